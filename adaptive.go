@@ -0,0 +1,37 @@
+package golru
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAdaptiveTTL = errors.New("adaptive ttl should have 0 < min <= max and non-negative extension per hit")
+
+// AdaptiveTTL describes the ttl depending on the popularity of the element. The element without hits lives Min, and
+// every hit extends its lifetime by PerHit up to Max, counting from the moment the element was added or changed
+type AdaptiveTTL struct {
+	Min    time.Duration
+	Max    time.Duration
+	PerHit time.Duration
+}
+
+// WithAdaptiveTTL makes the ttl of every element depend on its hits instead of the fixed ttl set by WithTTL, so the
+// popular elements stay in the cache longer, and the ones nobody reads leave it early. The effective ttl is reported
+// by GetEntry. NewCache returns ErrAdaptiveTTL if the limits are invalid
+func WithAdaptiveTTL(cfg AdaptiveTTL) CacheOption {
+	return func(cache *cache) {
+		cache.adaptive = &cfg
+	}
+}
+
+// lifetime returns the ttl of the element with the given number of hits
+func (a *AdaptiveTTL) lifetime(hits uint64) time.Duration {
+	if a.PerHit == 0 {
+		return a.Min
+	}
+	if hits >= uint64((a.Max-a.Min)/a.PerHit) {
+		return a.Max
+	}
+
+	return a.Min + time.Duration(hits)*a.PerHit
+}
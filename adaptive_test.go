@@ -0,0 +1,57 @@
+package golru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveTTLConfig(t *testing.T) {
+	for _, cfg := range []AdaptiveTTL{
+		{},
+		{Min: time.Second, Max: time.Millisecond},
+		{Min: time.Second, Max: time.Minute, PerHit: -time.Second},
+	} {
+		_, err := NewCache(2, WithAdaptiveTTL(cfg))
+		require.ErrorIs(t, err, ErrAdaptiveTTL)
+	}
+
+	cfg := AdaptiveTTL{Min: time.Second, Max: 10 * time.Second, PerHit: 2 * time.Second}
+	require.Equal(t, time.Second, cfg.lifetime(0))
+	require.Equal(t, 5*time.Second, cfg.lifetime(2))
+	require.Equal(t, 10*time.Second, cfg.lifetime(5))
+	require.Equal(t, 10*time.Second, cfg.lifetime(1000))
+}
+
+func TestAdaptiveTTL(t *testing.T) {
+	c, err := NewCache(0, WithAdaptiveTTL(AdaptiveTTL{
+		Min:    20 * time.Millisecond,
+		Max:    time.Minute,
+		PerHit: 20 * time.Millisecond,
+	}), WithTTLBuckets(time.Millisecond))
+	require.NoError(t, err)
+
+	c.Add("hot", 1)
+	c.Add("cold", 2)
+	for i := 0; i < 3; i++ {
+		c.Get("hot")
+	}
+
+	entry, _ := c.GetEntry("hot")
+	require.Equal(t, 80*time.Millisecond, entry.TTL)
+	entry, _ = c.GetEntry("cold")
+	require.Equal(t, 20*time.Millisecond, entry.TTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Expire(ctx))
+
+	time.Sleep(40 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		return c.Len() == 1
+	}, time.Second, time.Millisecond)
+	_, ok := c.Get("hot")
+	require.True(t, ok)
+}
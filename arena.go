@@ -0,0 +1,277 @@
+package golru
+
+import (
+	"bytes"
+	"errors"
+	"hash/maphash"
+	"os"
+	"sync"
+)
+
+// noSlot marks the absence of the slot in the links of the arena list
+const noSlot = -1
+
+var (
+	ErrArenaCapacity = errors.New("capacity of the arena cache can not be less than 1")
+	ErrSlotSize      = errors.New("slot size should be greater than 0")
+	ErrEntryTooLarge = errors.New("key and value don't fit into the slot of the arena")
+)
+
+// ArenaCache is the lru cache of byte keys and values for the very large caches. The keys and values are copied into
+// a single arena preallocated for the whole capacity and divided into equal slots, and the hash table, the list and
+// the slot metadata contain no pointers, so the garbage collector doesn't scan the entries at all, no matter how many
+// of them there are. Unlike the cache, it has a fixed memory footprint of capacity*slotSize bytes, and the key and
+// value of a single entry can not take more than a slot
+type ArenaCache struct {
+	mu sync.Mutex
+
+	seed   maphash.Seed
+	stable bool
+	index  map[uint64]int32
+	slots  []arenaSlot
+	data   []byte
+
+	slotSize   int
+	free       int32
+	head, tail int32
+	len        int
+	closed     bool
+
+	file *os.File
+	mem  []byte
+}
+
+// arenaSlot describes the entry kept in the slot of the same number in the arena. Prev and next link the slots of
+// the entries in the order of the list, and the next link of a free slot points to the next free one
+type arenaSlot struct {
+	hash       uint64
+	prev, next int32
+	keyLen     uint32
+	valueLen   uint32
+}
+
+// NewArenaCache creates the arena cache of n entries, each one taking up to slotSize bytes for the key and the value
+// together
+func NewArenaCache(n uint32, slotSize int) (*ArenaCache, error) {
+	if n == 0 {
+		return nil, ErrArenaCapacity
+	}
+	if slotSize <= 0 {
+		return nil, ErrSlotSize
+	}
+
+	a := &ArenaCache{
+		seed:     maphash.MakeSeed(),
+		index:    make(map[uint64]int32, n),
+		slots:    make([]arenaSlot, n),
+		data:     make([]byte, int(n)*slotSize),
+		slotSize: slotSize,
+	}
+	a.reset()
+
+	return a, nil
+}
+
+// Set puts the copies of the key and the value to the top of the list, replacing the value of the existing key. If
+// there is no free slot, the least recently used entry is evicted. Returns ErrEntryTooLarge if the entry doesn't fit
+// into the slot, and ErrCacheClosed after Close. Two keys with the same 64-bit hash can not be kept together, the
+// later one replaces the earlier one
+func (a *ArenaCache) Set(key, value []byte) error {
+	if len(key)+len(value) > a.slotSize {
+		return ErrEntryTooLarge
+	}
+
+	h := a.hash(key)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrCacheClosed
+	}
+
+	slot, ok := a.index[h]
+	if ok {
+		a.unlink(slot)
+	} else {
+		if a.free == noSlot {
+			a.removeSlot(a.tail)
+		}
+		slot = a.free
+		a.free = a.slots[slot].next
+		a.index[h] = slot
+		a.len++
+	}
+
+	offset := int(slot) * a.slotSize
+	copy(a.data[offset:], key)
+	copy(a.data[offset+len(key):], value)
+	a.slots[slot].hash = h
+	a.slots[slot].keyLen = uint32(len(key))
+	a.slots[slot].valueLen = uint32(len(value))
+	a.pushFront(slot)
+
+	return nil
+}
+
+// Get appends the value of the key to dst and moves the entry to the top of the list. Passing a reused buffer as dst
+// makes the lookup free of allocations. Returns false if there is no such key or the cache is closed
+func (a *ArenaCache) Get(dst, key []byte) ([]byte, bool) {
+	h := a.hash(key)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	slot, ok := a.lookup(h, key)
+	if !ok {
+		return dst, false
+	}
+
+	a.unlink(slot)
+	a.pushFront(slot)
+
+	offset := int(slot)*a.slotSize + int(a.slots[slot].keyLen)
+
+	return append(dst, a.data[offset:offset+int(a.slots[slot].valueLen)]...), true
+}
+
+// Remove deletes the entry by the key. Returns false if there is no such key or the cache is closed
+func (a *ArenaCache) Remove(key []byte) bool {
+	h := a.hash(key)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	slot, ok := a.lookup(h, key)
+	if !ok {
+		return false
+	}
+	a.removeSlot(slot)
+
+	return true
+}
+
+// Len returns the number of entries in the cache, zero after Close
+func (a *ArenaCache) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return 0
+	}
+
+	return a.len
+}
+
+// Clear removes all the entries keeping the arena allocated, it does nothing after Close
+func (a *ArenaCache) Clear() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return
+	}
+
+	a.index = make(map[uint64]int32, len(a.slots))
+	a.reset()
+}
+
+// hash returns the hash of the key. The arena file uses the stable hash, as the hashes are stored in the file
+func (a *ArenaCache) hash(key []byte) uint64 {
+	if a.stable {
+		return fnv1a(key)
+	}
+
+	return maphash.Bytes(a.seed, key)
+}
+
+// fnv1a is the FNV-1a hash of the key, unlike maphash it doesn't depend on a random seed
+func fnv1a(b []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+
+	return h
+}
+
+// reset links all the slots into the free list
+func (a *ArenaCache) reset() {
+	for i := range a.slots {
+		a.slots[i] = arenaSlot{prev: noSlot, next: int32(i + 1)}
+	}
+	a.slots[len(a.slots)-1].next = noSlot
+	a.free, a.head, a.tail, a.len = 0, noSlot, noSlot, 0
+}
+
+// lookup returns the slot of the key, comparing the stored key to tell it from another key with the same hash. The
+// closed cache has no keys
+func (a *ArenaCache) lookup(h uint64, key []byte) (int32, bool) {
+	slot, ok := a.index[h]
+	if !ok || a.closed {
+		return 0, false
+	}
+
+	offset := int(slot) * a.slotSize
+	if !bytes.Equal(a.data[offset:offset+int(a.slots[slot].keyLen)], key) {
+		return 0, false
+	}
+
+	return slot, true
+}
+
+// removeSlot deletes the entry of the slot and returns the slot to the free list
+func (a *ArenaCache) removeSlot(slot int32) {
+	a.unlink(slot)
+	delete(a.index, a.slots[slot].hash)
+	a.slots[slot] = arenaSlot{prev: noSlot, next: a.free}
+	a.free = slot
+	a.len--
+}
+
+// pushFront links the slot at the front of the list
+func (a *ArenaCache) pushFront(slot int32) {
+	a.slots[slot].prev = noSlot
+	a.slots[slot].next = a.head
+	if a.head != noSlot {
+		a.slots[a.head].prev = slot
+	}
+	a.head = slot
+	if a.tail == noSlot {
+		a.tail = slot
+	}
+}
+
+// unlink excludes the slot from the list
+func (a *ArenaCache) unlink(slot int32) {
+	prev, next := a.slots[slot].prev, a.slots[slot].next
+	if prev != noSlot {
+		a.slots[prev].next = next
+	} else {
+		a.head = next
+	}
+	if next != noSlot {
+		a.slots[next].prev = prev
+	} else {
+		a.tail = prev
+	}
+}
+
+// Close writes the state of the arena backed by the file and unmaps it. After Close the cache is empty: Set returns
+// ErrCacheClosed, and the lookups miss. Closing the arena in memory releases it too
+func (a *ArenaCache) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.file == nil {
+		a.index, a.slots, a.data = nil, nil, nil
+		return nil
+	}
+
+	return a.unmap()
+}
@@ -0,0 +1,127 @@
+//go:build linux || darwin || freebsd
+
+package golru
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// arenaMagic marks the files of the arena cache
+const arenaMagic = "golruar1"
+
+var ErrArenaFile = errors.New("file doesn't contain an arena of the same capacity and slot size")
+
+// arenaHeader is kept at the beginning of the arena file, followed by the slots and then by the data. Clean is set
+// only while the file is closed, so the state of an arena which wasn't closed is never trusted
+type arenaHeader struct {
+	magic      [8]byte
+	n          uint32
+	slotSize   uint32
+	free       int32
+	head, tail int32
+	len        int32
+	clean      uint32
+}
+
+// arenaHeaderSize keeps the slots following the header aligned
+const arenaHeaderSize = 64
+
+// OpenArenaFile creates the arena cache like NewArenaCache, with the arena mapped from the file at the path. The
+// entries take no memory of the process except the hash table, the operating system keeps the recently used pages
+// in memory and writes the cold ones to the file. If the file was left by the arena of the same capacity and slot
+// size closed by Close, the entries are restored, otherwise the arena starts empty. The file uses the native byte
+// order, so it can't be moved between the machines of different architectures
+func OpenArenaFile(path string, n uint32, slotSize int) (*ArenaCache, error) {
+	if n == 0 {
+		return nil, ErrArenaCapacity
+	}
+	if slotSize <= 0 {
+		return nil, ErrSlotSize
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	slotsSize := int(n) * int(unsafe.Sizeof(arenaSlot{}))
+	size := arenaHeaderSize + slotsSize + int(n)*slotSize
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	existing := info.Size() != 0
+	if existing && info.Size() != int64(size) {
+		f.Close()
+		return nil, ErrArenaFile
+	}
+	if err = f.Truncate(int64(size)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	header := (*arenaHeader)(unsafe.Pointer(&mem[0]))
+	if existing && (string(header.magic[:]) != arenaMagic || header.n != n || header.slotSize != uint32(slotSize)) {
+		syscall.Munmap(mem)
+		f.Close()
+		return nil, ErrArenaFile
+	}
+
+	a := &ArenaCache{
+		stable:   true,
+		index:    make(map[uint64]int32, n),
+		slots:    unsafe.Slice((*arenaSlot)(unsafe.Pointer(&mem[arenaHeaderSize])), n),
+		data:     mem[arenaHeaderSize+slotsSize:],
+		slotSize: slotSize,
+		file:     f,
+		mem:      mem,
+	}
+
+	if existing && header.clean == 1 {
+		a.free, a.head, a.tail, a.len = header.free, header.head, header.tail, int(header.len)
+		a.restoreIndex()
+	} else {
+		a.reset()
+	}
+
+	copy(header.magic[:], arenaMagic)
+	header.n, header.slotSize, header.clean = n, uint32(slotSize), 0
+
+	return a, nil
+}
+
+// restoreIndex fills the hash table by the hashes of the entries restored from the file
+func (a *ArenaCache) restoreIndex() {
+	for slot := a.head; slot != noSlot; slot = a.slots[slot].next {
+		a.index[a.slots[slot].hash] = slot
+	}
+}
+
+// unmap saves the state of the arena to the header and releases the file
+func (a *ArenaCache) unmap() error {
+	header := (*arenaHeader)(unsafe.Pointer(&a.mem[0]))
+	header.free, header.head, header.tail, header.len = a.free, a.head, a.tail, int32(a.len)
+	header.clean = 1
+
+	err := syscall.Munmap(a.mem)
+	if syncErr := a.file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	a.file, a.mem, a.slots, a.data = nil, nil, nil, nil
+
+	return err
+}
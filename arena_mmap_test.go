@@ -0,0 +1,62 @@
+//go:build linux || darwin || freebsd
+
+package golru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arena")
+
+	a, err := OpenArenaFile(path, 2, 16)
+	require.NoError(t, err)
+	require.NoError(t, a.Set([]byte("first"), []byte("1")))
+	require.NoError(t, a.Set([]byte("second"), []byte("2")))
+	a.Get(nil, []byte("first"))
+	require.NoError(t, a.Close())
+
+	_, err = OpenArenaFile(path, 3, 16)
+	require.ErrorIs(t, err, ErrArenaFile)
+
+	a, err = OpenArenaFile(path, 2, 16)
+	require.NoError(t, err)
+	require.Equal(t, 2, a.Len())
+	value, ok := a.Get(nil, []byte("second"))
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), value)
+
+	require.NoError(t, a.Set([]byte("third"), []byte("3")))
+	_, ok = a.Get(nil, []byte("first"))
+	require.False(t, ok)
+	require.NoError(t, a.Close())
+	require.NoError(t, a.Close())
+
+	require.ErrorIs(t, a.Set([]byte("fourth"), []byte("4")), ErrCacheClosed)
+	_, ok = a.Get(nil, []byte("second"))
+	require.False(t, ok)
+	require.Zero(t, a.Len())
+	a.Clear()
+}
+
+func TestArenaFileNotClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arena")
+
+	a, err := OpenArenaFile(path, 2, 16)
+	require.NoError(t, err)
+	require.NoError(t, a.Set([]byte("first"), []byte("1")))
+
+	b, err := OpenArenaFile(path, 2, 16)
+	require.NoError(t, err)
+	require.Zero(t, b.Len())
+	require.NoError(t, b.Close())
+	require.NoError(t, a.Close())
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = OpenArenaFile(path, 2, 16)
+	require.ErrorIs(t, err, ErrArenaFile)
+}
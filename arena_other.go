@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd)
+
+package golru
+
+import "errors"
+
+var ErrArenaFile = errors.New("arena files are not supported on this platform")
+
+// OpenArenaFile is not supported on this platform, see the version for unix
+func OpenArenaFile(path string, n uint32, slotSize int) (*ArenaCache, error) {
+	return nil, ErrArenaFile
+}
+
+// unmap is never called without the file
+func (a *ArenaCache) unmap() error {
+	return nil
+}
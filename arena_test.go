@@ -0,0 +1,86 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaInit(t *testing.T) {
+	_, err := NewArenaCache(0, 16)
+	require.ErrorIs(t, err, ErrArenaCapacity)
+	_, err = NewArenaCache(1, 0)
+	require.ErrorIs(t, err, ErrSlotSize)
+}
+
+func TestArenaCache(t *testing.T) {
+	a, err := NewArenaCache(2, 16)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Set([]byte("first"), []byte("1")))
+	require.NoError(t, a.Set([]byte("second"), []byte("2")))
+	require.ErrorIs(t, a.Set([]byte("large"), make([]byte, 12)), ErrEntryTooLarge)
+
+	value, ok := a.Get(nil, []byte("first"))
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	require.NoError(t, a.Set([]byte("third"), []byte("3")))
+	require.Equal(t, 2, a.Len())
+	_, ok = a.Get(nil, []byte("second"))
+	require.False(t, ok)
+
+	require.NoError(t, a.Set([]byte("first"), []byte("one")))
+	value, _ = a.Get(value[:0], []byte("first"))
+	require.Equal(t, []byte("one"), value)
+	require.Equal(t, 2, a.Len())
+
+	require.True(t, a.Remove([]byte("third")))
+	require.False(t, a.Remove([]byte("third")))
+	require.Equal(t, 1, a.Len())
+
+	a.Clear()
+	require.Zero(t, a.Len())
+	_, ok = a.Get(nil, []byte("first"))
+	require.False(t, ok)
+}
+
+func TestArenaCacheChurn(t *testing.T) {
+	a, err := NewArenaCache(100, 32)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		key := []byte("key" + strconv.Itoa(i))
+		require.NoError(t, a.Set(key, key))
+		if i%3 == 0 {
+			a.Remove(key)
+		}
+	}
+	require.LessOrEqual(t, a.Len(), 100)
+
+	buf := make([]byte, 0, 32)
+	value, ok := a.Get(buf, []byte("key998"))
+	require.True(t, ok)
+	require.Equal(t, []byte("key998"), value)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get(buf[:0], []byte("key998"))
+	})
+	require.Zero(t, allocs)
+}
+
+func TestArenaClosed(t *testing.T) {
+	a, err := NewArenaCache(2, 16)
+	require.NoError(t, err)
+	require.NoError(t, a.Set([]byte("first"), []byte("1")))
+	require.NoError(t, a.Close())
+	require.NoError(t, a.Close())
+
+	require.ErrorIs(t, a.Set([]byte("second"), []byte("2")), ErrCacheClosed)
+	_, ok := a.Get(nil, []byte("first"))
+	require.False(t, ok)
+	require.False(t, a.Remove([]byte("first")))
+	require.Zero(t, a.Len())
+	a.Clear()
+}
@@ -0,0 +1,114 @@
+package golru
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a single mutation of the cache: the element removed by Remove, the value replaced by
+// ChangeValue, Swap, Update or Merge, or the whole cache cleared by Clear with an empty key. The elements removed by
+// Invalidate, InvalidateByIndex, InvalidateSubtree and Purge are recorded one by one as OpRemove. Principal is the
+// one set by WithPrincipal on the context of the operation, empty for the methods without the context
+type AuditRecord struct {
+	Time      time.Time
+	Principal string
+	Op        Op
+	Key       string
+}
+
+// AuditFunc receives the records of the mutations. It is called outside the cache lock after the mutation succeeds
+type AuditFunc func(rec AuditRecord)
+
+// principalKey keeps the principal in the context
+type principalKey struct{}
+
+// WithPrincipal returns the context attributing the mutations made with it to the principal, such as the user or the
+// service, in the audit records
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal set by WithPrincipal, or an empty string
+func PrincipalFrom(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+
+	return principal
+}
+
+// WithAudit passes every successful removal and overwrite going through the hooks to the function, together with the
+// principal of its context, so it can answer who changed which key and when, and so are the elements removed in bulk
+// by the invalidation methods and Purge. The keys are the stored ones redacted by WithKeyRedactor. The elements
+// evicted or expired by the cache itself, including the dependents evicted by Invalidate, aren't audited, see
+// WithOnEvict for them, nor are the other methods working like GetEntry, which don't go through the hooks
+func WithAudit(fn AuditFunc) CacheOption {
+	return func(cache *cache) {
+		cache.auditor = fn
+	}
+}
+
+// JSONAuditor returns the audit function writing every record to w as the JSON object on its own line with the time
+// in RFC 3339 with nanoseconds, the principal, the name of the operation and the key. The writes are serialized, and
+// their errors are dropped
+func JSONAuditor(w io.Writer) AuditFunc {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return func(rec AuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_ = enc.Encode(struct {
+			Time      time.Time `json:"time"`
+			Principal string    `json:"principal,omitempty"`
+			Op        string    `json:"op"`
+			Key       string    `json:"key"`
+		}{rec.Time, rec.Principal, rec.Op.String(), rec.Key})
+	}
+}
+
+// audited reports whether the operation is a mutation recorded by the audit
+func audited(op Op) bool {
+	switch op {
+	case OpRemove, OpChangeValue, OpSwap, OpUpdate, OpClear:
+		return true
+	default:
+		return false
+	}
+}
+
+// queueAudit remembers the element removed by the methods not going through the hooks to pass it to the audit function
+// after the cache is unlocked
+func (c *cache) queueAudit(key string) {
+	if c.auditor != nil {
+		c.audits = append(c.audits, AuditRecord{Time: time.Now(), Op: OpRemove, Key: key})
+	}
+}
+
+// notifyAudit passes the queued removals to the audit function with the redacted keys. The cache must be unlocked
+func (c *cache) notifyAudit(records []AuditRecord) {
+	for _, rec := range records {
+		key := rec.Key
+		rec.Key = c.redact(key)
+		c.safely(callbackAudit, key, func() {
+			c.auditor(rec)
+		})
+	}
+}
+
+// wrapAudit passes the successful mutations to the audit function
+func (c *cache) wrapAudit(next ctxOpFunc) ctxOpFunc {
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		result, err := next(ctx, op, key, value)
+		if err == nil && audited(op) {
+			rec := AuditRecord{Time: time.Now(), Principal: PrincipalFrom(ctx), Op: op, Key: c.redact(key)}
+			c.safely(callbackAudit, key, func() {
+				c.auditor(rec)
+			})
+		}
+
+		return result, err
+	}
+}
@@ -0,0 +1,100 @@
+package golru
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	var records []AuditRecord
+	c, err := NewCache(2, WithAudit(func(rec AuditRecord) {
+		records = append(records, rec)
+	}), WithKeyNormalizer(strings.ToLower))
+	require.NoError(t, err)
+
+	admin := WithPrincipal(context.Background(), "admin")
+	require.NoError(t, c.AddContext(admin, "Role", "reader"))
+	require.NoError(t, c.ChangeValueContext(admin, "role", "writer"))
+	require.ErrorIs(t, c.ChangeValueContext(admin, "missing", 1), ErrKeyNotFound)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	require.NoError(t, c.RemoveContext(admin, "b"))
+	require.False(t, c.Remove("b"))
+	_, err = c.Update("a", func(old interface{}, exists bool) (interface{}, error) {
+		return 3, nil
+	})
+	require.NoError(t, err)
+	c.Clear()
+
+	require.Len(t, records, 4)
+	ops := make([]string, 0, len(records))
+	for _, rec := range records {
+		ops = append(ops, rec.Principal+" "+rec.Op.String()+" "+rec.Key)
+		require.False(t, rec.Time.IsZero())
+	}
+	require.Equal(t, []string{"admin change_value role", "admin remove b", " update a", " clear "}, ops)
+}
+
+func TestAuditBulkRemovals(t *testing.T) {
+	var records []string
+	c, err := NewCache(0, WithAudit(func(rec AuditRecord) {
+		records = append(records, rec.Op.String()+" "+rec.Key)
+	}), WithKeyTree('/'), WithIndex("tenant", tenantIndex), WithKeyRedactor(strings.ToUpper))
+	require.NoError(t, err)
+
+	c.Add("dep", 1)
+	require.NoError(t, c.AddWithDependencies("derived", 2, "dep"))
+	c.Add("acme", tenantValue{"acme", 1})
+	c.Add("users/1", 3)
+	c.Add("users/2", 4)
+	c.Add("stale", 5)
+
+	require.Equal(t, 2, c.Invalidate("dep"))
+	require.Equal(t, 1, c.InvalidateByIndex("tenant", "acme"))
+	require.Equal(t, 2, c.InvalidateSubtree("users"))
+	require.Equal(t, 1, c.Purge(func(entry Entry) bool {
+		return entry.Key == "stale"
+	}))
+	require.ElementsMatch(t, []string{"remove DEP", "remove ACME", "remove USERS/1", "remove USERS/2", "remove STALE"},
+		records)
+
+	records = nil
+	other, err := NewCache(0)
+	require.NoError(t, err)
+	c.Add("shared", 1)
+	other.Add("shared", 2)
+	other.Add("new", 3)
+	require.Equal(t, 2, c.Merge(other, KeepIncoming))
+	require.Equal(t, []string{"change_value SHARED"}, records)
+}
+
+func TestJSONAuditor(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := NewCache(10, WithAudit(JSONAuditor(&buf)), WithKeyRedactor(func(key string) string {
+		return "#" + key
+	}))
+	require.NoError(t, err)
+
+	ctx := WithPrincipal(context.Background(), "svc")
+	require.Equal(t, "svc", PrincipalFrom(ctx))
+	require.Empty(t, PrincipalFrom(context.Background()))
+	c.Add("perm", 1)
+	require.NoError(t, c.RemoveContext(ctx, "perm"))
+	c.Add("perm", 1)
+	require.True(t, c.Remove("perm"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	require.Equal(t, "svc", rec["principal"])
+	require.Equal(t, "remove", rec["op"])
+	require.Equal(t, "#perm", rec["key"])
+	require.Contains(t, rec, "time")
+	require.NotContains(t, lines[1], "principal")
+}
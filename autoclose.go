@@ -0,0 +1,47 @@
+package golru
+
+import (
+	"io"
+	"log/slog"
+	"reflect"
+)
+
+// WithAutoClose makes the cache close the values implementing io.Closer once they leave it: evicted, expired, removed,
+// cleared or replaced by a different value. Close is called after the eviction callback, outside the cache lock, on
+// the eviction workers if WithEvictWorkers is set, so Drain waits for it too. The values taken out of the cache by the
+// caller aren't closed. The error of Close is logged, its panic is recovered like the panic of a callback
+func WithAutoClose() CacheOption {
+	return func(cache *cache) {
+		cache.autoClose = true
+	}
+}
+
+// queueRemoval remembers the removed element to close its value after the cache is unlocked. Unlike the evicted
+// elements, the removed ones aren't passed to the eviction callback
+func (c *cache) queueRemoval(key string, value interface{}) {
+	c.queueEvent(evictEvent{key: key, value: value, close: c.autoClose})
+}
+
+// closeValue closes the value if it implements io.Closer
+func (c *cache) closeValue(key string, value interface{}) {
+	closer, ok := value.(io.Closer)
+	if !ok {
+		return
+	}
+
+	c.safely(callbackClose, key, func() {
+		if err := closer.Close(); err != nil {
+			c.log(slog.LevelWarn, "golru: failed to close the value", "key", c.redact(key), "error", err)
+		}
+	})
+}
+
+// sameValue reports whether both values are the same, comparing only the values of comparable types, so the old value
+// added again isn't closed
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
@@ -0,0 +1,110 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type handle struct {
+	closed int32
+	err    error
+}
+
+func (h *handle) Close() error {
+	atomic.AddInt32(&h.closed, 1)
+	return h.err
+}
+
+func (h *handle) isClosed() bool {
+	return atomic.LoadInt32(&h.closed) == 1
+}
+
+func TestAutoClose(t *testing.T) {
+	c, err := NewCache(2, WithAutoClose())
+	require.NoError(t, err)
+
+	evicted, removed, kept := &handle{}, &handle{}, &handle{}
+	c.Add("evicted", evicted)
+	c.Add("removed", removed)
+	c.Add("kept", kept)
+	require.True(t, evicted.isClosed())
+
+	require.True(t, c.Remove("removed"))
+	require.True(t, removed.isClosed())
+	require.False(t, kept.isClosed())
+
+	c.Clear()
+	require.True(t, kept.isClosed())
+}
+
+func TestAutoCloseExpired(t *testing.T) {
+	c, err := NewCache(2, WithAutoClose(), WithTTL(0.01))
+	require.NoError(t, err)
+
+	h := &handle{}
+	c.Add("key", h)
+	time.Sleep(20 * time.Millisecond)
+	_, ok := c.Get("key")
+	require.False(t, ok)
+	require.True(t, h.isClosed())
+}
+
+func TestAutoCloseReplaced(t *testing.T) {
+	c, err := NewCache(2, WithAutoClose())
+	require.NoError(t, err)
+
+	old, replacement := &handle{}, &handle{}
+	c.Add("key", old)
+	require.True(t, c.ChangeValue("key", old))
+	require.False(t, old.isClosed())
+
+	require.True(t, c.ChangeValue("key", replacement))
+	require.True(t, old.isClosed())
+	require.False(t, replacement.isClosed())
+}
+
+func TestAutoCloseWithCallback(t *testing.T) {
+	var closedBefore bool
+	h := &handle{}
+	c, err := NewCache(1, WithAutoClose(), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		closedBefore = value.(*handle).isClosed()
+	}))
+	require.NoError(t, err)
+
+	c.Add("first", h)
+	c.Add("second", 2)
+	require.False(t, closedBefore)
+	require.True(t, h.isClosed())
+}
+
+func TestAutoCloseWorkers(t *testing.T) {
+	var panics int32
+	c, err := NewCache(1, WithAutoClose(), WithEvictWorkers(2, 4),
+		WithPanicHandler(func(callback string, key string, recovered interface{}) {
+			require.Equal(t, callbackClose, callback)
+			atomic.AddInt32(&panics, 1)
+		}))
+	require.NoError(t, err)
+
+	handles := []*handle{{}, {err: errors.New("failed")}}
+	c.Add("first", handles[0])
+	c.Add("second", handles[1])
+	c.Add("third", panicCloser{})
+	c.Add("fourth", 4)
+	require.NoError(t, c.Drain(context.Background()))
+
+	require.True(t, handles[0].isClosed())
+	require.True(t, handles[1].isClosed())
+	require.Equal(t, int32(1), atomic.LoadInt32(&panics))
+}
+
+type panicCloser struct{}
+
+func (panicCloser) Close() error {
+	panic("close")
+}
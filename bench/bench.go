@@ -0,0 +1,186 @@
+// Package bench runs the synthetic workloads against the caches and measures their throughput, allocations and hit
+// ratio, so the caches and their settings can be compared in your own environment. Unlike the benchmarks of the test
+// files, it can be called from any program
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// Cache is the part of the cache used by the benchmark, so the other implementations can be compared too
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Add(key string, value interface{}) bool
+}
+
+// Target is the benchmarked cache, New creates it with the capacity of the run
+type Target struct {
+	Name string
+	New  func(capacity uint32) (Cache, error)
+}
+
+// Targets returns the caches of golru benchmarked by default: a single cache and a sharded one with 16 shards
+func Targets() []Target {
+	return []Target{
+		{Name: "lru", New: func(capacity uint32) (Cache, error) {
+			return golru.NewCache(capacity)
+		}},
+		{Name: "sharded", New: func(capacity uint32) (Cache, error) {
+			return golru.NewShardedCache(16, capacity)
+		}},
+	}
+}
+
+// Config describes the benchmark. Every target is run with every workload. Ops is the number of the requests of every
+// run, 1000000 by default, divided between the goroutines, GOMAXPROCS of them by default. All the default targets
+// are used if Targets is empty
+type Config struct {
+	Capacity   uint32
+	Ops        int
+	Goroutines int
+	Targets    []Target
+	Workloads  map[string]WorkloadFunc
+}
+
+// Result is the outcome of a single run. Every request is a Get followed by Add if the key is missed, as in the
+// read-through cache. Allocations are counted for the whole process during the run, the keys are generated before
+// it
+type Result struct {
+	Target      string
+	Workload    string
+	Ops         int
+	Duration    time.Duration
+	Hits        uint64
+	AllocsPerOp float64
+	BytesPerOp  float64
+}
+
+// OpsPerSecond returns the throughput of the run
+func (r Result) OpsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(r.Ops) / r.Duration.Seconds()
+}
+
+// HitRatio returns the share of the requests which found the key
+func (r Result) HitRatio() float64 {
+	if r.Ops == 0 {
+		return 0
+	}
+
+	return float64(r.Hits) / float64(r.Ops)
+}
+
+// Run runs every target with every workload and returns the results ordered by the workload and the target
+func Run(cfg Config) ([]Result, error) {
+	if cfg.Ops <= 0 {
+		cfg.Ops = 1000000
+	}
+	if cfg.Goroutines <= 0 {
+		cfg.Goroutines = runtime.GOMAXPROCS(0)
+	}
+	if len(cfg.Targets) == 0 {
+		cfg.Targets = Targets()
+	}
+
+	names := make([]string, 0, len(cfg.Workloads))
+	for name := range cfg.Workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]Result, 0, len(names)*len(cfg.Targets))
+	for _, name := range names {
+		for _, target := range cfg.Targets {
+			result, err := runOne(cfg, target, cfg.Workloads[name])
+			if err != nil {
+				return nil, fmt.Errorf("%s with %s: %w", target.Name, name, err)
+			}
+			result.Workload = name
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// runOne runs the single target with the single workload
+func runOne(cfg Config, target Target, workload WorkloadFunc) (Result, error) {
+	c, err := target.New(cfg.Capacity)
+	if err != nil {
+		return Result{}, err
+	}
+
+	perGoroutine := cfg.Ops / cfg.Goroutines
+	keys := make([][]string, cfg.Goroutines)
+	for g := range keys {
+		w := workload(int64(g))
+		keys[g] = make([]string, perGoroutine)
+		for i := range keys[g] {
+			keys[g][i] = w.Next()
+		}
+	}
+
+	hits := make([]uint64, cfg.Goroutines)
+	var wg sync.WaitGroup
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for g := range keys {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for _, key := range keys[g] {
+				if _, ok := c.Get(key); ok {
+					hits[g]++
+					continue
+				}
+				c.Add(key, key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result := Result{Target: target.Name, Ops: perGoroutine * cfg.Goroutines, Duration: duration}
+	for _, n := range hits {
+		result.Hits += n
+	}
+	if result.Ops != 0 {
+		result.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(result.Ops)
+		result.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(result.Ops)
+	}
+
+	return result, nil
+}
+
+// WriteReport writes the results as a table aligned by the columns
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "workload\ttarget\tops/s\tallocs/op\tB/op\thit ratio"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%.0f\t%.2f\t%.1f\t%.4f\n",
+			r.Workload, r.Target, r.OpsPerSecond(), r.AllocsPerOp, r.BytesPerOp, r.HitRatio())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
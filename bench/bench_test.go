@@ -0,0 +1,58 @@
+package bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloads(t *testing.T) {
+	l := Loop(3)(0)
+	require.Equal(t, []string{"loop:0", "loop:1", "loop:2", "loop:0"}, []string{l.Next(), l.Next(), l.Next(), l.Next()})
+
+	s := Scan()(1)
+	require.NotEqual(t, s.Next(), s.Next())
+
+	z := Zipfian(1000, 1.5)(1)
+	seen := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		seen[z.Next()]++
+	}
+	require.Greater(t, seen["zipf:0"], 100)
+
+	m := Mixed([]WorkloadFunc{Loop(1), Scan()}, []float64{1, 0})(1)
+	require.Equal(t, "loop:0", m.Next())
+}
+
+func TestRun(t *testing.T) {
+	results, err := Run(Config{
+		Capacity:   1000,
+		Ops:        10000,
+		Goroutines: 2,
+		Workloads: map[string]WorkloadFunc{
+			"loop": Loop(50),
+			"scan": Scan(),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	for _, r := range results {
+		require.Equal(t, 10000, r.Ops)
+		require.Greater(t, r.OpsPerSecond(), 0.0)
+		switch r.Workload {
+		case "loop":
+			require.Greater(t, r.HitRatio(), 0.9, r.Target)
+		case "scan":
+			require.Zero(t, r.HitRatio(), r.Target)
+		}
+	}
+	require.Equal(t, "loop", results[0].Workload)
+	require.Equal(t, "lru", results[0].Target)
+
+	var report bytes.Buffer
+	require.NoError(t, WriteReport(&report, results))
+	require.Len(t, strings.Split(strings.TrimSpace(report.String()), "\n"), 5)
+}
@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// Workload generates the sequence of the requested keys
+type Workload interface {
+	// Next returns the next requested key
+	Next() string
+}
+
+// WorkloadFunc creates the workload with the given seed, so every goroutine of the run gets its own sequence
+type WorkloadFunc func(seed int64) Workload
+
+// zipfian requests the keys by the Zipf distribution, so a few of them are hot
+type zipfian struct {
+	zipf *rand.Zipf
+}
+
+// Zipfian requests keys out of the given number by the Zipf distribution with the exponent s, which should be greater
+// than 1. The larger s is, the more the traffic is concentrated on the hottest keys
+func Zipfian(keys uint64, s float64) WorkloadFunc {
+	return func(seed int64) Workload {
+		return &zipfian{zipf: rand.NewZipf(rand.New(rand.NewSource(seed)), s, 1, keys-1)}
+	}
+}
+
+// Next returns the key by the Zipf distribution
+func (z *zipfian) Next() string {
+	return "zipf:" + strconv.FormatUint(z.zipf.Uint64(), 10)
+}
+
+// scan requests the new keys all the time
+type scan struct {
+	prefix string
+	next   uint64
+}
+
+// Scan requests the keys which are never requested again, like a sequential read of a large table. No cache can hit
+// them, and the workload shows how much the cache spends on the useless elements
+func Scan() WorkloadFunc {
+	return func(seed int64) Workload {
+		return &scan{prefix: "scan:" + strconv.FormatInt(seed, 10) + ":"}
+	}
+}
+
+// Next returns the key which wasn't requested before
+func (s *scan) Next() string {
+	s.next++
+
+	return s.prefix + strconv.FormatUint(s.next, 10)
+}
+
+// loop requests the same keys in a cycle
+type loop struct {
+	keys uint64
+	next uint64
+}
+
+// Loop requests the given number of keys one by one in a cycle. The LRU cache smaller than the loop never hits, as
+// every key is evicted just before it is requested again
+func Loop(keys uint64) WorkloadFunc {
+	return func(seed int64) Workload {
+		return &loop{keys: keys, next: uint64(seed) % keys}
+	}
+}
+
+// Next returns the next key of the cycle
+func (l *loop) Next() string {
+	key := "loop:" + strconv.FormatUint(l.next, 10)
+	l.next = (l.next + 1) % l.keys
+
+	return key
+}
+
+// mixed chooses one of the workloads for every request by their weights
+type mixed struct {
+	rand      *rand.Rand
+	workloads []Workload
+	weights   []float64
+	total     float64
+}
+
+// Mixed combines the workloads with the given weights, for example the Zipfian traffic interrupted by scans. The
+// weights are the relative shares of the requests of every workload
+func Mixed(workloads []WorkloadFunc, weights []float64) WorkloadFunc {
+	return func(seed int64) Workload {
+		m := &mixed{rand: rand.New(rand.NewSource(seed)), weights: weights}
+		for i, w := range workloads {
+			m.workloads = append(m.workloads, w(seed+int64(i)+1))
+			m.total += weights[i]
+		}
+		return m
+	}
+}
+
+// Next returns the key of the workload chosen by the weights
+func (m *mixed) Next() string {
+	x := m.rand.Float64() * m.total
+	for i, w := range m.weights {
+		if x < w {
+			return m.workloads[i].Next()
+		}
+		x -= w
+	}
+
+	return m.workloads[len(m.workloads)-1].Next()
+}
@@ -0,0 +1,161 @@
+package golru
+
+import (
+	"container/heap"
+	"time"
+)
+
+// ttlBuckets groups the elements by the moment of their expiration rounded up to the granularity. A bucket whose
+// end has passed contains only the expired elements, so the janitor drops it without checking them one by one
+type ttlBuckets struct {
+	granularity time.Duration
+	byKey       map[int64]*ttlBucket
+	keys        bucketKeys
+}
+
+// ttlBucket is the list of the elements expiring before its end, linked through the bucket links of the items
+type ttlBucket struct {
+	key  int64
+	head *item
+	len  int
+}
+
+// WithTTLBuckets makes the janitor started by Expire find the expired elements by the buckets of the given
+// granularity instead of traversing the whole list. It pays off for the large caches where only a small share of
+// the elements expires at a time, as the elements which are far from the expiration are not visited at all
+func WithTTLBuckets(granularity time.Duration) CacheOption {
+	return func(cache *cache) {
+		if granularity <= 0 {
+			cache.buckets = nil
+			return
+		}
+		cache.buckets = newTTLBuckets(granularity)
+	}
+}
+
+// newTTLBuckets returns the empty buckets of the granularity
+func newTTLBuckets(granularity time.Duration) *ttlBuckets {
+	return &ttlBuckets{granularity: granularity, byKey: make(map[int64]*ttlBucket)}
+}
+
+// rebucket puts the element to the bucket of its current expiration moment
+func (c *cache) rebucket(element *item) {
+	if c.buckets == nil {
+		return
+	}
+
+	c.unbucket(element)
+	expiration := c.expiration(element)
+	if expiration.IsZero() {
+		return
+	}
+	c.buckets.put(element, expiration)
+}
+
+// unbucket deletes the element from its bucket
+func (c *cache) unbucket(element *item) {
+	if c.buckets == nil || element.bucket == nil {
+		return
+	}
+
+	c.buckets.remove(element)
+}
+
+// expireBuckets evicts the elements of the buckets which have ended by the moment now, and checks the elements of
+// the current bucket one by one. Returns the number of evicted elements
+func (c *cache) expireBuckets(now time.Time) int {
+	b := c.buckets
+	current := b.keyOf(now)
+	removed := 0
+
+	for len(b.keys) != 0 && b.keys[0] <= current {
+		bucket := b.byKey[b.keys[0]]
+		whole := bucket.key < current
+		for element := bucket.head; element != nil; {
+			next := element.bnext
+			switch {
+			case c.pinned(element, now) && !c.overaged(element, now):
+				b.remove(element)
+				b.put(element, now.Add(b.granularity))
+			case whole || c.expired(element, now):
+				c.evict(element, EvictedByTTL)
+				removed++
+			}
+			element = next
+		}
+		if !whole {
+			break
+		}
+	}
+
+	return removed
+}
+
+// keyOf returns the key of the bucket containing the moment
+func (b *ttlBuckets) keyOf(t time.Time) int64 {
+	n := t.UnixNano()
+	g := int64(b.granularity)
+
+	return (n + g - 1) / g
+}
+
+// put links the element to the bucket of the expiration moment, creating the bucket if needed
+func (b *ttlBuckets) put(element *item, expiration time.Time) {
+	key := b.keyOf(expiration)
+	bucket, ok := b.byKey[key]
+	if !ok {
+		bucket = &ttlBucket{key: key}
+		b.byKey[key] = bucket
+		heap.Push(&b.keys, key)
+	}
+
+	element.bucket = bucket
+	element.bprev = nil
+	element.bnext = bucket.head
+	if bucket.head != nil {
+		bucket.head.bprev = element
+	}
+	bucket.head = element
+	bucket.len++
+}
+
+// remove unlinks the element from its bucket and drops the bucket once it is empty
+func (b *ttlBuckets) remove(element *item) {
+	bucket := element.bucket
+	if element.bprev != nil {
+		element.bprev.bnext = element.bnext
+	} else {
+		bucket.head = element.bnext
+	}
+	if element.bnext != nil {
+		element.bnext.bprev = element.bprev
+	}
+	element.bucket, element.bprev, element.bnext = nil, nil, nil
+	bucket.len--
+
+	if bucket.len == 0 {
+		delete(b.byKey, bucket.key)
+		for i, key := range b.keys {
+			if key == bucket.key {
+				heap.Remove(&b.keys, i)
+				break
+			}
+		}
+	}
+}
+
+// bucketKeys is the min-heap of the keys of the existing buckets
+type bucketKeys []int64
+
+func (h bucketKeys) Len() int            { return len(h) }
+func (h bucketKeys) Less(i, j int) bool  { return h[i] < h[j] }
+func (h bucketKeys) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *bucketKeys) Push(x interface{}) { *h = append(*h, x.(int64)) }
+
+func (h *bucketKeys) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return x
+}
@@ -0,0 +1,60 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLBuckets(t *testing.T) {
+	var expired []string
+	c, err := NewCache(0, WithTTL(0.05), WithTTLBuckets(10*time.Millisecond),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			require.Equal(t, EvictedByTTL, reason)
+			expired = append(expired, key)
+		}))
+	require.NoError(t, err)
+	tc := c.(*cache)
+
+	for i := 0; i < 10; i++ {
+		c.Add("old"+strconv.Itoa(i), i)
+	}
+	time.Sleep(30 * time.Millisecond)
+	c.Add("new", 1)
+	c.ChangeValue("old0", 0)
+	lease, err := c.AcquireLease("old1", time.Minute)
+	require.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+	tc.inspect()
+	require.Len(t, expired, 8)
+	require.ElementsMatch(t, []string{"new", "old0", "old1"}, c.Keys())
+
+	lease.Release()
+	time.Sleep(30 * time.Millisecond)
+	tc.inspect()
+	require.Len(t, expired, 11)
+	require.Zero(t, c.Len())
+	require.Empty(t, tc.buckets.byKey)
+	require.Empty(t, tc.buckets.keys)
+}
+
+func TestTTLBucketsRemove(t *testing.T) {
+	c, err := NewCache(2, WithTTL(60), WithTTLBuckets(time.Second))
+	require.NoError(t, err)
+	tc := c.(*cache)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	c.Add("third", 3)
+	c.Remove("second")
+	require.Len(t, tc.buckets.byKey, 1)
+	for _, bucket := range tc.buckets.byKey {
+		require.Equal(t, 1, bucket.len)
+	}
+
+	c.Clear()
+	require.Empty(t, tc.buckets.byKey)
+}
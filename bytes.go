@@ -0,0 +1,48 @@
+package golru
+
+// AddBytes works like Add for the key given as a byte slice. The key is copied into a new string, as the cache has
+// to store it anyway
+func (c *cache) AddBytes(key []byte, value interface{}) bool {
+	_, err := c.do(OpAdd, string(key), value)
+
+	return err == nil
+}
+
+// GetBytes works like Get for the key given as a byte slice. The lookup doesn't allocate a string for the key, so
+// it can be used directly with the keys parsed out of network buffers. The hooks and the middleware, if set, still
+// get the key as a string, and so do the transformations of the values like the compression, the miss-ratio curve,
+// the hot key detection and the tenants
+func (c *cache) GetBytes(key []byte) (interface{}, bool) {
+	if c.pipeline != nil || c.transforms() {
+		return c.Get(string(key))
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	element, ok := c.items[string(key)]
+	if c.countsReads() {
+		value, err := c.read(string(key), element, ok)
+		return value, err == nil
+	}
+	value, err := c.access(element, ok)
+
+	return value, err == nil
+}
+
+// RemoveBytes works like Remove for the key given as a byte slice without allocating a string for the key
+func (c *cache) RemoveBytes(key []byte) bool {
+	if c.pipeline != nil {
+		return c.Remove(string(key))
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	element, ok := c.items[string(key)]
+	if !ok {
+		return false
+	}
+
+	return c.remove(element.key) == nil
+}
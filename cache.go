@@ -1,8 +1,10 @@
 package golru
 
 import (
-	"container/list"
+	"context"
 	"errors"
+	"hash/maphash"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -10,7 +12,32 @@ import (
 type seconds float64
 type CacheOption func(*cache)
 
+// EvictReason explains why the element has left the cache without a direct request of the user
+type EvictReason int
+
+const (
+	// EvictedByCapacity means that the element was the last one in the list when there was no more room in the cache
+	EvictedByCapacity EvictReason = iota + 1
+	// EvictedByTTL means that the lifetime of the element has come to an end
+	EvictedByTTL
+	// EvictedByPressure means that the cache was shedding its tail because of the memory usage of the process
+	EvictedByPressure
+	// EvictedByClear means that the whole cache was cleared
+	EvictedByClear
+	// EvictedByReplace means that the value was replaced by ChangeValue in the cache with immutable values, the key
+	// stays in the cache with the new value
+	EvictedByReplace
+	// EvictedByDependency means that a key the element depends on was invalidated, see AddWithDependencies
+	EvictedByDependency
+)
+
+// EvictCallback is called for every evicted element. It is called after the cache is unlocked, so it can use the cache
+// methods. By default it runs in the goroutine of the operation which caused the eviction, see WithEvictWorkers for
+// the asynchronous alternative
+type EvictCallback func(key string, value interface{}, reason EvictReason)
+
 var (
+	// Deprecated: capacity 0 creates an unbounded cache, so NewCache doesn't return this error anymore
 	ErrCacheCapacity = errors.New("capacity of the cache can not be less than 1")
 )
 
@@ -22,40 +49,187 @@ var (
 // All fields are non-exportable, which allows you to work with the content through methods without having
 // direct access to the cache
 type cache struct {
-	mu    sync.Mutex
-	items map[string]*list.Element
-	chain *list.List
+	mu       sync.Mutex
+	lockedAt time.Time
+	items    map[string]*item
+	chain    *chain
 
 	capacity uint32
 	ttl      seconds
+	lifetime time.Duration
+	adaptive *AdaptiveTTL
+	idle     time.Duration
+	maxAge   time.Duration
+	buckets  *ttlBuckets
+	onEvict  EvictCallback
+	onPanic  PanicHandler
+	pooled   bool
+	hasher   Hasher
+	weigher  Weigher
+	logger   *slog.Logger
+	hooks    *hooks
+	ctxHooks *contextHooks
+	tracer   *TraceRecorder
+	indexes  map[string]*index
+	staged   map[string]map[string]string
+	tenancy  *tenancy
+
+	normalizer func(string) string
+	validator  func(string) error
+	scope      func(ctx context.Context) string
+	redactor   func(key string) string
+	auditor    AuditFunc
+
+	onEvictMeta EvictMetaCallback
+	dependents  map[string]map[string]struct{}
+	deferring   bool
+	deferred    []string
+	tree        *keyTree
+	scan        *scanTable
+	tombstones  *tombstones
+	prefixes    []*prefixLimit
+	callers     *callers
+
+	leaseSeq uint64
+	version  uint64
+
+	copier    ValueCopier
+	copyMode  CopyMode
+	immutable bool
+	autoClose bool
+
+	codec         Codec
+	compressor    Compressor
+	minCompressed int
+
+	dispatcher *dispatcher
+	overflow   OverflowPolicy
+	evicted    []evictEvent
+	audits     []AuditRecord
+
+	middleware []Middleware
+	pipeline   ctxOpFunc
+
+	interning   bool
+	lockMetrics bool
+
+	nursery *nursery
+	policy  EvictionPolicy
+	hand    *item
+	s3      *s3fifo
+	samples int
+
+	retrievalCost CostFunc
+	inflation     float64
+
+	decay     FrequencyDecay
+	freqLimit uint32
+	epoch     uint32
+	decayedAt time.Time
+	accesses  uint64
+
+	softCapacity uint32
+	trimBatch    int
+	trimSignal   chan struct{}
+
+	stats          Stats
+	window         *hitWindow
+	curve          *missCurve
+	hot            *hotKeys
+	hotEvents      []hotEvent
+	youngAge       time.Duration
+	youngEvictions uint64
+
+	flights flights
+	life    *lifecycle
+	closed  bool
+	opts    []CacheOption
 }
 
-// NewCache create new implementation of lru cache. Capacity can't be less than one. If you set capacity to zero,
-// for example, an assignment error will return
+// NewCache create new implementation of lru cache. If you set capacity to zero, the cache becomes unbounded: the
+// number of entries is not limited, and they leave the cache only by removing or by ttl
 func NewCache(n uint32, opts ...CacheOption) (Cacher, error) {
-	if n == 0 {
-		return nil, ErrCacheCapacity
-	}
-
 	c := &cache{
 		capacity: n,
-		items:    make(map[string]*list.Element, n),
-		chain:    list.New(),
+		items:    make(map[string]*item, n),
+		chain:    newChain(),
+		scan:     newScanTable(maphash.MakeSeed()),
+		life:     newLifecycle(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	c.opts = opts
+	c.lifetime = toNanosecond(float64(c.ttl))
+	c.freqLimit = frequencyLimit(c.decay.Width)
+	if c.policy == PolicyS3FIFO {
+		c.s3 = newS3FIFO()
+	}
+	if c.dispatcher != nil {
+		c.dispatcher.run = c.callOnEvict
+		c.dispatcher.overflow = c.overflow
+		c.dispatcher.report = c.reportDropped
+	}
+	c.buildPipeline()
+	c.checkConfig()
+
+	if c.softCapacity != 0 && c.capacity != 0 && c.softCapacity >= c.capacity {
+		return nil, ErrSoftCapacity
+	}
+	if c.nursery != nil && c.capacity != 0 && c.nursery.cfg.Capacity >= c.capacity {
+		return nil, ErrNurseryCapacity
+	}
+	if c.nursery != nil && c.s3 != nil {
+		return nil, ErrNurseryPolicy
+	}
+	if c.adaptive != nil && (c.adaptive.Min <= 0 || c.adaptive.Min > c.adaptive.Max || c.adaptive.PerHit < 0) {
+		return nil, ErrAdaptiveTTL
+	}
+
 	return c, nil
 }
 
-// item is an element inside *list.Element of cache with the key and value used by your program
+// item is an element of the cache list with the key and value used by your program. It carries the links of the
+// list itself, so no separate list element is allocated for it
 type item struct {
 	key   string
 	value interface{}
 
+	prev, next *item
+	slot       int
+	keyHandle  interface{}
+	leases     map[uint64]time.Time
+	tenant     *tenant
+	cost       uint64
+
+	bucket       *ttlBucket
+	bprev, bnext *item
+
+	position uint64
+	snext    *item
+
+	tenantLinks groupLinks
+	callerLinks groupLinks
+	prefixLinks []groupLinks
+
+	visited      bool
+	freq         uint32
+	epoch        uint32
+	priority     float64
+	queue        *queue
+	qprev, qnext *item
+
 	creationTime time.Time
+	bornTime     time.Time
+	deadline     time.Time
+	lastAccess   time.Time
+	hits         uint64
+	version      uint64
+	meta         interface{}
+	parents      []string
+	caller       *caller
 }
 
 func WithTTL(ttl seconds) CacheOption {
@@ -63,3 +237,36 @@ func WithTTL(ttl seconds) CacheOption {
 		cache.ttl = ttl
 	}
 }
+
+// WithIdleTimeout makes the element expire if it wasn't read or written for the duration d. It works together with
+// the ttl, so the element leaves the cache when either of them ends, as with expire-after-write and
+// expire-after-access set at once
+func WithIdleTimeout(d time.Duration) CacheOption {
+	return func(cache *cache) {
+		cache.idle = d
+	}
+}
+
+// WithMaxLifetime sets the hard limit of the lifetime of the elements. The element is dropped after d since it was
+// added even if the ttl is renewed by the writes, the idle timeout by the reads, or the element is leased. Replacing
+// the value doesn't make the element younger, only removing and adding it again does
+func WithMaxLifetime(d time.Duration) CacheOption {
+	return func(cache *cache) {
+		cache.maxAge = d
+	}
+}
+
+// WithUnbounded removes the limit on the number of entries, regardless of the capacity passed to NewCache. It is the
+// same as zero capacity and makes sense together with WithTTL
+func WithUnbounded() CacheOption {
+	return func(cache *cache) {
+		cache.capacity = 0
+	}
+}
+
+// WithOnEvict sets the callback which is called for every element evicted from the cache because of capacity or ttl
+func WithOnEvict(fn EvictCallback) CacheOption {
+	return func(cache *cache) {
+		cache.onEvict = fn
+	}
+}
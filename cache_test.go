@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,10 +23,27 @@ func TestInit(t *testing.T) {
 	require.Equal(t, 0, len(tc.items))
 }
 
-func TestInitSuccess(t *testing.T) {
+func TestInitUnbounded(t *testing.T) {
 	c, err := NewCache(0)
-	require.Error(t, err)
-	require.Nil(t, c)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		require.True(t, c.Add("test"+strconv.Itoa(i), i))
+	}
+	require.Equal(t, 100, c.Len())
+}
+
+func TestWithUnbounded(t *testing.T) {
+	c, err := NewCache(2, WithUnbounded())
+	require.NoError(t, err)
+
+	c.Add("test1", 42)
+	c.Add("test2", 43)
+	c.Add("test3", 44)
+	require.Equal(t, 3, c.Len())
+
+	c.ChangeCapacity(2)
+	require.Equal(t, 2, c.Len())
 }
 
 func TestAddPositive(t *testing.T) {
@@ -39,7 +58,7 @@ func TestAddPositive(t *testing.T) {
 	require.Equal(t, 1, len(tc.items))
 
 	elem := tc.chain.Front()
-	require.Equal(t, 45, elem.Value.(*item).value)
+	require.Equal(t, 45, elem.value)
 	require.Equal(t, elem, tc.items["test"])
 
 	fail := c.Add("test", "sos")
@@ -77,11 +96,11 @@ func TestAddRemoveLast(t *testing.T) {
 	require.True(t, answer3)
 
 	frontItem := tc.chain.Front()
-	require.Equal(t, 101, frontItem.Value.(*item).value)
+	require.Equal(t, 101, frontItem.value)
 	require.Equal(t, frontItem, tc.items["new"])
 
 	backItem := tc.chain.Back()
-	require.Equal(t, "sos", backItem.Value.(*item).value)
+	require.Equal(t, "sos", backItem.value)
 	require.Equal(t, backItem, tc.items["testing"])
 }
 
@@ -105,7 +124,7 @@ func TestGetPositive(t *testing.T) {
 	require.Equal(t, "sos", value)
 
 	frontItem := tc.chain.Front()
-	require.Equal(t, "sos", frontItem.Value.(*item).value)
+	require.Equal(t, "sos", frontItem.value)
 }
 
 func TestGetNegative(t *testing.T) {
@@ -166,14 +185,14 @@ func TestSetValueSuccess(t *testing.T) {
 
 	answer := c.ChangeValue("test", 0)
 	require.True(t, answer)
-	require.Equal(t, 0, tc.items["test"].Value.(*item).value)
-	require.Equal(t, 102, tc.items["tests"].Value.(*item).value)
-	require.Equal(t, 103, tc.items["testing"].Value.(*item).value)
+	require.Equal(t, 0, tc.items["test"].value)
+	require.Equal(t, 102, tc.items["tests"].value)
+	require.Equal(t, 103, tc.items["testing"].value)
 
 	frontItem := tc.chain.Front()
-	require.Equal(t, 0, frontItem.Value.(*item).value)
+	require.Equal(t, 0, frontItem.value)
 	backItem := tc.chain.Back()
-	require.Equal(t, 102, backItem.Value.(*item).value)
+	require.Equal(t, 102, backItem.value)
 }
 
 func TestSetValueInvalidKey(t *testing.T) {
@@ -196,6 +215,28 @@ func TestRemoveAll(t *testing.T) {
 	require.Equal(t, 0, c.Len())
 }
 
+func TestClearOnEvict(t *testing.T) {
+	evicted := make(map[string]EvictReason)
+	c, err := NewCache(3, WithItemPool(), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted[key] = reason
+	}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("test", 101)
+	c.Add("tests", 102)
+	c.Clear()
+	require.Equal(t, map[string]EvictReason{"test": EvictedByClear, "tests": EvictedByClear}, evicted)
+	require.Len(t, tc.items, 0)
+	require.Nil(t, tc.chain.Front())
+
+	require.True(t, c.Add("test", 103))
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, 103, value)
+	require.Equal(t, "clear", EvictedByClear.String())
+}
+
 func TestChangeCapacityToLarge(t *testing.T) {
 	c, err := NewCache(1)
 	require.NoError(t, err)
@@ -214,10 +255,42 @@ func TestChangeCapacityToLess(t *testing.T) {
 	c.Add("test2", 43)
 	c.Add("test3", 44)
 	c.Add("test4", 45)
-	c.ChangeCapacity(2)
+	require.Equal(t, 2, c.ChangeCapacity(2))
 	require.Equal(t, 2, c.Len())
 }
 
+func TestChangeCapacityOnEvict(t *testing.T) {
+	var evicted []string
+	c, err := NewCache(3, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		require.Equal(t, EvictedByCapacity, reason)
+		evicted = append(evicted, key)
+	}))
+	require.NoError(t, err)
+
+	c.Add("test1", 42)
+	c.Add("test2", 43)
+	c.Add("test3", 44)
+	require.Equal(t, 2, c.ChangeCapacity(1))
+	require.Equal(t, []string{"test1", "test2"}, evicted)
+
+	c.Add("test4", 45)
+	require.Equal(t, []string{"test1", "test2", "test3"}, evicted)
+}
+
+func TestOnEvictTTL(t *testing.T) {
+	var reasons []EvictReason
+	c, err := NewCache(3, WithTTL(0.05), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		reasons = append(reasons, reason)
+	}))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	time.Sleep(100 * time.Millisecond)
+	_, ok := c.Get("test")
+	require.False(t, ok)
+	require.Equal(t, []EvictReason{EvictedByTTL}, reasons)
+}
+
 func TestChangeCapacityToZero(t *testing.T) {
 	c, err := NewCache(1)
 	require.NoError(t, err)
@@ -225,6 +298,9 @@ func TestChangeCapacityToZero(t *testing.T) {
 	c.Add("test", 42)
 	c.ChangeCapacity(0)
 	require.Equal(t, 1, c.Len())
+
+	c.Add("new", 43)
+	require.Equal(t, 2, c.Len())
 }
 
 func TestValues(t *testing.T) {
@@ -238,6 +314,98 @@ func TestValues(t *testing.T) {
 
 	values := c.Values()
 	require.Len(t, values, 4)
+	require.ElementsMatch(t, []interface{}{42, 43, 44, 45}, values)
+}
+
+func TestItemPool(t *testing.T) {
+	var evicted []interface{}
+	c, err := NewCache(2, WithItemPool(), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted = append(evicted, value)
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, c.Add("test"+strconv.Itoa(i), i))
+	}
+	require.Equal(t, []interface{}{0, 1, 2, 3, 4, 5, 6, 7}, evicted)
+	require.True(t, c.Remove("test9"))
+
+	value, ok := c.Get("test8")
+	require.True(t, ok)
+	require.Equal(t, 8, value)
+	require.ElementsMatch(t, []string{"test8"}, c.Keys())
+}
+
+func TestEstimatedBytes(t *testing.T) {
+	c, err := NewCache(3)
+	require.NoError(t, err)
+	require.Zero(t, c.EstimatedBytes())
+
+	c.Add("test", "value")
+	c.Add("bytes", make([]byte, 10, 100))
+	c.Add("int", 42)
+	overhead := 3 * (itemBytes + mapEntryBytes)
+	require.Equal(t, overhead+4+5+5+100+3, c.EstimatedBytes())
+
+	weighted, err := NewCache(3, WithWeigher(func(key string, value interface{}) uint64 {
+		return 1000
+	}))
+	require.NoError(t, err)
+	weighted.Add("int", 42)
+	require.Equal(t, itemBytes+mapEntryBytes+3+1000, weighted.EstimatedBytes())
+}
+
+func TestConfig(t *testing.T) {
+	c, err := NewCache(10, WithTTL(1.5), WithSoftCapacity(5, 0), WithItemPool(), WithEvictWorkers(2, 0),
+		WithEvictOverflow(OverflowDropNewest), WithHitWindow(time.Second, 60))
+	require.NoError(t, err)
+
+	require.Equal(t, 10, c.Cap())
+	require.Equal(t, Config{
+		Capacity:     10,
+		SoftCapacity: 5,
+		TTL:          1500 * time.Millisecond,
+		Shards:       1,
+		ItemPool:     true,
+		HitWindow:    time.Minute,
+		EvictWorkers: 2,
+		EvictQueue:   defaultEvictQueue,
+		Overflow:     OverflowDropNewest,
+	}, c.Config())
+
+	c.ChangeCapacity(0)
+	require.Zero(t, c.Cap())
+
+	s, err := NewShardedCache(4, 10)
+	require.NoError(t, err)
+	require.Equal(t, 10, s.Cap())
+	require.Equal(t, 4, s.Config().Shards)
+	require.Equal(t, uint32(10), s.Config().Capacity)
+}
+
+func TestChain(t *testing.T) {
+	l := newChain()
+	require.Nil(t, l.Front())
+	require.Nil(t, l.Back())
+
+	first := l.PushFront(&item{key: "first"})
+	second := l.PushFront(&item{key: "second"})
+	third := l.PushFront(&item{key: "third"})
+	require.Equal(t, 3, l.Len())
+	require.Equal(t, third, l.Front())
+	require.Equal(t, first, l.Back())
+	require.Equal(t, second, l.Next(third))
+	require.Nil(t, l.Next(first))
+	require.Nil(t, l.Prev(third))
+
+	l.MoveToFront(first)
+	require.Equal(t, first, l.Front())
+	require.Equal(t, second, l.Back())
+
+	l.Remove(third)
+	require.Equal(t, 2, l.Len())
+	require.Equal(t, second, l.Next(first))
+	require.Nil(t, third.next)
 }
 
 func TestReflectKeys(t *testing.T) {
@@ -285,8 +453,18 @@ func TestExpireFractional(t *testing.T) {
 	require.NoError(t, err)
 
 	time.Sleep(1 * time.Second)
-	require.Equal(t, tc.chain.Len(), 0)
-	require.Len(t, tc.items, 0)
+	chained, items := tc.sizes()
+	require.Zero(t, chained)
+	require.Zero(t, items)
+}
+
+// sizes returns the lengths of the list and of the hash table of the cache under its lock, so the tests can check
+// them while the janitor is running
+func (c *cache) sizes() (int, int) {
+	c.lock()
+	defer c.unlock()
+
+	return c.chain.Len(), len(c.items)
 }
 
 func TestExpireInteger(t *testing.T) {
@@ -307,8 +485,9 @@ func TestExpireInteger(t *testing.T) {
 	require.NoError(t, err)
 
 	time.Sleep(3 * time.Second)
-	require.Equal(t, tc.chain.Len(), 0)
-	require.Len(t, tc.items, 0)
+	chained, items := tc.sizes()
+	require.Zero(t, chained)
+	require.Zero(t, items)
 }
 
 func TestExpireZeroTTL(t *testing.T) {
@@ -324,6 +503,181 @@ func TestExpireZeroTTL(t *testing.T) {
 	require.ErrorIs(t, err, ErrZeroTTL)
 }
 
+func TestAddE(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddE("test", 45))
+	require.ErrorIs(t, c.AddE("test", 46), ErrKeyExists)
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, 45, value)
+}
+
+func TestGetE(t *testing.T) {
+	c, err := NewCache(2, WithTTL(0.05))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+
+	c.Add("test", 45)
+	value, err := c.GetE("test")
+	require.NoError(t, err)
+	require.Equal(t, 45, value)
+
+	_, err = c.GetE("testing")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	time.Sleep(100 * time.Millisecond)
+	value, err = c.GetE("test")
+	require.ErrorIs(t, err, ErrExpired)
+	require.Nil(t, value)
+	require.Equal(t, 0, tc.chain.Len())
+	require.Len(t, tc.items, 0)
+
+	_, err = c.GetE("test")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestRemoveE(t *testing.T) {
+	c, err := NewCache(2, WithTTL(0.05))
+	require.NoError(t, err)
+
+	c.Add("test", 45)
+	require.NoError(t, c.RemoveE("test"))
+	require.ErrorIs(t, c.RemoveE("test"), ErrKeyNotFound)
+
+	c.Add("test", 45)
+	time.Sleep(100 * time.Millisecond)
+	require.ErrorIs(t, c.RemoveE("test"), ErrExpired)
+	require.Equal(t, 0, c.Len())
+}
+
+func TestAddReplacesExpired(t *testing.T) {
+	c, err := NewCache(2, WithTTL(0.05))
+	require.NoError(t, err)
+
+	c.Add("test", 45)
+	time.Sleep(100 * time.Millisecond)
+	require.True(t, c.Add("test", 46))
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, 46, value)
+}
+
+func TestStats(t *testing.T) {
+	c, err := NewCache(1)
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	c.Get("testing")
+	c.Add("new", 43)
+
+	stats := c.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, uint64(2), stats.Adds)
+	require.Equal(t, uint64(1), stats.Evictions)
+	require.Equal(t, 0.5, stats.HitRatio())
+}
+
+func TestStatsDeltaAndReset(t *testing.T) {
+	c, err := NewCache(1)
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	prev := c.Stats()
+
+	c.Get("test")
+	c.Get("testing")
+	c.Add("new", 43)
+	delta := c.Stats().Delta(prev)
+	require.Equal(t, uint64(1), delta.Hits)
+	require.Equal(t, uint64(1), delta.Misses)
+	require.Equal(t, uint64(1), delta.Adds)
+	require.Equal(t, uint64(1), delta.Evictions)
+	require.Equal(t, uint64(1), delta.EvictionAge.Count())
+
+	prev = c.Stats()
+	c.ResetStats()
+	require.Zero(t, c.Stats())
+
+	c.Get("new")
+	delta = c.Stats().Delta(prev)
+	require.Equal(t, uint64(1), delta.Hits)
+	require.Zero(t, delta.Misses)
+}
+
+func TestStatsHistograms(t *testing.T) {
+	c, err := NewCache(1, WithTTL(60))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	time.Sleep(20 * time.Millisecond)
+	c.Add("new", 43)
+
+	stats := c.Stats()
+	require.Equal(t, uint64(1), stats.EvictionAge.Count())
+	require.Equal(t, 100*time.Millisecond, stats.EvictionAge.Quantile(0.5))
+	require.Equal(t, uint64(1), stats.AccessTTL.Count())
+	require.Equal(t, time.Minute, stats.AccessTTL.Quantile(0.99))
+
+	var h Histogram
+	require.Zero(t, h.Quantile(0.5))
+	h.observe(48 * time.Hour)
+	require.Equal(t, time.Duration(-1), h.Quantile(0.5))
+	require.Len(t, h.Bounds(), len(h)-1)
+}
+
+func TestHotPathAllocations(t *testing.T) {
+	c, err := NewCache(100, WithTTL(60), WithItemPool())
+	require.NoError(t, err)
+
+	var value interface{} = 42
+	c.Add("key", value)
+
+	require.Zero(t, testing.AllocsPerRun(100, func() { c.Get("key") }))
+	require.Zero(t, testing.AllocsPerRun(100, func() { c.Get("missing") }))
+	require.Zero(t, testing.AllocsPerRun(100, func() { c.Add("key", value) }))
+	require.Zero(t, testing.AllocsPerRun(100, func() { c.ChangeValue("key", value) }))
+	require.Zero(t, testing.AllocsPerRun(100, func() {
+		c.Remove("key")
+		c.Add("key", value)
+	}))
+}
+
+func TestBytesKeys(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	key := []byte("test")
+	require.True(t, c.AddBytes(key, 42))
+	require.False(t, c.AddBytes(key, 43))
+	key[0] = 'b'
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, 42, value)
+
+	value, ok = c.GetBytes([]byte("test"))
+	require.True(t, ok)
+	require.Equal(t, 42, value)
+	_, ok = c.GetBytes(key)
+	require.False(t, ok)
+
+	lookup := []byte("test")
+	require.Zero(t, testing.AllocsPerRun(100, func() { c.GetBytes(lookup) }))
+
+	require.False(t, c.RemoveBytes(key))
+	require.True(t, c.RemoveBytes([]byte("test")))
+	require.Equal(t, 0, c.Len())
+}
+
 // Benchmarks
 
 func BenchmarkReflectKeys(b *testing.B) {
@@ -393,6 +747,7 @@ func BenchmarkGet(b *testing.B) {
 		Integer:   123456789987654321,
 		NewStruct: struct{ Flo float64 }{Flo: 456215.12165468},
 	})
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		cache.Get("key")
 	}
@@ -438,6 +793,39 @@ func BenchmarkAdd(b *testing.B) {
 	}
 }
 
+func BenchmarkGolangLruAddEvict(b *testing.B) {
+	cache, err := lru.New(100)
+	if err != nil {
+		log.Fatal("error")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cache.Add("key"+strconv.Itoa(i%1000), i)
+	}
+}
+
+func BenchmarkAddEvict(b *testing.B) {
+	cache, err := NewCache(100)
+	if err != nil {
+		log.Fatal("error")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cache.Add("key"+strconv.Itoa(i%1000), i)
+	}
+}
+
+func BenchmarkAddEvictPooled(b *testing.B) {
+	cache, err := NewCache(100, WithItemPool())
+	if err != nil {
+		log.Fatal("error")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cache.Add("key"+strconv.Itoa(i%1000), i)
+	}
+}
+
 func BenchmarkGolangLruRemove(b *testing.B) {
 	cache, err := lru.New(100)
 	if err != nil {
@@ -483,3 +871,90 @@ func BenchmarkRemove(b *testing.B) {
 		cache.Remove("key")
 	}
 }
+
+func TestGetOrAdd(t *testing.T) {
+	c, err := NewCache(2, WithTTL(0.01))
+	require.NoError(t, err)
+
+	actual, loaded := c.GetOrAdd("test", 1)
+	require.False(t, loaded)
+	require.Equal(t, 1, actual)
+
+	actual, loaded = c.GetOrAdd("test", 2)
+	require.True(t, loaded)
+	require.Equal(t, 1, actual)
+	require.Equal(t, uint64(1), c.Stats().Hits)
+
+	time.Sleep(20 * time.Millisecond)
+	actual, loaded = c.GetOrAdd("test", 3)
+	require.False(t, loaded)
+	require.Equal(t, 3, actual)
+}
+
+func TestGetOrAddConcurrent(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		added int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, loaded := c.GetOrAdd("shared", i)
+			if !loaded {
+				mu.Lock()
+				added++
+				mu.Unlock()
+				require.Equal(t, i, actual)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, added)
+}
+
+func TestSwap(t *testing.T) {
+	c, err := NewCache(2, WithCompression(FlateCompressor(1), 1))
+	require.NoError(t, err)
+
+	old, existed := c.Swap("test", "first")
+	require.False(t, existed)
+	require.Nil(t, old)
+	require.Zero(t, c.Len())
+
+	text := strings.Repeat("value ", 20)
+	c.Add("test", text)
+	old, existed = c.Swap("test", "second")
+	require.True(t, existed)
+	require.Equal(t, text, old)
+
+	value, _ := c.Get("test")
+	require.Equal(t, "second", value)
+}
+
+func TestAddReturningEvicted(t *testing.T) {
+	var callback []string
+	c, err := NewCache(2, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		callback = append(callback, key)
+	}))
+	require.NoError(t, err)
+
+	_, _, evicted := c.AddReturningEvicted("first", 1)
+	require.False(t, evicted)
+	c.Add("second", 2)
+
+	key, value, evicted := c.AddReturningEvicted("third", 3)
+	require.True(t, evicted)
+	require.Equal(t, "first", key)
+	require.Equal(t, 1, value)
+	require.Equal(t, []string{"first"}, callback)
+
+	_, _, evicted = c.AddReturningEvicted("third", 30)
+	require.False(t, evicted)
+	require.Equal(t, 2, c.Len())
+}
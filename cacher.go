@@ -1,30 +1,135 @@
 package golru
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 type Cacher interface {
 	Expire(ctx context.Context) error
+	Clone(copier ValueCopier) Cacher
+	FilterInto(pred func(Entry) bool) Cacher
+	Merge(other Cacher, resolve ConflictFunc) int
+	ImportCSV(r io.Reader, codec Codec) (int, error)
+	AutoTune(ctx context.Context, cfg TuneConfig) error
+	WatchMemory(ctx context.Context, cfg PressureConfig) error
+	WatchGC(ctx context.Context, cfg GCConfig) error
+	Trim(ctx context.Context) error
+	Drain(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+	Close() error
+	Health() Health
+	SchedulePurge(ctx context.Context, spec string, pred func(Entry) bool) error
+	Purge(pred func(Entry) bool) int
+	AddForTenant(tenant, key string, value interface{}) error
+	AddWithMeta(key string, value interface{}, meta interface{}) error
+	AddWithDependencies(key string, value interface{}, deps ...string) error
+	Invalidate(key string) int
+	TenantStats() []TenantStats
+	CallerStats() []CallerStats
+	GetMulti(ctx context.Context, keys []string, load BatchLoadFunc) (map[string]interface{}, error)
 
 	Editor
 	Informer
+	Inspector
 	Changer
+	Scanner
+	Indexer
+	Leaser
+	Versioner
+	Scheduler
+	Estimator
 }
 
 type Editor interface {
 	Add(key string, value interface{}) bool
+	AddE(key string, value interface{}) error
+	AddReturningEvicted(key string, value interface{}) (evictedKey string, evictedValue interface{}, evicted bool)
 	Get(key string) (interface{}, bool)
+	GetE(key string) (interface{}, error)
+	GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool)
 	Remove(key string) bool
+	RemoveE(key string) error
 	Clear()
+
+	GetContext(ctx context.Context, key string) (interface{}, error)
+	AddContext(ctx context.Context, key string, value interface{}) error
+	RemoveContext(ctx context.Context, key string) error
+	ChangeValueContext(ctx context.Context, key string, value interface{}) error
+
+	AddBytes(key []byte, value interface{}) bool
+	GetBytes(key []byte) (interface{}, bool)
+	RemoveBytes(key []byte) bool
 }
 
 type Changer interface {
 	ChangeValue(key string, newValue interface{}) bool
-	ChangeCapacity(newCap uint32)
+	Swap(key string, newValue interface{}) (old interface{}, existed bool)
+	Update(key string, fn UpdateFunc) (interface{}, error)
+	AppendToSlice(key string, elems ...interface{}) (int, error)
+	Increment(key string, delta int64) (int64, error)
+	ChangeCapacity(newCap uint32) int
 }
 
 type Informer interface {
 	Len() int
+	Cap() int
+	Config() Config
 	Keys() []string
 	ReflectKeys() []string
 	Values() []interface{}
+	Stats() Stats
+	ResetStats()
+	WindowHitRatio(d time.Duration) float64
+	HotKeys() []HotKey
+	EstimatedBytes() uint64
+}
+
+type Inspector interface {
+	GetEntry(key string) (Entry, bool)
+	Entries() []Entry
+	TopKeys(n int) []Entry
+	FrequentKeys(n int) []string
+	ColdestKeys(n int) []Entry
+	Dump(w io.Writer, opts ...DumpOption) error
+	ExportCSV(w io.Writer, codec Codec) error
+	String() string
+}
+
+type Scanner interface {
+	KeysPage(cursor Cursor, limit int) ([]string, Cursor)
+	RandomKey() (string, bool)
+	SampleEntries(n int) []Entry
+}
+
+type Indexer interface {
+	GetByIndex(name, attr string) []Entry
+	InvalidateByIndex(name, attr string) int
+	InvalidateSubtree(path string) int
+	ListChildren(path string) []string
+}
+
+type Leaser interface {
+	AcquireLease(key string, d time.Duration) (Lease, error)
+	PinWithContext(ctx context.Context, key string) error
+}
+
+type Versioner interface {
+	AddVersion(key string, value interface{}) (uint64, error)
+	ChangeValueVersion(key string, newValue interface{}) (uint64, error)
+	GetAtLeast(key string, version uint64) (interface{}, bool)
+	GetIfChanged(key string, knownVersion uint64) (interface{}, uint64, error)
+}
+
+type Scheduler interface {
+	AddWithDeadline(key string, value interface{}, deadline time.Time) error
+	AddUntilEndOfHour(key string, value interface{}, loc *time.Location) error
+	AddUntilEndOfDay(key string, value interface{}, loc *time.Location) error
+}
+
+type Estimator interface {
+	EstimateHitRatio(capacity uint32) (float64, bool)
+	MissRatioCurve(capacities ...uint32) []CurvePoint
+	AdviseCapacity(target float64) (CapacityAdvice, error)
 }
@@ -0,0 +1,260 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+var ErrThrottled = errors.New("caller exceeded its rate of the inserts")
+
+// CallerQuota limits the writes of a single caller. MaxEntries is the number of the resident elements added by it,
+// Rate is the number of the inserts per second with the burst of up to Burst of them, which is the rate rounded up
+// by default. Zero means no limit
+type CallerQuota struct {
+	MaxEntries int
+	Rate       float64
+	Burst      int
+}
+
+// CallerQuotas describes the limits of the callers identified by WithPrincipal. Quotas are the limits of the named
+// callers, and Default is the limit of all the others
+type CallerQuotas struct {
+	Quotas  map[string]CallerQuota
+	Default CallerQuota
+}
+
+// CallerStats describes the writes of a single caller. Throttled counts the inserts rejected by its rate, and
+// Evictions counts its elements evicted to keep it within MaxEntries
+type CallerStats struct {
+	Caller    string
+	Entries   int
+	Adds      uint64
+	Throttled uint64
+	Evictions uint64
+}
+
+// callers keeps the accounting of the callers
+type callers struct {
+	cfg     CallerQuotas
+	callers map[string]*caller
+}
+
+// caller is the accounting of a single caller with the bucket of the tokens of its inserts and the list of its
+// resident elements
+type caller struct {
+	quota    CallerQuota
+	stats    CallerStats
+	tokens   float64
+	filled   time.Time
+	elements group
+}
+
+// callerLinks returns the links of the element in the list of its caller
+func callerLinks(it *item) *groupLinks {
+	return &it.callerLinks
+}
+
+// WithCallerQuotas attributes the elements added by AddContext to the principal of its context, see WithPrincipal,
+// and limits every principal by its quota, so a badly behaved caller like a plugin can't take over the cache. The
+// insert over the rate returns ErrThrottled, and the insert over MaxEntries evicts the least recently used element
+// of the same caller, or returns ErrRejected if all of them are leased. The writes without a principal aren't limited
+func WithCallerQuotas(cfg CallerQuotas) CacheOption {
+	return func(cache *cache) {
+		cache.callers = &callers{cfg: cfg, callers: make(map[string]*caller)}
+	}
+}
+
+// CallerStats returns the writes of every caller seen so far in the order of their names, or nil without
+// WithCallerQuotas
+func (c *cache) CallerStats() []CallerStats {
+	c.lock()
+	defer c.unlock()
+
+	if c.callers == nil {
+		return nil
+	}
+
+	stats := make([]CallerStats, 0, len(c.callers.callers))
+	for _, cl := range c.callers.callers {
+		stats = append(stats, cl.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Caller < stats[j].Caller })
+
+	return stats
+}
+
+// callerOf returns the caller of the context, or nil if it has no principal or the quotas aren't set
+func (c *cache) callerOf(ctx context.Context) *caller {
+	if c.callers == nil {
+		return nil
+	}
+	principal := PrincipalFrom(ctx)
+	if principal == "" {
+		return nil
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	return c.callers.lookup(principal)
+}
+
+// lookup returns the accounting of the caller creating it if needed
+func (cs *callers) lookup(name string) *caller {
+	if found, ok := cs.callers[name]; ok {
+		return found
+	}
+
+	quota, ok := cs.cfg.Quotas[name]
+	if !ok {
+		quota = cs.cfg.Default
+	}
+	if quota.Burst <= 0 {
+		quota.Burst = int(quota.Rate)
+		if float64(quota.Burst) < quota.Rate {
+			quota.Burst++
+		}
+	}
+	created := &caller{quota: quota, stats: CallerStats{Caller: name}, tokens: float64(quota.Burst),
+		elements: group{links: callerLinks}}
+	created.filled = time.Now()
+	cs.callers[name] = created
+
+	return created
+}
+
+// addByCaller adds the element within the quota of the caller. The element goes through the hooks and the middleware
+// like the one of AddE, and only the insert itself is replaced by addAsCaller
+func (c *cache) addByCaller(ctx context.Context, cl *caller, key string, value interface{}) error {
+	exec := func(op Op, key string, value interface{}) (interface{}, error) {
+		if op != OpAdd {
+			return c.exec(op, key, value)
+		}
+		return c.execAsCaller(cl, key, value)
+	}
+
+	key = c.scoped(ctx, key)
+	if c.pipeline == nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := exec(OpAdd, key, value)
+		return err
+	}
+	_, err := c.compose(exec)(ctx, OpAdd, key, value)
+
+	return err
+}
+
+// execAsCaller works like exec for the insert of the caller, the value is transformed outside the lock
+func (c *cache) execAsCaller(cl *caller, key string, value interface{}) (interface{}, error) {
+	attrs := c.attributes(value)
+	if c.transforms() {
+		var err error
+		if value, err = c.store(value); err != nil {
+			return nil, err
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, ErrCacheClosed
+	}
+	c.stage(key, attrs)
+	if err := c.addAsCaller(cl, key, value); err != nil {
+		return nil, err
+	}
+	c.countCompressed(value)
+
+	return c.version, nil
+}
+
+// addAsCaller checks the quota of the caller, adds the element and attributes it to the caller under a single lock.
+// The token of the insert is taken and the least recently used elements of the caller are evicted only when the
+// insert succeeds
+func (c *cache) addAsCaller(cl *caller, key string, value interface{}) error {
+	now := time.Now()
+	if element, ok := c.items[key]; ok && !c.expired(element, now) {
+		return ErrKeyExists
+	}
+
+	if cl.quota.Rate > 0 {
+		cl.tokens += now.Sub(cl.filled).Seconds() * cl.quota.Rate
+		if burst := float64(cl.quota.Burst); cl.tokens > burst {
+			cl.tokens = burst
+		}
+		cl.filled = now
+		if cl.tokens < 1 {
+			cl.stats.Throttled++
+			return ErrThrottled
+		}
+	}
+	if cl.quota.MaxEntries != 0 && cl.stats.Entries >= cl.quota.MaxEntries && c.groupTail(&cl.elements) == nil {
+		return ErrRejected
+	}
+
+	if err := c.add(key, value); err != nil {
+		return err
+	}
+	if cl.quota.Rate > 0 {
+		cl.tokens--
+	}
+	element := c.items[key]
+	element.caller = cl
+	c.attribute(element)
+	cl.stats.Adds++
+
+	for cl.quota.MaxEntries != 0 && cl.stats.Entries > cl.quota.MaxEntries {
+		victim := c.groupTail(&cl.elements)
+		if victim == nil || victim == element {
+			break
+		}
+		cl.stats.Evictions++
+		c.evict(victim, EvictedByCapacity)
+	}
+
+	return nil
+}
+
+// attribute makes the linked element the resident element of its caller
+func (c *cache) attribute(element *item) {
+	if element.caller != nil {
+		element.caller.stats.Entries++
+		element.caller.elements.pushFront(element)
+	}
+}
+
+// unattribute removes the element leaving the cache from the resident elements of its caller
+func (c *cache) unattribute(element *item) {
+	if element.caller != nil {
+		element.caller.stats.Entries--
+		element.caller.elements.remove(element)
+	}
+}
+
+// resetCallers sets the resident elements of all the callers to zero after the cache is cleared
+func (c *cache) resetCallers() {
+	if c.callers == nil {
+		return
+	}
+
+	for _, cl := range c.callers.callers {
+		cl.stats.Entries = 0
+		cl.elements.front, cl.elements.back = nil, nil
+	}
+}
+
+// resetCallerStats sets the counters of all the callers to zero keeping their resident elements
+func (c *cache) resetCallerStats() {
+	if c.callers == nil {
+		return
+	}
+
+	for _, cl := range c.callers.callers {
+		cl.stats = CallerStats{Caller: cl.stats.Caller, Entries: cl.stats.Entries}
+	}
+}
@@ -0,0 +1,120 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerQuotaEntries(t *testing.T) {
+	c, err := NewCache(10, WithCallerQuotas(CallerQuotas{
+		Quotas:  map[string]CallerQuota{"trusted": {}},
+		Default: CallerQuota{MaxEntries: 2},
+	}))
+	require.NoError(t, err)
+
+	plugin := WithPrincipal(context.Background(), "plugin")
+	trusted := WithPrincipal(context.Background(), "trusted")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.AddContext(plugin, "plugin:"+strconv.Itoa(i), i))
+		require.NoError(t, c.AddContext(trusted, "trusted:"+strconv.Itoa(i), i))
+	}
+	require.NoError(t, c.AddContext(context.Background(), "anonymous", 1))
+	require.ErrorIs(t, c.AddContext(plugin, "plugin:4", 4), ErrKeyExists)
+
+	require.Equal(t, 8, c.Len())
+	require.Equal(t, []CallerStats{
+		{Caller: "plugin", Entries: 2, Adds: 5, Evictions: 3},
+		{Caller: "trusted", Entries: 5, Adds: 5},
+	}, c.CallerStats())
+
+	_, err = c.AcquireLease("plugin:3", time.Minute)
+	require.NoError(t, err)
+	_, err = c.AcquireLease("plugin:4", time.Minute)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.AddContext(plugin, "plugin:5", 5), ErrRejected)
+
+	clone := c.Clone(nil)
+	require.Equal(t, 2, clone.CallerStats()[0].Entries)
+	require.True(t, c.Remove("trusted:0"))
+	require.Equal(t, 4, c.CallerStats()[1].Entries)
+
+	c.ResetStats()
+	require.Equal(t, CallerStats{Caller: "plugin", Entries: 2}, c.CallerStats()[0])
+	c.Clear()
+	require.Equal(t, CallerStats{Caller: "plugin"}, c.CallerStats()[0])
+}
+
+func TestCallerQuotaRate(t *testing.T) {
+	c, err := NewCache(0, WithCallerQuotas(CallerQuotas{Default: CallerQuota{Rate: 50, Burst: 2}}))
+	require.NoError(t, err)
+
+	ctx := WithPrincipal(context.Background(), "plugin")
+	require.NoError(t, c.AddContext(ctx, "a", 1))
+	require.NoError(t, c.AddContext(ctx, "b", 2))
+	require.ErrorIs(t, c.AddContext(ctx, "c", 3), ErrThrottled)
+	require.ErrorIs(t, c.AddContext(ctx, "a", 1), ErrKeyExists)
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, c.AddContext(ctx, "c", 3))
+	require.Equal(t, uint64(1), c.CallerStats()[0].Throttled)
+
+	plain, err := NewCache(0)
+	require.NoError(t, err)
+	require.NoError(t, plain.AddContext(ctx, "a", 1))
+	require.Nil(t, plain.CallerStats())
+}
+
+func TestCallerQuotaFailedAdd(t *testing.T) {
+	c, err := NewCache(0, WithTombstones(time.Minute),
+		WithKeyValidator(func(key string) error {
+			if key == "invalid" {
+				return ErrRejected
+			}
+			return nil
+		}),
+		WithCallerQuotas(CallerQuotas{Default: CallerQuota{MaxEntries: 1, Rate: 0.001, Burst: 2}}))
+	require.NoError(t, err)
+
+	ctx := WithPrincipal(context.Background(), "plugin")
+	require.NoError(t, c.AddContext(ctx, "first", 1))
+	c.Add("removed", 0)
+	require.True(t, c.Remove("removed"))
+
+	require.ErrorIs(t, c.AddContext(ctx, "removed", 2), ErrTombstoned)
+	require.Error(t, c.AddContext(ctx, "invalid", 3))
+	require.ErrorIs(t, c.AddContext(ctx, "first", 4), ErrKeyExists)
+	_, ok := c.Get("first")
+	require.True(t, ok)
+	require.Equal(t, CallerStats{Caller: "plugin", Entries: 1, Adds: 1}, c.CallerStats()[0])
+
+	require.NoError(t, c.AddContext(ctx, "second", 5))
+	_, ok = c.Get("first")
+	require.False(t, ok)
+	require.Equal(t, CallerStats{Caller: "plugin", Entries: 1, Adds: 2, Evictions: 1}, c.CallerStats()[0])
+}
+
+func TestCallerQuotaConcurrent(t *testing.T) {
+	c, err := NewCache(0, WithCallerQuotas(CallerQuotas{Default: CallerQuota{MaxEntries: 5}}))
+	require.NoError(t, err)
+
+	ctx := WithPrincipal(context.Background(), "plugin")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = c.AddContext(ctx, strconv.Itoa(g)+":"+strconv.Itoa(i), i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	require.Equal(t, 5, c.Len())
+	require.Equal(t, 5, c.CallerStats()[0].Entries)
+}
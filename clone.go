@@ -0,0 +1,299 @@
+package golru
+
+import (
+	"context"
+	"time"
+)
+
+// ValueCopier returns a copy of the value, it is used when the cache has to hand out or keep a value independent
+// from the original one
+type ValueCopier func(value interface{}) interface{}
+
+// Clone returns an independent cache with the same configuration and the same live elements in the same order,
+// keeping their creation time, so they expire at the same moment. The values are copied by the copier, or shared with
+// the original cache if it is nil. The statistics of the new cache start from zero, and the background goroutines
+// like Expire or Trim have to be started for it separately
+func (c *cache) Clone(copier ValueCopier) Cacher {
+	return c.copyInto(copier, nil)
+}
+
+// FilterInto returns a new cache with the same configuration and only the live elements matching the predicate. As
+// in Clone, the elements keep their relative order and creation time, and the values are shared with this cache. The
+// predicate is called under the lock, so it must not use the methods of this cache
+func (c *cache) FilterInto(pred func(Entry) bool) Cacher {
+	return c.copyInto(nil, pred)
+}
+
+// copyInto builds a new cache from the live elements accepted by the predicate, all of them if it is nil
+func (c *cache) copyInto(copier ValueCopier, pred func(Entry) bool) *cache {
+	c.lock()
+	defer c.unlock()
+
+	dst := c.empty()
+	dst.version = c.version
+	now := time.Now()
+	for current := c.chain.Back(); current != nil; current = c.chain.Prev(current) {
+		if c.expired(current, now) {
+			continue
+		}
+		if pred != nil && !pred(c.entry(current)) {
+			continue
+		}
+		it := dst.copyItem(current, copier)
+		dst.link(it)
+		dst.depend(it)
+	}
+
+	return dst
+}
+
+// empty returns a new cache with the same options and the current capacity of this one
+func (c *cache) empty() *cache {
+	clone, _ := NewCache(c.capacity, c.opts...)
+	empty := clone.(*cache)
+	empty.capacity = c.capacity
+
+	return empty
+}
+
+// copyItem returns a new item of this cache with the key, value and statistics of the given one
+func (c *cache) copyItem(src *item, copier ValueCopier) *item {
+	value := src.value
+	if copier != nil {
+		value = copier(value)
+	}
+
+	it := c.newItem(src.key, value)
+	it.keyHandle = src.keyHandle
+	it.creationTime = src.creationTime
+	it.bornTime = src.bornTime
+	it.deadline = src.deadline
+	it.lastAccess = src.lastAccess
+	it.hits = src.hits
+	it.version = src.version
+	it.meta = src.meta
+	it.parents = src.parents
+	if c.tenancy != nil && src.tenant != nil {
+		it.tenant = c.tenancy.lookup(src.tenant.stats.Tenant)
+	}
+	if c.callers != nil && src.caller != nil {
+		it.caller = c.callers.lookup(src.caller.stats.Caller)
+	}
+
+	return it
+}
+
+// link puts the prepared item to the top of the list without any checks
+func (c *cache) link(it *item) {
+	c.items[it.key] = c.chain.PushFront(it)
+	c.scan.insert(it, len(c.items))
+	it.freq, it.epoch = 0, c.epoch
+	c.enqueue(it)
+	c.prioritize(it)
+	c.account(it, 1)
+	c.countPrefixes(it, 1)
+	c.attribute(it)
+	c.reindex(it)
+	c.plant(it)
+	c.rebucket(it)
+}
+
+// ConflictFunc decides which value stays in the cache when the merged key already exists. Replace tells whether the
+// returned value replaces the existing one
+type ConflictFunc func(existing, incoming Entry) (value interface{}, replace bool)
+
+// KeepExisting is the conflict policy which leaves the existing value
+func KeepExisting(existing, incoming Entry) (interface{}, bool) {
+	return existing.Value, false
+}
+
+// KeepIncoming is the conflict policy which replaces the existing value by the merged one
+func KeepIncoming(existing, incoming Entry) (interface{}, bool) {
+	return incoming.Value, true
+}
+
+// KeepNewest is the conflict policy which leaves the value created later
+func KeepNewest(existing, incoming Entry) (interface{}, bool) {
+	if incoming.Created.After(existing.Created) {
+		return incoming.Value, true
+	}
+
+	return existing.Value, false
+}
+
+// Merge imports the live elements of the other cache keeping their creation time. Conflicts are resolved by the
+// given function, KeepExisting by default. The elements are added as usual, from the least recently used one of the
+// other cache, so the capacity is respected and the evicted elements are passed to the eviction callback. The keys
+// are normalized and validated, and the values are copied, encoded and compressed as by AddE, the elements with the
+// invalid keys or the values failing to encode are skipped. The conflicts are resolved and the values are stored
+// outside the lock, and the elements changed in the meantime keep their values. The new elements go through the
+// hooks and the middleware as OpAdd and the replaced ones as OpChangeValue, without them all the elements are merged
+// under a single lock. Returns the number of the added and replaced elements
+func (c *cache) Merge(other Cacher, resolve ConflictFunc) int {
+	if resolve == nil {
+		resolve = KeepExisting
+	}
+
+	entries := other.Entries()
+	merges := make([]merge, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		key, err := c.prepare(entries[i].Key)
+		if err != nil {
+			continue
+		}
+		entries[i].Key = key
+		merges = append(merges, merge{incoming: entries[i]})
+	}
+
+	c.lock()
+	now := time.Now()
+	for i := range merges {
+		if element, ok := c.validate(merges[i].incoming.Key); ok && !c.expired(element, now) {
+			merges[i].existing, merges[i].conflict = c.snapshot(element), true
+		}
+	}
+	c.unlock()
+
+	resolved := merges[:0]
+	for _, m := range merges {
+		m.value = m.incoming.Value
+		if m.conflict {
+			var replace bool
+			if m.value, replace = resolve(c.reveal([]Entry{m.existing})[0], m.incoming); !replace {
+				continue
+			}
+		}
+		resolved = append(resolved, m)
+	}
+
+	if c.pipeline != nil {
+		return c.mergeThroughPipeline(resolved)
+	}
+
+	for i := range resolved {
+		m := &resolved[i]
+		m.attrs = c.attributes(m.value)
+		if c.transforms() {
+			var err error
+			if m.value, err = c.store(m.value); err != nil {
+				m.failed = true
+			}
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	merged := 0
+	for i := range resolved {
+		m := &resolved[i]
+		if m.failed || c.closed {
+			continue
+		}
+		c.stage(m.incoming.Key, m.attrs)
+		if c.merge(m.incoming.Key, m, m.value) == nil {
+			merged++
+		}
+	}
+
+	return merged
+}
+
+// merge is the element of the other cache imported by Merge. Existing is the snapshot of the element of this cache
+// with the same key if there is a conflict, and value is the resolved value, which is replaced by its stored form
+// before the element is merged under the lock
+type merge struct {
+	incoming Entry
+	existing Entry
+	conflict bool
+	value    interface{}
+	attrs    map[string]string
+	failed   bool
+}
+
+// mergeThroughPipeline merges the resolved elements one by one through the hooks and the middleware
+func (c *cache) mergeThroughPipeline(resolved []merge) int {
+	var current *merge
+	run := c.compose(func(op Op, key string, value interface{}) (interface{}, error) {
+		if op != OpAdd && op != OpChangeValue {
+			return c.exec(op, key, value)
+		}
+
+		attrs := c.attributes(value)
+		if c.transforms() {
+			var err error
+			if value, err = c.store(value); err != nil {
+				return nil, err
+			}
+		}
+
+		c.lock()
+		defer c.unlock()
+
+		if c.closed {
+			return nil, ErrCacheClosed
+		}
+		c.stage(key, attrs)
+		if err := c.merge(key, current, value); err != nil {
+			return nil, err
+		}
+
+		return c.version, nil
+	})
+
+	merged := 0
+	for i := range resolved {
+		current = &resolved[i]
+		op := OpAdd
+		if current.conflict {
+			op = OpChangeValue
+		}
+		if _, err := run(context.Background(), op, current.incoming.Key, current.value); err == nil {
+			merged++
+		}
+	}
+
+	return merged
+}
+
+// merge adds the element imported by Merge with the key or replaces the value of the conflicting one by the given stored value.
+// Returns ErrKeyExists if the key was added or changed since the conflicts were found, and ErrKeyNotFound if the
+// conflicting element has left the cache
+func (c *cache) merge(key string, m *merge, stored interface{}) error {
+	now := time.Now()
+	element, ok := c.validate(key)
+	live := ok && !c.expired(element, now)
+
+	if !m.conflict {
+		if live {
+			return ErrKeyExists
+		}
+		if err := c.add(key, stored); err != nil {
+			return err
+		}
+		element = c.items[key]
+		element.creationTime = m.incoming.Created
+		element.bornTime = m.incoming.Created
+		c.rebucket(element)
+		c.countCompressed(stored)
+		return nil
+	}
+
+	switch {
+	case !live:
+		return ErrKeyNotFound
+	case element.version != m.existing.Version:
+		return ErrKeyExists
+	case c.pinned(element, now):
+		return ErrLeased
+	}
+	c.replaceValue(element, stored)
+	c.countCompressed(stored)
+	if m.incoming.Created.After(element.creationTime) {
+		element.creationTime = m.incoming.Created
+		c.rebucket(element)
+	}
+	c.touch(element)
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package golru
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	c, err := NewCache(3, WithTTL(60), WithHitWindow(time.Second, 10))
+	require.NoError(t, err)
+
+	c.Add("first", []int{1})
+	c.Add("second", []int{2})
+	c.Add("third", []int{3})
+	c.Get("first")
+	c.ChangeCapacity(4)
+
+	clone := c.Clone(nil)
+	require.Equal(t, c.Config(), clone.Config())
+	require.Equal(t, c.Entries(), clone.Entries())
+	require.Zero(t, clone.Stats().Hits)
+
+	clone.Add("fourth", []int{4})
+	clone.Remove("first")
+	require.Equal(t, 3, c.Len())
+	_, ok := c.Get("fourth")
+	require.False(t, ok)
+
+	value, _ := clone.Get("second")
+	value.([]int)[0] = 20
+	value, _ = c.Get("second")
+	require.Equal(t, []int{20}, value)
+}
+
+func TestCloneCopier(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	c.Add("test", []int{1, 2})
+
+	clone := c.Clone(func(value interface{}) interface{} {
+		return append([]int(nil), value.([]int)...)
+	})
+
+	value, _ := clone.Get("test")
+	value.([]int)[0] = 10
+	value, _ = c.Get("test")
+	require.Equal(t, []int{1, 2}, value)
+}
+
+func TestFilterInto(t *testing.T) {
+	c, err := NewCache(4, WithTTL(60))
+	require.NoError(t, err)
+
+	c.Add("acme:1", 1)
+	c.Add("other:1", 2)
+	c.Add("acme:2", 3)
+	c.Add("other:2", 4)
+
+	acme := c.FilterInto(func(entry Entry) bool {
+		return strings.HasPrefix(entry.Key, "acme:")
+	})
+
+	entries := acme.Entries()
+	require.Len(t, entries, 2)
+	require.Equal(t, "acme:2", entries[0].Key)
+	require.Equal(t, "acme:1", entries[1].Key)
+	original, _ := c.GetEntry("acme:1")
+	require.Equal(t, original.Expiration, entries[1].Expiration)
+	require.Equal(t, 4, c.Len())
+}
+
+func TestMerge(t *testing.T) {
+	var evicted []string
+	c, err := NewCache(3, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted = append(evicted, key)
+	}))
+	require.NoError(t, err)
+	other, err := NewCache(3)
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("shared", 2)
+	other.Add("shared", 20)
+	other.Add("second", 30)
+	other.Add("third", 40)
+
+	require.Equal(t, 2, c.Merge(other, nil))
+	require.Equal(t, []string{"first"}, evicted)
+
+	value, _ := c.Get("shared")
+	require.Equal(t, 2, value)
+	value, _ = c.Get("third")
+	require.Equal(t, 40, value)
+
+	require.Equal(t, 3, c.Merge(other, KeepIncoming))
+	value, _ = c.Get("shared")
+	require.Equal(t, 20, value)
+}
+
+func TestMergeNewest(t *testing.T) {
+	c, err := NewCache(3)
+	require.NoError(t, err)
+	other, err := NewCache(3)
+	require.NoError(t, err)
+
+	other.Add("old", 1)
+	time.Sleep(time.Millisecond)
+	c.Add("old", 2)
+	c.Add("new", 3)
+	time.Sleep(time.Millisecond)
+	other.Add("new", 4)
+
+	require.Equal(t, 1, c.Merge(other, KeepNewest))
+	value, _ := c.Get("old")
+	require.Equal(t, 2, value)
+	value, _ = c.Get("new")
+	require.Equal(t, 4, value)
+
+	sum := func(existing, incoming Entry) (interface{}, bool) {
+		return existing.Value.(int) + incoming.Value.(int), true
+	}
+	require.Equal(t, 2, c.Merge(other, sum))
+	value, _ = c.Get("old")
+	require.Equal(t, 3, value)
+}
+
+func TestMergeTransforms(t *testing.T) {
+	c, err := NewCache(3, WithCodec(GobCodec()), WithKeyNormalizer(strings.ToLower))
+	require.NoError(t, err)
+	other, err := NewCache(3)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddE("shared", codecValue{Name: "existing"}))
+	other.Add("Shared", codecValue{Name: "incoming"})
+	other.Add("New", codecValue{Name: "new", Items: []int{1}})
+	other.Add("func", func() {})
+
+	require.Equal(t, 2, c.Merge(other, KeepIncoming))
+	require.Equal(t, 2, c.Len())
+
+	value, err := c.GetE("shared")
+	require.NoError(t, err)
+	require.Equal(t, codecValue{Name: "incoming"}, value)
+	value, err = c.GetE("NEW")
+	require.NoError(t, err)
+	require.Equal(t, codecValue{Name: "new", Items: []int{1}}, value)
+}
+
+func TestMergeThroughPipeline(t *testing.T) {
+	var c Cacher
+	var records []AuditRecord
+	var ops []Op
+	c, err := NewCache(3, WithAudit(func(rec AuditRecord) {
+		records = append(records, rec)
+	}), WithHooks(func(op Op, key string) {
+		ops = append(ops, op)
+	}, nil))
+	require.NoError(t, err)
+	other, err := NewCache(3)
+	require.NoError(t, err)
+
+	c.Add("shared", 1)
+	c.Add("changed", 2)
+	other.Add("shared", 10)
+	other.Add("changed", 20)
+	other.Add("new", 30)
+	ops = nil
+
+	merged := c.Merge(other, func(existing, incoming Entry) (interface{}, bool) {
+		if existing.Key == "changed" {
+			require.True(t, c.ChangeValue("changed", 200))
+		}
+		return incoming.Value, true
+	})
+	require.Equal(t, 2, merged)
+	require.Equal(t, []Op{OpChangeValue, OpChangeValue, OpChangeValue, OpAdd}, ops)
+
+	value, _ := c.Get("shared")
+	require.Equal(t, 10, value)
+	value, _ = c.Get("changed")
+	require.Equal(t, 200, value)
+	value, _ = c.Get("new")
+	require.Equal(t, 30, value)
+
+	keys := make([]string, 0, len(records))
+	for _, rec := range records {
+		require.Equal(t, OpChangeValue, rec.Op)
+		keys = append(keys, rec.Key)
+	}
+	require.Equal(t, []string{"changed", "shared"}, keys)
+}
@@ -0,0 +1,50 @@
+package golru
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Codec serializes the values of the cache created with WithCodec
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// WithCodec makes the cache store the values serialized by the codec. The value is encoded on Add and ChangeValue
+// and decoded on every Get, outside the cache lock, so the callers never share the stored value, and the size of
+// every value is known exactly for EstimatedBytes. The encoding errors are returned by AddE as is, so the failed
+// value isn't added. The codec is applied before the compression, if it is set too
+func WithCodec(codec Codec) CacheOption {
+	return func(cache *cache) {
+		cache.codec = codec
+	}
+}
+
+// GobCodec returns the codec using encoding/gob. The concrete types of the values other than the basic ones have to
+// be registered by gob.Register, as the values are encoded as interfaces
+func GobCodec() Codec {
+	return gobCodec{}
+}
+
+type gobCodec struct{}
+
+// Marshal encodes the value together with the name of its type
+func (gobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the value of the type written by Marshal
+func (gobCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
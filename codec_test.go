@@ -0,0 +1,98 @@
+package golru
+
+import (
+	"compress/flate"
+	"encoding/gob"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type codecValue struct {
+	Name  string
+	Items []int
+}
+
+func init() {
+	gob.Register(codecValue{})
+}
+
+func TestCodec(t *testing.T) {
+	c, err := NewCache(2, WithCodec(GobCodec()))
+	require.NoError(t, err)
+
+	original := codecValue{Name: "test", Items: []int{1, 2}}
+	require.NoError(t, c.AddE("test", original))
+	original.Items[0] = 10
+
+	value, err := c.GetE("test")
+	require.NoError(t, err)
+	require.Equal(t, codecValue{Name: "test", Items: []int{1, 2}}, value)
+
+	value.(codecValue).Items[1] = 20
+	value, _ = c.GetBytes([]byte("test"))
+	require.Equal(t, []int{1, 2}, value.(codecValue).Items)
+	require.Equal(t, []interface{}{value}, c.Values())
+
+	require.Error(t, c.AddE("func", func() {}))
+	require.Equal(t, 1, c.Len())
+}
+
+func TestCodecWithCompression(t *testing.T) {
+	c, err := NewCache(2, WithCodec(GobCodec()), WithCompression(FlateCompressor(flate.BestSpeed), 64))
+	require.NoError(t, err)
+
+	text := strings.Repeat("compressible ", 100)
+	c.Add("test", text)
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, text, value)
+	require.Equal(t, uint64(1), c.Stats().Compressed)
+}
+
+// lockCheckCodec is the gob codec recording whether any value was decoded under the lock of the cache
+type lockCheckCodec struct {
+	gobCodec
+	c         *cache
+	underLock bool
+}
+
+func (l *lockCheckCodec) Unmarshal(data []byte) (interface{}, error) {
+	if l.c.mu.TryLock() {
+		l.c.mu.Unlock()
+	} else {
+		l.underLock = true
+	}
+	return l.gobCodec.Unmarshal(data)
+}
+
+func TestCodecOutsideLock(t *testing.T) {
+	codec := &lockCheckCodec{}
+	c, err := NewCache(2, WithCodec(codec))
+	require.NoError(t, err)
+	codec.c = c.(*cache)
+
+	c.Add("first", "one")
+	c.Add("second", "two")
+
+	entry, ok := c.GetEntry("first")
+	require.True(t, ok)
+	require.Equal(t, "one", entry.Value)
+	require.Equal(t, []interface{}{"two", "one"}, []interface{}{c.Entries()[0].Value, c.Entries()[1].Value})
+	require.ElementsMatch(t, []interface{}{"one", "two"}, c.Values())
+	require.Len(t, c.SampleEntries(2), 2)
+	require.NoError(t, c.Dump(&strings.Builder{}))
+
+	key, value, evicted := c.AddReturningEvicted("third", "three")
+	require.True(t, evicted)
+	require.Equal(t, "first", key)
+	require.Equal(t, "one", value)
+
+	require.Equal(t, 1, c.Purge(func(entry Entry) bool {
+		return entry.Value == "two" && c.Len() == 2
+	}))
+	require.Equal(t, []string{"third"}, c.Keys())
+	require.False(t, codec.underLock)
+}
@@ -0,0 +1,135 @@
+package golru
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// Compressor compresses the values stored by the cache created with WithCompression
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// compressed is the stored form of the compressed string or byte slice. Size is the length of the original value
+type compressed struct {
+	data []byte
+	size int
+	text bool
+}
+
+// WithCompression makes the cache compress the strings and byte slices of at least minSize bytes when they are added
+// and decompress them when they are returned, so the callers see the original values. The value is compressed
+// outside the cache lock, and it is stored as is if the compression fails or doesn't make it smaller.
+// Stats.CompressionRatio shows how much memory the compression saves
+func WithCompression(compressor Compressor, minSize int) CacheOption {
+	return func(cache *cache) {
+		cache.compressor = compressor
+		cache.minCompressed = minSize
+	}
+}
+
+// FlateCompressor returns the compressor using DEFLATE with the given level from compress/flate
+func FlateCompressor(level int) Compressor {
+	return flateCompressor(level)
+}
+
+type flateCompressor int
+
+// Compress returns the DEFLATE stream of the data
+func (f flateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, int(f))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the data of the DEFLATE stream
+func (f flateCompressor) Decompress(data []byte) ([]byte, error) {
+	return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+}
+
+// compress returns the compressed form of the value if it is a large enough string or byte slice
+func (c *cache) compress(value interface{}) interface{} {
+	var (
+		data []byte
+		text bool
+	)
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data, text = []byte(v), true
+	default:
+		return value
+	}
+
+	if len(data) < c.minCompressed {
+		return value
+	}
+
+	packed, err := c.compressor.Compress(data)
+	if err != nil || len(packed) >= len(data) {
+		return value
+	}
+
+	return &compressed{data: packed, size: len(data), text: text}
+}
+
+// decompress returns the original value of the compressed one, other values are returned as is
+func (c *cache) decompress(value interface{}) (interface{}, error) {
+	v, ok := value.(*compressed)
+	if !ok {
+		return value, nil
+	}
+
+	data, err := c.compressor.Decompress(v.data)
+	if err != nil {
+		return nil, fmt.Errorf("golru: decompress value: %w", err)
+	}
+	if v.text {
+		return string(data), nil
+	}
+
+	return data, nil
+}
+
+// plain returns the original value for the snapshots and the eviction callback, which can't report an error
+func (c *cache) plain(value interface{}) interface{} {
+	if !c.transforms() {
+		return value
+	}
+	value, _ = c.decode(value)
+
+	return value
+}
+
+// countCompressed accounts the stored value in the compression stats
+func (c *cache) countCompressed(value interface{}) {
+	if v, ok := value.(*compressed); ok {
+		c.stats.Compressed++
+		c.stats.RawBytes += uint64(v.size)
+		c.stats.CompressedBytes += uint64(len(v.data))
+	}
+}
+
+// CompressionRatio returns how many times the compressed values are smaller than the original ones, or zero if
+// nothing was compressed
+func (s Stats) CompressionRatio() float64 {
+	if s.CompressedBytes == 0 {
+		return 0
+	}
+
+	return float64(s.RawBytes) / float64(s.CompressedBytes)
+}
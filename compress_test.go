@@ -0,0 +1,66 @@
+package golru
+
+import (
+	"compress/flate"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingCompressor struct{}
+
+func (failingCompressor) Compress(data []byte) ([]byte, error) {
+	return nil, errors.New("compress failed")
+}
+
+func (failingCompressor) Decompress(data []byte) ([]byte, error) {
+	return nil, errors.New("decompress failed")
+}
+
+func TestCompression(t *testing.T) {
+	var evicted interface{}
+	c, err := NewCache(2, WithCompression(FlateCompressor(flate.BestSpeed), 64),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			evicted = value
+		}))
+	require.NoError(t, err)
+
+	html := strings.Repeat("<div>fragment</div>", 100)
+	c.Add("html", html)
+	c.Add("bytes", []byte(html))
+
+	value, ok := c.Get("html")
+	require.True(t, ok)
+	require.Equal(t, html, value)
+	value, ok = c.GetBytes([]byte("bytes"))
+	require.True(t, ok)
+	require.Equal(t, []byte(html), value)
+
+	entry, _ := c.GetEntry("html")
+	require.Equal(t, html, entry.Value)
+	require.Less(t, c.EstimatedBytes(), uint64(len(html)))
+
+	stats := c.Stats()
+	require.Equal(t, uint64(2), stats.Compressed)
+	require.Equal(t, uint64(2*len(html)), stats.RawBytes)
+	require.Greater(t, stats.CompressionRatio(), 10.0)
+
+	c.Add("small", "short")
+	require.Equal(t, html, evicted)
+	require.Equal(t, uint64(2), c.Stats().Compressed)
+	value, _ = c.Get("small")
+	require.Equal(t, "short", value)
+}
+
+func TestCompressionFailure(t *testing.T) {
+	c, err := NewCache(2, WithCompression(failingCompressor{}, 1))
+	require.NoError(t, err)
+
+	c.Add("test", "value")
+	value, err := c.GetE("test")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+	require.Zero(t, c.Stats().CompressionRatio())
+}
@@ -0,0 +1,56 @@
+package golru
+
+import "time"
+
+// Config is a snapshot of the cache configuration. Zero Capacity means the unbounded cache, zero TTL means that the
+// elements never expire. HitWindow is the whole period covered by the hit ratio window. EvictWorkers is zero if the
+// eviction callbacks are called synchronously
+type Config struct {
+	Capacity     uint32
+	SoftCapacity uint32
+	TTL          time.Duration
+	Shards       int
+
+	ItemPool     bool
+	KeyInterning bool
+	LockMetrics  bool
+	HitWindow    time.Duration
+
+	EvictWorkers int
+	EvictQueue   int
+	Overflow     OverflowPolicy
+}
+
+// Cap returns the current capacity of the cache, zero if it is unbounded
+func (c *cache) Cap() int {
+	c.lock()
+	defer c.unlock()
+
+	return int(c.capacity)
+}
+
+// Config returns the snapshot of the current configuration of the cache
+func (c *cache) Config() Config {
+	c.lock()
+	defer c.unlock()
+
+	cfg := Config{
+		Capacity:     c.capacity,
+		SoftCapacity: c.softCapacity,
+		TTL:          c.lifetime,
+		Shards:       1,
+		ItemPool:     c.pooled,
+		KeyInterning: c.interning,
+		LockMetrics:  c.lockMetrics,
+		Overflow:     c.overflow,
+	}
+	if c.window != nil {
+		cfg.HitWindow = c.window.width * time.Duration(len(c.window.buckets))
+	}
+	if c.dispatcher != nil {
+		cfg.EvictWorkers = int(c.dispatcher.workers)
+		cfg.EvictQueue = cap(c.dispatcher.queue)
+	}
+
+	return cfg
+}
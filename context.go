@@ -0,0 +1,139 @@
+package golru
+
+import (
+	"context"
+	"time"
+)
+
+// ctxOpFunc is the operation running in the pipeline together with its context
+type ctxOpFunc func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error)
+
+// ContextBeforeHook works like BeforeHook and gets the context of the operation, the background one for the methods
+// without the context
+type ContextBeforeHook func(ctx context.Context, op Op, key string)
+
+// ContextAfterHook works like AfterHook and gets the context of the operation, the background one for the methods
+// without the context
+type ContextAfterHook func(ctx context.Context, op Op, key string, hit bool, d time.Duration)
+
+// contextHooks keeps the hooks getting the context of the operations
+type contextHooks struct {
+	before ContextBeforeHook
+	after  ContextAfterHook
+}
+
+// WithContextHooks sets the hooks like WithHooks does, but they also get the context of the operation, so they can
+// take the request-scoped values like the trace spans out of it. They are called inside the hooks set by WithHooks.
+// Either of them can be nil
+func WithContextHooks(before ContextBeforeHook, after ContextAfterHook) CacheOption {
+	return func(cache *cache) {
+		if before == nil && after == nil {
+			cache.ctxHooks = nil
+			return
+		}
+		cache.ctxHooks = &contextHooks{before: before, after: after}
+	}
+}
+
+// forceTraceKey marks the context of the operations recorded regardless of the sampling
+type forceTraceKey struct{}
+
+// ForceTrace returns the context making the trace recorder record the operations with it regardless of the
+// sampling, for example to capture the whole flow of a single request
+func ForceTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceTraceKey{}, true)
+}
+
+// WithKeyScope scopes the keys of the methods with the context by the value the function takes out of it, for example
+// the user, the locale or the cohort of the feature flag, so the elements of the different scopes never mix. The key
+// is stored as Key(scope) followed by the separator and the key itself, so the scope is the first part of SplitKey and
+// the default tenant of WithTenancy. The empty scope leaves the key as it is, the same as the methods without the
+// context do. The concurrent loads of GetMulti are coalesced only within the same scope
+func WithKeyScope(scope func(ctx context.Context) string) CacheOption {
+	return func(cache *cache) {
+		cache.scope = scope
+	}
+}
+
+// scoped returns the key within the scope of the context
+func (c *cache) scoped(ctx context.Context, key string) string {
+	if c.scope == nil {
+		return key
+	}
+	scope := c.scope(ctx)
+	if scope == "" {
+		return key
+	}
+
+	return Key(scope) + string(KeySeparator) + key
+}
+
+// GetContext works like GetE and passes the context to the context hooks and the trace recorder, and scopes the key
+// with WithKeyScope. The operations of the cache never wait for anything but the cache lock, so the context is checked
+// before the operation is started, and its error is returned if it is already done
+func (c *cache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	return c.doContext(ctx, OpGet, key, nil)
+}
+
+// AddContext works like AddE and passes the context to the hooks, see GetContext. With WithCallerQuotas the element
+// is attributed to the principal of the context and limited by its quota
+func (c *cache) AddContext(ctx context.Context, key string, value interface{}) error {
+	if cl := c.callerOf(ctx); cl != nil {
+		return c.addByCaller(ctx, cl, key, value)
+	}
+	_, err := c.doContext(ctx, OpAdd, key, value)
+
+	return err
+}
+
+// RemoveContext works like RemoveE and passes the context to the hooks, see GetContext
+func (c *cache) RemoveContext(ctx context.Context, key string) error {
+	_, err := c.doContext(ctx, OpRemove, key, nil)
+
+	return err
+}
+
+// ChangeValueContext works like ChangeValue and returns the error, passing the context to the hooks and the audit,
+// see GetContext
+func (c *cache) ChangeValueContext(ctx context.Context, key string, value interface{}) error {
+	_, err := c.doContext(ctx, OpChangeValue, key, value)
+
+	return err
+}
+
+// doContext runs the operation through the pipeline with the context
+func (c *cache) doContext(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+	key = c.scoped(ctx, key)
+	if c.pipeline == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return c.exec(op, key, value)
+	}
+
+	return c.pipeline(ctx, op, key, value)
+}
+
+// wrapContextHooks surrounds the operation by the context hooks
+func (c *cache) wrapContextHooks(next ctxOpFunc) ctxOpFunc {
+	h := c.ctxHooks
+
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		if h.before != nil {
+			c.safely(callbackBeforeHook, key, func() {
+				h.before(ctx, op, key)
+			})
+		}
+
+		start := time.Now()
+		result, err := next(ctx, op, key, value)
+
+		if h.after != nil {
+			c.safely(callbackAfterHook, key, func() {
+				h.after(ctx, op, key, err == nil, time.Since(start))
+			})
+		}
+
+		return result, err
+	}
+}
@@ -0,0 +1,139 @@
+package golru
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type requestKey struct{}
+
+func TestContextOperations(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.AddContext(ctx, "test", 1))
+	value, err := c.GetContext(ctx, "test")
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+	require.NoError(t, c.RemoveContext(ctx, "test"))
+	_, err = c.GetContext(ctx, "test")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, c.AddContext(canceled, "test", 1), context.Canceled)
+	require.Equal(t, 0, c.Len())
+
+	s, err := NewShardedCache(2, 10)
+	require.NoError(t, err)
+	require.NoError(t, s.AddContext(ctx, "test", 1))
+	_, err = s.GetContext(canceled, "test")
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, s.RemoveContext(ctx, "test"))
+}
+
+func TestContextHooks(t *testing.T) {
+	var before, after []string
+	c, err := NewCache(10, WithContextHooks(
+		func(ctx context.Context, op Op, key string) {
+			id, _ := ctx.Value(requestKey{}).(string)
+			before = append(before, op.String()+":"+key+":"+id)
+		},
+		func(ctx context.Context, op Op, key string, hit bool, d time.Duration) {
+			id, _ := ctx.Value(requestKey{}).(string)
+			after = append(after, op.String()+":"+key+":"+id)
+		},
+	))
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), requestKey{}, "r1")
+	require.NoError(t, c.AddContext(ctx, "test", 1))
+	c.Get("test")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.GetContext(canceled, "test")
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Equal(t, []string{"add:test:r1", "get:test:", "get:test:r1"}, before)
+	require.Equal(t, before, after)
+}
+
+func TestForceTrace(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewTraceRecorder(&buf, TraceCSV, 0.0001)
+	c, err := NewCache(10, WithTraceRecorder(rec))
+	require.NoError(t, err)
+
+	c.Add("test", 1)
+	_, err = c.GetContext(ForceTrace(context.Background()), "test")
+	require.NoError(t, err)
+	require.NoError(t, rec.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], ",get,test,1")
+}
+
+func TestKeyScope(t *testing.T) {
+	scope := WithKeyScope(func(ctx context.Context) string {
+		user, _ := ctx.Value(requestKey{}).(string)
+		return user
+	})
+	c, err := NewCache(10, scope)
+	require.NoError(t, err)
+
+	alice := context.WithValue(context.Background(), requestKey{}, "alice")
+	bob := context.WithValue(context.Background(), requestKey{}, "b:ob")
+	require.NoError(t, c.AddContext(alice, "profile", 1))
+	require.NoError(t, c.AddContext(bob, "profile", 2))
+	require.NoError(t, c.AddContext(context.Background(), "profile", 3))
+
+	value, err := c.GetContext(alice, "profile")
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+	value, err = c.GetContext(bob, "profile")
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+	value, _ = c.Get("profile")
+	require.Equal(t, 3, value)
+	value, _ = c.Get("alice:profile")
+	require.Equal(t, 1, value)
+	require.Equal(t, []string{"b:ob", "profile"}, SplitKey(`b\:ob:profile`))
+	value, _ = c.Get(`b\:ob:profile`)
+	require.Equal(t, 2, value)
+
+	require.NoError(t, c.PinWithContext(alice, "profile"))
+	require.NoError(t, c.RemoveContext(bob, "profile"))
+	_, err = c.GetContext(bob, "profile")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	var loaded []string
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		loaded = append(loaded, keys...)
+		return map[string]interface{}{"settings": ctx.Value(requestKey{})}, nil
+	}
+	values, err := c.GetMulti(alice, []string{"profile", "settings"}, load)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"profile": 1, "settings": "alice"}, values)
+	require.Equal(t, []string{"settings"}, loaded)
+	value, _ = c.Get("alice:settings")
+	require.Equal(t, "alice", value)
+
+	s, err := NewShardedCache(4, 40, scope)
+	require.NoError(t, err)
+	require.NoError(t, s.AddContext(alice, "profile", 1))
+	values, err = s.GetMulti(alice, []string{"profile"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"profile": 1}, values)
+	_, err = s.GetContext(bob, "profile")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	value, _ = s.Get("alice:profile")
+	require.Equal(t, 1, value)
+}
@@ -0,0 +1,49 @@
+package golru
+
+// CopyMode tells when the value copier set by WithValueCopier is applied
+type CopyMode int
+
+const (
+	// CopyOnAdd copies the values passed to Add and ChangeValue, so the caller can keep changing its value
+	CopyOnAdd CopyMode = 1 << iota
+	// CopyOnGet copies the values returned by Get, so the callers can't change the stored value
+	CopyOnGet
+	// CopyAlways copies the values both on the way in and on the way out
+	CopyAlways = CopyOnAdd | CopyOnGet
+)
+
+// WithValueCopier makes the cache copy the values by the copier according to the mode. The copier is called outside
+// the cache lock. The snapshots returned by Entries, GetEntry and the like as well as the eviction callback still
+// get the stored value itself
+func WithValueCopier(copier ValueCopier, mode CopyMode) CacheOption {
+	return func(cache *cache) {
+		cache.copier = copier
+		cache.copyMode = mode
+	}
+}
+
+// WithImmutableValues makes the cache treat the values as immutable: they are never changed in place, ChangeValue
+// installs the new value, and the readers holding the old one keep seeing it as it was. The replaced value is passed
+// to the eviction callback with the EvictedByReplace reason, so the resources held by it can be released once it is
+// no longer in the cache
+func WithImmutableValues() CacheOption {
+	return func(cache *cache) {
+		cache.immutable = true
+	}
+}
+
+// replaceValue installs the new value of the element, handing the old one to the eviction callback if the values
+// are immutable, and closing it with WithAutoClose
+func (c *cache) replaceValue(element *item, value interface{}) {
+	old := element.value
+	element.value = value
+	c.recost(element)
+	c.reindex(element)
+	c.stamp(element)
+	c.cascade(element.key)
+
+	if c.immutable || c.autoClose {
+		c.queueEvent(evictEvent{key: element.key, value: old, meta: element.meta, reason: EvictedByReplace,
+			callback: c.immutable && c.evictCallbacks(), close: c.autoClose && !sameValue(old, value)})
+	}
+}
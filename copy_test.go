@@ -0,0 +1,75 @@
+package golru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func copyInts(value interface{}) interface{} {
+	return append([]int(nil), value.([]int)...)
+}
+
+func TestValueCopierOnAdd(t *testing.T) {
+	c, err := NewCache(2, WithValueCopier(copyInts, CopyOnAdd))
+	require.NoError(t, err)
+
+	value := []int{1, 2}
+	c.Add("test", value)
+	value[0] = 10
+
+	stored, _ := c.Get("test")
+	require.Equal(t, []int{1, 2}, stored)
+
+	stored.([]int)[1] = 20
+	stored, _ = c.GetBytes([]byte("test"))
+	require.Equal(t, []int{1, 20}, stored)
+
+	c.ChangeValue("test", value)
+	value[1] = 30
+	stored, _ = c.Get("test")
+	require.Equal(t, []int{10, 2}, stored)
+}
+
+func TestValueCopierOnGet(t *testing.T) {
+	c, err := NewCache(2, WithValueCopier(copyInts, CopyAlways))
+	require.NoError(t, err)
+
+	c.Add("test", []int{1, 2})
+	stored, _ := c.Get("test")
+	stored.([]int)[0] = 10
+	stored, _ = c.GetBytes([]byte("test"))
+	stored.([]int)[1] = 20
+
+	stored, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, []int{1, 2}, stored)
+
+	_, ok = c.Get("missing")
+	require.False(t, ok)
+}
+
+func TestImmutableValues(t *testing.T) {
+	type replaced struct {
+		value  interface{}
+		reason EvictReason
+	}
+	var events []replaced
+	c, err := NewCache(2, WithImmutableValues(), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		events = append(events, replaced{value, reason})
+	}))
+	require.NoError(t, err)
+
+	c.Add("test", []int{1})
+	old, _ := c.Get("test")
+
+	require.True(t, c.ChangeValue("test", []int{2}))
+	require.Equal(t, []int{1}, old)
+	require.Equal(t, []replaced{{[]int{1}, EvictedByReplace}}, events)
+	require.Equal(t, "replace", EvictedByReplace.String())
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, []int{2}, value)
+	require.Zero(t, c.Stats().Evictions)
+}
@@ -0,0 +1,209 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrCronSpec = errors.New("cron spec should have 5 fields: minute, hour, day of month, month and day of week")
+
+// cronSchedule is the parsed cron spec, every field is the bit set of the allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	anyDom, anyDow bool
+}
+
+// cronFields are the ranges of the values of the cron fields in their order
+var cronFields = [...]struct{ min, max int }{
+	{0, 59},
+	{0, 23},
+	{1, 31},
+	{1, 12},
+	{0, 6},
+}
+
+// SchedulePurge removes the live elements matching the predicate, like Purge does, every time the cron spec is due in
+// the local time. The spec has the usual 5 fields: minute, hour, day of month, month and day of week, each one being
+// *, a number, a range like 1-5, a list of them separated by commas, optionally with a step like */15. For example,
+// "0 3 * * *" runs every night at 03:00. Purging stops when the context is done
+func (c *cache) SchedulePurge(ctx context.Context, spec string, pred func(Entry) bool) error {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	c.background(ctx, "purge", func(ctx context.Context) {
+		for {
+			timer := time.NewTimer(time.Until(schedule.next(time.Now())))
+			select {
+			case <-timer.C:
+				removed := c.Purge(pred)
+				c.log(slog.LevelInfo, "golru: scheduled purge", "spec", spec, "removed", removed)
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// Purge removes all the live elements matching the predicate, except the leased ones, like Remove does, and returns
+// their number. The predicate is called outside the lock with the snapshots of the elements, so it may use the
+// methods of the cache. The elements changed, leased or expired after their snapshots were taken are kept
+func (c *cache) Purge(pred func(Entry) bool) int {
+	c.lock()
+	now := time.Now()
+	entries := make([]Entry, 0, c.chain.Len())
+	for current := c.chain.Front(); current != nil; current = c.chain.Next(current) {
+		if !c.expired(current, now) && !c.pinned(current, now) {
+			entries = append(entries, c.snapshot(current))
+		}
+	}
+	c.unlock()
+
+	matched := entries[:0]
+	for _, entry := range c.reveal(entries) {
+		if pred(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if len(matched) == 0 {
+		return 0
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	now = time.Now()
+	removed := 0
+	c.deferCascades(func() {
+		for _, entry := range matched {
+			current, ok := c.items[entry.Key]
+			if !ok || current.version != entry.Version || c.expired(current, now) || c.pinned(current, now) {
+				continue
+			}
+			c.removeElement(current)
+			c.queueRemoval(current.key, current.value)
+			c.queueAudit(current.key)
+			c.entomb(current.key)
+			c.release(current)
+			removed++
+		}
+	})
+
+	return removed
+}
+
+// parseCron parses the 5 fields of the cron spec
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, ErrCronSpec
+	}
+
+	var sets [len(cronFields)]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	return &cronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the bit set of the values allowed by the field
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, ErrCronSpec
+			}
+			step, part = n, part[:i]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, ErrCronSpec
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, ErrCronSpec
+				}
+			}
+		}
+		if from < min || to > max || from > to {
+			return 0, ErrCronSpec
+		}
+
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+// next returns the first moment after t when the schedule is due
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return limit
+}
+
+// dayMatches checks the day of month and the day of week. If both of them are restricted, either one is enough, as
+// in the usual cron
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
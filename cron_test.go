@@ -0,0 +1,61 @@
+package golru
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := parseCron(spec)
+		require.ErrorIs(t, err, ErrCronSpec, spec)
+	}
+
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		require.NoError(t, err)
+		return tm
+	}
+
+	for _, tc := range []struct {
+		spec, from, next string
+	}{
+		{"0 3 * * *", "2024-05-10 12:30", "2024-05-11 03:00"},
+		{"0 3 * * *", "2024-05-10 02:59", "2024-05-10 03:00"},
+		{"*/15 * * * *", "2024-05-10 12:31", "2024-05-10 12:45"},
+		{"30 9 * * 1-5", "2024-05-10 10:00", "2024-05-13 09:30"},
+		{"0 0 1 1 *", "2024-05-10 10:00", "2025-01-01 00:00"},
+		{"0 12 13 * 5", "2024-05-10 13:00", "2024-05-13 12:00"},
+		{"0,30 8-9 * * *", "2024-05-10 08:40", "2024-05-10 09:00"},
+	} {
+		schedule, err := parseCron(tc.spec)
+		require.NoError(t, err)
+		require.Equal(t, at(tc.next), schedule.next(at(tc.from)), tc.spec)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	c.Add("report:daily", 1)
+	c.Add("report:weekly", 2)
+	c.Add("user:1", 3)
+	_, err = c.AcquireLease("report:weekly", time.Minute)
+	require.NoError(t, err)
+
+	reports := func(entry Entry) bool {
+		return strings.HasPrefix(entry.Key, "report:")
+	}
+	require.Equal(t, 1, c.Purge(reports))
+	require.ElementsMatch(t, []string{"report:weekly", "user:1"}, c.Keys())
+
+	require.ErrorIs(t, c.SchedulePurge(context.Background(), "* *", reports), ErrCronSpec)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.SchedulePurge(ctx, "0 3 * * *", reports))
+}
@@ -0,0 +1,168 @@
+package golru
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrCSVFormat = errors.New("csv record is malformed")
+
+// csvHeader is the first line of the CSV written by ExportCSV and expected by ImportCSV
+var csvHeader = []string{"key", "value", "ttl", "meta"}
+
+// ExportCSV writes the live elements as CSV with the header of the columns key, value, ttl and meta, from the least
+// recently used element to the most recently used one, so ImportCSV restores their order. The value is encoded by the
+// codec, or formatted by fmt.Sprint without it, the ttl is the remaining lifetime like 1h30m0s, empty for the element
+// which never expires, and the meta is the JSON of the metadata, see AddWithMeta. Like Dump, it doesn't change the
+// order of the list nor the statistics
+func (c *cache) ExportCSV(w io.Writer, codec Codec) error {
+	return exportCSV(w, c.Entries(), codec)
+}
+
+// exportCSV writes the entries given in the order of the list
+func exportCSV(w io.Writer, entries []Entry, codec Codec) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := len(entries) - 1; i >= 0; i-- {
+		record, err := csvRecord(entries[i], codec, now)
+		if err != nil {
+			return err
+		}
+		if err = cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvRecord returns the columns of the entry
+func csvRecord(entry Entry, codec Codec, now time.Time) ([]string, error) {
+	var value string
+	switch {
+	case codec != nil:
+		data, err := codec.Marshal(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("golru: encode value of %q: %w", entry.Key, err)
+		}
+		value = string(data)
+	default:
+		if data, ok := entry.Value.([]byte); ok {
+			value = string(data)
+		} else {
+			value = fmt.Sprint(entry.Value)
+		}
+	}
+
+	var ttl string
+	if !entry.Expiration.IsZero() {
+		ttl = entry.Expiration.Sub(now).Round(time.Millisecond).String()
+	}
+
+	var meta string
+	if entry.Meta != nil {
+		data, err := json.Marshal(entry.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("golru: encode meta of %q: %w", entry.Key, err)
+		}
+		meta = string(data)
+	}
+
+	return []string{entry.Key, value, ttl, meta}, nil
+}
+
+// ImportCSV adds the elements from CSV written by ExportCSV or by hand, for example from a spreadsheet, in the order
+// of the lines. The value is decoded by the codec, or added as the string without it, the non-empty ttl is parsed by
+// time.ParseDuration and the element is added as by AddWithDeadline, and the non-empty meta is the JSON object of
+// the strings added as map[string]string by AddWithMeta. The keys which are already in the cache and the rows with
+// the non-positive ttl are skipped. Returns the number of the added elements and the first error of the reader, of
+// the codec, or ErrCSVFormat with the number of the line if the row is malformed, after which the import stops
+func (c *cache) ImportCSV(r io.Reader, codec Codec) (int, error) {
+	return importCSV(r, codec, func(string) *cache { return c })
+}
+
+// importCSV adds the elements from CSV to the caches returned by owner for their keys
+func importCSV(r io.Reader, codec Codec, owner func(key string) *cache) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCSVFormat, err)
+	}
+	for i, name := range csvHeader {
+		if header[i] != name {
+			return 0, fmt.Errorf("%w: line 1: unexpected header %q", ErrCSVFormat, header)
+		}
+	}
+
+	imported := 0
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, fmt.Errorf("%w: %v", ErrCSVFormat, err)
+		}
+
+		added, err := owner(record[0]).importRecord(record, codec)
+		if err != nil {
+			return imported, fmt.Errorf("line %d: %w", line, err)
+		}
+		if added {
+			imported++
+		}
+	}
+}
+
+// importRecord adds the element of the single row, reporting whether it was added
+func (c *cache) importRecord(record []string, codec Codec) (bool, error) {
+	var value interface{} = record[1]
+	if codec != nil {
+		decoded, err := codec.Unmarshal([]byte(record[1]))
+		if err != nil {
+			return false, fmt.Errorf("golru: decode value: %w", err)
+		}
+		value = decoded
+	}
+
+	var deadline time.Time
+	if record[2] != "" {
+		ttl, err := time.ParseDuration(record[2])
+		if err != nil {
+			return false, fmt.Errorf("%w: ttl %q", ErrCSVFormat, record[2])
+		}
+		if ttl <= 0 {
+			return false, nil
+		}
+		deadline = time.Now().Add(ttl)
+	}
+
+	var meta interface{}
+	if record[3] != "" {
+		var fields map[string]string
+		if err := json.Unmarshal([]byte(record[3]), &fields); err != nil {
+			return false, fmt.Errorf("%w: meta %q", ErrCSVFormat, record[3])
+		}
+		meta = fields
+	}
+
+	err := c.addDetailed(record[0], value, deadline, meta)
+	if errors.Is(err, ErrKeyExists) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,102 @@
+package golru
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportCSV(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	c.Add("plain", "a,b")
+	require.NoError(t, c.AddWithDeadline("short", "x", time.Now().Add(time.Hour)))
+	require.NoError(t, c.AddWithMeta("tagged", 42, map[string]string{"source": "db"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.ExportCSV(&buf, nil))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, []string{
+		"key,value,ttl,meta",
+		`plain,"a,b",,`,
+		"short,x,1h0m0s,",
+		`tagged,42,,"{""source"":""db""}"`,
+	}, lines)
+
+	dst, err := NewCache(10)
+	require.NoError(t, err)
+	dst.Add("tagged", "existing")
+	imported, err := dst.ImportCSV(&buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+	require.Equal(t, []string{"short", "plain", "tagged"}, keysOf(dst.Entries()))
+
+	entry, _ := dst.GetEntry("short")
+	require.Equal(t, "x", entry.Value)
+	require.WithinDuration(t, time.Now().Add(time.Hour), entry.Expiration, time.Second)
+	entry, _ = dst.GetEntry("tagged")
+	require.Equal(t, "existing", entry.Value)
+}
+
+func TestImportCSVWithCodec(t *testing.T) {
+	src, err := NewCache(10)
+	require.NoError(t, err)
+	require.NoError(t, src.AddWithMeta("n", 7, map[string]string{"k": "v"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, src.ExportCSV(&buf, GobCodec()))
+
+	dst, err := NewCache(10)
+	require.NoError(t, err)
+	imported, err := dst.ImportCSV(&buf, GobCodec())
+	require.NoError(t, err)
+	require.Equal(t, 1, imported)
+	entry, _ := dst.GetEntry("n")
+	require.Equal(t, 7, entry.Value)
+	require.Equal(t, map[string]string{"k": "v"}, entry.Meta)
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	_, err = c.ImportCSV(strings.NewReader("name,value,ttl,meta\n"), nil)
+	require.ErrorIs(t, err, ErrCSVFormat)
+	_, err = c.ImportCSV(strings.NewReader("key,value\n"), nil)
+	require.ErrorIs(t, err, ErrCSVFormat)
+
+	imported, err := c.ImportCSV(strings.NewReader("key,value,ttl,meta\na,1,,\nb,2,soon,\nc,3,,\n"), nil)
+	require.ErrorIs(t, err, ErrCSVFormat)
+	require.Contains(t, err.Error(), "line 3")
+	require.Equal(t, 1, imported)
+
+	imported, err = c.ImportCSV(strings.NewReader("key,value,ttl,meta\nd,1,-1s,\ne,2,,{bad}\n"), nil)
+	require.ErrorIs(t, err, ErrCSVFormat)
+	require.Equal(t, 0, imported)
+	require.Equal(t, 1, c.Len())
+
+	imported, err = c.ImportCSV(strings.NewReader(""), nil)
+	require.NoError(t, err)
+	require.Zero(t, imported)
+}
+
+func TestShardedCSV(t *testing.T) {
+	src, err := NewShardedCache(4, 40)
+	require.NoError(t, err)
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		src.Add(key, key)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, src.ExportCSV(&buf, nil))
+	dst, err := NewShardedCache(4, 40)
+	require.NoError(t, err)
+	imported, err := dst.ImportCSV(&buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, 5, imported)
+	require.ElementsMatch(t, src.Keys(), dst.Keys())
+}
@@ -0,0 +1,300 @@
+package golru
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+var (
+	ErrNoCurve      = errors.New("miss ratio curve is not enabled or has no reads yet")
+	ErrAdviceTarget = errors.New("target hit ratio should be greater than 0 and not greater than 1")
+)
+
+const (
+	// maxCurveKeys is the number of the sampled keys tracked by the curve. The keys not read for longer are forgotten,
+	// and their next read is counted as the first one
+	maxCurveKeys = 1 << 16
+	// curveBuckets is the number of the logarithmic buckets of the reuse distances, 8 per power of two
+	curveBuckets = 8 + 14*8
+)
+
+// CurvePoint is the estimated hit ratio of the cache of the given capacity
+type CurvePoint struct {
+	Capacity uint32
+	HitRatio float64
+}
+
+// missCurve estimates the hit ratio of the LRU cache of any capacity from the reuse distances of the sampled keys,
+// as SHARDS does. The reuse distance is the number of the other keys read since the previous read of the key, the
+// read hits in the cache of the capacity larger than it. The distances of the keys sampled at the rate are scaled by
+// the rate to the whole traffic.
+type missCurve struct {
+	rate      float64
+	threshold uint64
+
+	last map[string]int
+	tree []int
+	next int
+
+	reads     uint64
+	distances [curveBuckets]uint64
+}
+
+// WithMissRatioCurve makes the cache estimate the hit ratio it would have with another capacity from the live reads.
+// Sample is the fraction of the keys tracked for it, the values out of the range (0, 1) make it track all of them.
+// A small sample, like 0.01, is usually enough for a cache with many keys and takes little memory
+func WithMissRatioCurve(sample float64) CacheOption {
+	return func(cache *cache) {
+		if sample <= 0 || sample >= 1 {
+			sample = 1
+		}
+		cache.curve = &missCurve{
+			rate:      sample,
+			threshold: uint64(sample * math.MaxUint64),
+			last:      make(map[string]int),
+			tree:      make([]int, 1025),
+		}
+		if sample == 1 {
+			cache.curve.threshold = math.MaxUint64
+		}
+	}
+}
+
+// EstimateHitRatio returns the hit ratio the cache would have with the given capacity, estimated from the reads
+// since the creation of the cache or the last ResetStats. Returns false if the curve isn't enabled by
+// WithMissRatioCurve or there were no reads yet
+func (c *cache) EstimateHitRatio(capacity uint32) (float64, bool) {
+	c.lock()
+	defer c.unlock()
+
+	if c.curve == nil || c.curve.reads == 0 {
+		return 0, false
+	}
+
+	return c.curve.hitRatio(capacity), true
+}
+
+// MissRatioCurve returns the estimated hit ratios at the given capacities in ascending order of them, or nil if the
+// curve isn't enabled by WithMissRatioCurve or there were no reads yet
+func (c *cache) MissRatioCurve(capacities ...uint32) []CurvePoint {
+	c.lock()
+	defer c.unlock()
+
+	if c.curve == nil || c.curve.reads == 0 {
+		return nil
+	}
+
+	sorted := append([]uint32(nil), capacities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	points := make([]CurvePoint, len(sorted))
+	for i, capacity := range sorted {
+		points[i] = CurvePoint{Capacity: capacity, HitRatio: c.curve.hitRatio(capacity)}
+	}
+
+	return points
+}
+
+// observeRead passes the read of the key to the curve and to the hot key detection, if they are enabled
+func (c *cache) observeRead(key string) {
+	if c.curve != nil {
+		c.curve.read(key)
+	}
+	c.observeHot(key)
+}
+
+// read records the reuse distance of the key if it is sampled
+func (m *missCurve) read(key string) {
+	if m.threshold != math.MaxUint64 {
+		if sampleHash(key) > m.threshold {
+			return
+		}
+	}
+
+	m.reads++
+	if m.next == len(m.tree)-1 {
+		m.compact()
+	}
+	if pos, ok := m.last[key]; ok {
+		m.distances[curveBucket(m.sum(m.next)-m.sum(pos))]++
+		m.add(pos, -1)
+	}
+	m.next++
+	m.add(m.next, 1)
+	m.last[key] = m.next
+}
+
+// hitRatio sums up the reads with the scaled distances less than the capacity, linearly within the bucket
+func (m *missCurve) hitRatio(capacity uint32) float64 {
+	limit := float64(capacity) * m.rate
+
+	var hits float64
+	for b, n := range m.distances {
+		if n == 0 {
+			continue
+		}
+		lower, upper := curveBounds(b)
+		switch {
+		case float64(upper) <= limit:
+			hits += float64(n)
+		case float64(lower) < limit:
+			hits += float64(n) * (limit - float64(lower)) / float64(upper-lower)
+		}
+	}
+
+	return hits / float64(m.reads)
+}
+
+// compact renumbers the last reads of the tracked keys from 1 keeping their order, so the tree doesn't grow with the
+// number of reads. The tree grows only when it is more than half full, and the oldest keys above maxCurveKeys are
+// forgotten
+func (m *missCurve) compact() {
+	keys := make([]string, 0, len(m.last))
+	for key := range m.last {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return m.last[keys[i]] < m.last[keys[j]] })
+	if len(keys) > maxCurveKeys {
+		for _, key := range keys[:len(keys)-maxCurveKeys] {
+			delete(m.last, key)
+		}
+		keys = keys[len(keys)-maxCurveKeys:]
+	}
+
+	size := len(m.tree) - 1
+	for size < 2*len(keys)+2 {
+		size *= 2
+	}
+	m.tree = make([]int, size+1)
+	m.next = 0
+	for _, key := range keys {
+		m.next++
+		m.add(m.next, 1)
+		m.last[key] = m.next
+	}
+}
+
+// add changes the mark at the position of the Fenwick tree
+func (m *missCurve) add(pos, delta int) {
+	for ; pos < len(m.tree); pos += pos & -pos {
+		m.tree[pos] += delta
+	}
+}
+
+// sum returns the number of the marks up to the position including it
+func (m *missCurve) sum(pos int) int {
+	total := 0
+	for ; pos > 0; pos -= pos & -pos {
+		total += m.tree[pos]
+	}
+
+	return total
+}
+
+// reset forgets all the reads
+func (m *missCurve) reset() {
+	m.last = make(map[string]int)
+	m.tree = make([]int, 1025)
+	m.next = 0
+	m.reads = 0
+	m.distances = [curveBuckets]uint64{}
+}
+
+// curveBucket returns the bucket of the distance: the exact one below 8 and one of 8 per power of two above
+func curveBucket(d int) int {
+	if d < 8 {
+		return d
+	}
+
+	e := bits.Len(uint(d)) - 1
+	b := 8 + (e-3)*8 + (d>>(e-3))&7
+	if b >= curveBuckets {
+		return curveBuckets - 1
+	}
+
+	return b
+}
+
+// curveBounds returns the range of the distances of the bucket, the upper bound is excluded
+func curveBounds(b int) (lower, upper int) {
+	if b < 8 {
+		return b, b + 1
+	}
+
+	shift := (b - 8) / 8
+	sub := (b - 8) % 8
+
+	return (8 + sub) << shift, (9 + sub) << shift
+}
+
+// CapacityAdvice is the capacity recommended for the target hit ratio. Current is the capacity of the cache, or the
+// number of its elements if it is unbounded. Reachable is false if the target isn't reached even by the capacity
+// holding all the tracked keys, in that case Recommended is that capacity. Oversized tells that the target is
+// reached with less than a half of the current capacity, so the memory can be saved without losing the hit ratio
+type CapacityAdvice struct {
+	Target              float64
+	Current             uint32
+	CurrentHitRatio     float64
+	Recommended         uint32
+	RecommendedHitRatio float64
+	Reachable           bool
+	Oversized           bool
+}
+
+// AdviseCapacity recommends the smallest capacity reaching the target hit ratio by the miss ratio curve. Returns
+// ErrAdviceTarget if the target is out of the range (0, 1], and ErrNoCurve if the curve isn't enabled by
+// WithMissRatioCurve or there were no reads yet
+func (c *cache) AdviseCapacity(target float64) (CapacityAdvice, error) {
+	if target <= 0 || target > 1 {
+		return CapacityAdvice{}, ErrAdviceTarget
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	if c.curve == nil || c.curve.reads == 0 {
+		return CapacityAdvice{}, ErrNoCurve
+	}
+
+	current := c.capacity
+	if current == 0 {
+		current = uint32(c.chain.Len())
+	}
+	advice := CapacityAdvice{Target: target, Current: current, CurrentHitRatio: c.curve.hitRatio(current)}
+
+	limit := c.curve.saturation()
+	if c.curve.hitRatio(limit) < target {
+		advice.Recommended, advice.RecommendedHitRatio = limit, c.curve.hitRatio(limit)
+		return advice, nil
+	}
+
+	low, high := uint32(1), limit
+	for low < high {
+		middle := low + (high-low)/2
+		if c.curve.hitRatio(middle) >= target {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+
+	advice.Recommended, advice.RecommendedHitRatio = low, c.curve.hitRatio(low)
+	advice.Reachable = true
+	advice.Oversized = uint64(low)*2 < uint64(current)
+
+	return advice, nil
+}
+
+// saturation returns the capacity above which the estimated hit ratio doesn't grow, the scaled upper bound of the
+// longest observed distance
+func (m *missCurve) saturation() uint32 {
+	for b := len(m.distances) - 1; b >= 0; b-- {
+		if m.distances[b] != 0 {
+			_, upper := curveBounds(b)
+			return uint32(math.Min(math.Ceil(float64(upper)/m.rate), math.MaxUint32))
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,103 @@
+package golru
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissRatioCurveCyclic(t *testing.T) {
+	c, err := NewCache(10, WithMissRatioCurve(1))
+	require.NoError(t, err)
+
+	_, ok := c.EstimateHitRatio(10)
+	require.False(t, ok)
+
+	for i := 0; i < 1000; i++ {
+		c.Get("test" + strconv.Itoa(i%100))
+	}
+
+	ratio, ok := c.EstimateHitRatio(200)
+	require.True(t, ok)
+	require.InDelta(t, 0.9, ratio, 0.001)
+	ratio, _ = c.EstimateHitRatio(50)
+	require.Zero(t, ratio)
+
+	points := c.MissRatioCurve(200, 50, 100)
+	require.Len(t, points, 3)
+	require.Equal(t, uint32(50), points[0].Capacity)
+	require.Equal(t, uint32(200), points[2].Capacity)
+	require.Less(t, points[0].HitRatio, points[1].HitRatio)
+
+	c.ResetStats()
+	require.Nil(t, c.MissRatioCurve(100))
+
+	plain, err := NewCache(10)
+	require.NoError(t, err)
+	plain.Get("test")
+	require.Nil(t, plain.MissRatioCurve(100))
+}
+
+func TestMissRatioCurveMatchesLRU(t *testing.T) {
+	for _, sample := range []float64{1, 0.1} {
+		estimator, err := NewCache(1, WithMissRatioCurve(sample))
+		require.NoError(t, err)
+
+		capacities := []uint32{100, 500, 2000}
+		caches := make([]Cacher, len(capacities))
+		for i, capacity := range capacities {
+			caches[i], err = NewCache(capacity)
+			require.NoError(t, err)
+		}
+
+		zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.2, 100, 1<<16)
+		for i := 0; i < 200000; i++ {
+			key := strconv.FormatUint(zipf.Uint64(), 10)
+			estimator.Get(key)
+			for _, c := range caches {
+				if _, ok := c.Get(key); !ok {
+					c.Add(key, nil)
+				}
+			}
+		}
+
+		for i, capacity := range capacities {
+			estimated, ok := estimator.EstimateHitRatio(capacity)
+			require.True(t, ok)
+			require.InDelta(t, caches[i].Stats().HitRatio(), estimated, 0.05, "sample %v capacity %d", sample, capacity)
+		}
+	}
+}
+
+func TestAdviseCapacity(t *testing.T) {
+	c, err := NewCache(1000, WithMissRatioCurve(1))
+	require.NoError(t, err)
+
+	_, err = c.AdviseCapacity(0.5)
+	require.ErrorIs(t, err, ErrNoCurve)
+	_, err = c.AdviseCapacity(1.5)
+	require.ErrorIs(t, err, ErrAdviceTarget)
+
+	for i := 0; i < 1000; i++ {
+		c.Get("test" + strconv.Itoa(i%100))
+	}
+
+	advice, err := c.AdviseCapacity(0.85)
+	require.NoError(t, err)
+	require.True(t, advice.Reachable)
+	require.True(t, advice.Oversized)
+	require.Equal(t, uint32(1000), advice.Current)
+	require.InDelta(t, 0.9, advice.CurrentHitRatio, 0.001)
+	require.GreaterOrEqual(t, advice.RecommendedHitRatio, 0.85)
+	require.InDelta(t, 100, advice.Recommended, 5)
+	smaller, _ := c.EstimateHitRatio(advice.Recommended - 1)
+	require.Less(t, smaller, 0.85)
+
+	advice, err = c.AdviseCapacity(0.95)
+	require.NoError(t, err)
+	require.False(t, advice.Reachable)
+	require.False(t, advice.Oversized)
+	require.InDelta(t, 0.9, advice.RecommendedHitRatio, 0.001)
+}
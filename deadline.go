@@ -0,0 +1,75 @@
+package golru
+
+import "time"
+
+// AddWithDeadline works like AddE, and the element expires at the deadline, or earlier if the ttl of the cache ends
+// first. The deadline is absolute, so ChangeValue doesn't move it. Returns ErrExpired without adding the element if
+// the deadline has already passed. Like GetEntry, it doesn't go through the hooks and the middleware
+func (c *cache) AddWithDeadline(key string, value interface{}, deadline time.Time) error {
+	if !deadline.After(time.Now()) {
+		return ErrExpired
+	}
+
+	return c.addDetailed(key, value, deadline, nil)
+}
+
+// addDetailed works like AddE and sets the deadline and the metadata of the new element, the zero deadline sets none
+func (c *cache) addDetailed(key string, value interface{}, deadline time.Time, meta interface{}) error {
+	key, err := c.prepare(key)
+	if err != nil {
+		return err
+	}
+	attrs := c.attributes(value)
+	if c.transforms() {
+		if value, err = c.store(value); err != nil {
+			return err
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	c.stage(key, attrs)
+	if err = c.add(key, value); err != nil {
+		return err
+	}
+	element := c.items[key]
+	element.meta = meta
+	if !deadline.IsZero() {
+		element.deadline = deadline
+		c.rebucket(element)
+	}
+	c.countCompressed(value)
+
+	return nil
+}
+
+// AddUntilEndOfHour adds the element expiring at the end of the current hour in the location, see AddWithDeadline
+func (c *cache) AddUntilEndOfHour(key string, value interface{}, loc *time.Location) error {
+	return c.AddWithDeadline(key, value, EndOfHour(time.Now(), loc))
+}
+
+// AddUntilEndOfDay adds the element expiring at the midnight in the location, see AddWithDeadline
+func (c *cache) AddUntilEndOfDay(key string, value interface{}, loc *time.Location) error {
+	return c.AddWithDeadline(key, value, EndOfDay(time.Now(), loc))
+}
+
+// EndOfHour returns the beginning of the hour following the moment t in the location, the local one if it is nil
+func EndOfHour(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+}
+
+// EndOfDay returns the midnight following the moment t in the location, the local one if it is nil
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,125 @@
+package golru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarBoundaries(t *testing.T) {
+	loc := time.FixedZone("UTC+5:30", 5*3600+1800)
+	moment := time.Date(2024, 12, 31, 23, 10, 0, 0, loc)
+
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, loc), EndOfHour(moment, loc))
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, loc), EndOfDay(moment, loc))
+	require.Equal(t, time.Date(2024, 12, 31, 18, 0, 0, 0, time.UTC), EndOfHour(moment, time.UTC))
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndOfDay(moment, time.UTC))
+}
+
+func TestAddWithDeadline(t *testing.T) {
+	c, err := NewCache(2, WithTTL(2*24*60*60))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.AddWithDeadline("past", 1, time.Now().Add(-time.Second)), ErrExpired)
+	require.NoError(t, c.AddWithDeadline("soon", 1, time.Now().Add(20*time.Millisecond)))
+	require.ErrorIs(t, c.AddWithDeadline("soon", 2, time.Now().Add(time.Hour)), ErrKeyExists)
+
+	require.NoError(t, c.AddUntilEndOfDay("quota", 10, time.UTC))
+	entry, ok := c.GetEntry("quota")
+	require.True(t, ok)
+	require.Equal(t, EndOfDay(time.Now(), time.UTC), entry.Expiration.In(time.UTC))
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = c.GetE("soon")
+	require.ErrorIs(t, err, ErrExpired)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestAddUntilEndOfHourWithoutTTL(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddUntilEndOfHour("rate", 1, nil))
+	entry, _ := c.GetEntry("rate")
+	require.Equal(t, EndOfHour(time.Now(), nil), entry.Expiration)
+	require.True(t, c.ChangeValue("rate", 2))
+	entry, _ = c.GetEntry("rate")
+	require.Equal(t, EndOfHour(time.Now(), nil), entry.Expiration)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	c, err := NewCache(0, WithTTL(0.1), WithIdleTimeout(30*time.Millisecond))
+	require.NoError(t, err)
+
+	c.Add("active", 1)
+	c.Add("idle", 2)
+
+	entry, _ := c.GetEntry("idle")
+	require.Equal(t, entry.Created.Add(30*time.Millisecond), entry.Expiration)
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(15 * time.Millisecond)
+		_, ok := c.Get("active")
+		require.True(t, ok)
+	}
+	_, err = c.GetE("idle")
+	require.ErrorIs(t, err, ErrExpired)
+
+	entry, _ = c.GetEntry("active")
+	require.Equal(t, entry.LastAccess.Add(30*time.Millisecond), entry.Expiration)
+	time.Sleep(50 * time.Millisecond)
+	_, err = c.GetE("active")
+	require.ErrorIs(t, err, ErrExpired)
+
+	idle, err := NewCache(0, WithIdleTimeout(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, idle.(*cache).janitorInterval())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, idle.Expire(ctx))
+}
+
+func TestMaxLifetime(t *testing.T) {
+	c, err := NewCache(0, WithIdleTimeout(time.Minute), WithMaxLifetime(50*time.Millisecond))
+	require.NoError(t, err)
+
+	c.Add("renewed", 1)
+	c.Add("leased", 2)
+	_, err = c.AcquireLease("leased", time.Hour)
+	require.NoError(t, err)
+
+	entry, _ := c.GetEntry("renewed")
+	require.Equal(t, entry.Created.Add(50*time.Millisecond), entry.Expiration)
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		require.True(t, c.ChangeValue("renewed", i))
+		_, ok := c.Get("renewed")
+		require.True(t, ok)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	_, err = c.GetE("renewed")
+	require.ErrorIs(t, err, ErrExpired)
+	_, err = c.GetE("leased")
+	require.ErrorIs(t, err, ErrExpired)
+	require.Equal(t, 50*time.Millisecond, c.(*cache).janitorInterval())
+}
+
+func TestMaxLifetimeBuckets(t *testing.T) {
+	c, err := NewCache(0, WithMaxLifetime(20*time.Millisecond), WithTTLBuckets(5*time.Millisecond))
+	require.NoError(t, err)
+
+	c.Add("test", 1)
+	_, err = c.AcquireLease("test", time.Hour)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Expire(ctx))
+	require.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, 5*time.Millisecond)
+}
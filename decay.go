@@ -0,0 +1,71 @@
+package golru
+
+import (
+	"math"
+	"time"
+)
+
+// FrequencyDecay describes the aging of the frequency counters of the elements used by PolicyS3FIFO, PolicyHyperbolic
+// and PolicyGDSF. All the counters are halved every Period and after every Accesses counted accesses, zero disables
+// either. Width is the number of the bits of a counter, 32 by default, the counter saturates at its maximum. S3-FIFO
+// never counts above 3 whatever the width is. The hits of Entry and Stats are never decayed
+type FrequencyDecay struct {
+	Period   time.Duration
+	Accesses uint64
+	Width    uint
+}
+
+// WithFrequencyDecay makes the frequency counters of the elements age, so the elements popular long ago don't keep
+// their place in the long-running cache forever. Halving takes constant time however many elements there are: the
+// counter of an element is caught up with the halvings it has missed when it is used next time
+func WithFrequencyDecay(decay FrequencyDecay) CacheOption {
+	return func(cache *cache) {
+		cache.decay = decay
+	}
+}
+
+// frequencyLimit returns the maximum of the counters of the given width
+func frequencyLimit(width uint) uint32 {
+	if width == 0 || width >= 32 {
+		return math.MaxUint32
+	}
+
+	return 1<<width - 1
+}
+
+// frequency returns the counter of the element after the halvings it has missed
+func (c *cache) frequency(element *item) uint32 {
+	if shift := c.epoch - element.epoch; shift != 0 {
+		if shift >= 32 {
+			element.freq = 0
+		} else {
+			element.freq >>= shift
+		}
+		element.epoch = c.epoch
+	}
+
+	return element.freq
+}
+
+// bump counts the access of the element up to the limit, halving all the counters first if it is time to
+func (c *cache) bump(element *item, limit uint32, now time.Time) {
+	if c.decay.Period != 0 {
+		if c.decayedAt.IsZero() {
+			c.decayedAt = now
+		} else if now.Sub(c.decayedAt) >= c.decay.Period {
+			c.epoch++
+			c.decayedAt = now
+		}
+	}
+	if c.decay.Accesses != 0 {
+		c.accesses++
+		if c.accesses >= c.decay.Accesses {
+			c.epoch++
+			c.accesses = 0
+		}
+	}
+
+	if f := c.frequency(element); f < limit {
+		element.freq = f + 1
+	}
+}
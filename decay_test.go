@@ -0,0 +1,67 @@
+package golru
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrequencyLimit(t *testing.T) {
+	require.Equal(t, uint32(math.MaxUint32), frequencyLimit(0))
+	require.Equal(t, uint32(math.MaxUint32), frequencyLimit(40))
+	require.Equal(t, uint32(15), frequencyLimit(4))
+}
+
+func TestFrequencyWidth(t *testing.T) {
+	c, err := NewCache(10, WithEvictionPolicy(PolicyGDSF), WithFrequencyDecay(FrequencyDecay{Width: 2}))
+	require.NoError(t, err)
+
+	c.Add("key", 1)
+	for i := 0; i < 10; i++ {
+		c.Get("key")
+	}
+	tc := c.(*cache)
+	require.Equal(t, uint32(3), tc.frequency(tc.items["key"]))
+	require.Equal(t, uint64(10), tc.items["key"].hits)
+}
+
+func TestFrequencyDecayByAccesses(t *testing.T) {
+	c, err := NewCache(10, WithEvictionPolicy(PolicyHyperbolic), WithFrequencyDecay(FrequencyDecay{Accesses: 8}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("old", 1)
+	c.Add("new", 2)
+	for i := 0; i < 7; i++ {
+		c.Get("old")
+	}
+	require.Equal(t, uint32(7), tc.frequency(tc.items["old"]))
+
+	c.Get("new")
+	require.Equal(t, uint32(1), tc.epoch)
+	require.Equal(t, uint32(3), tc.frequency(tc.items["old"]))
+	require.Equal(t, uint32(1), tc.frequency(tc.items["new"]))
+}
+
+func TestFrequencyDecayByPeriod(t *testing.T) {
+	c, err := NewCache(10, WithEvictionPolicy(PolicyS3FIFO),
+		WithFrequencyDecay(FrequencyDecay{Period: 20 * time.Millisecond}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("key", 1)
+	c.Get("key")
+	c.Get("key")
+	require.Equal(t, uint32(2), tc.frequency(tc.items["key"]))
+
+	time.Sleep(30 * time.Millisecond)
+	c.Get("key")
+	require.Equal(t, uint32(2), tc.frequency(tc.items["key"]))
+
+	for i := 0; i < 40; i++ {
+		tc.epoch++
+	}
+	require.Zero(t, tc.frequency(tc.items["key"]))
+}
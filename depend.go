@@ -0,0 +1,141 @@
+package golru
+
+// AddWithDependencies works like AddE and makes the element depend on the given keys, so it is evicted with the
+// EvictedByDependency reason as soon as any of them is invalidated: removed, evicted, expired or replaced by a new
+// value. The invalidation cascades through the dependents of the dependents, and the leases don't stop it, as the
+// derived value is stale anyway. The keys don't have to be in the cache, see Invalidate. Like GetEntry, it doesn't go
+// through the hooks and the middleware
+func (c *cache) AddWithDependencies(key string, value interface{}, deps ...string) error {
+	key, err := c.prepare(key)
+	if err != nil {
+		return err
+	}
+	attrs := c.attributes(value)
+	if c.transforms() {
+		if value, err = c.store(value); err != nil {
+			return err
+		}
+	}
+	parents := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if dep = c.normalize(dep); dep != key {
+			parents = append(parents, dep)
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	c.stage(key, attrs)
+	if err = c.add(key, value); err != nil {
+		return err
+	}
+	element := c.items[key]
+	element.parents = parents
+	c.depend(element)
+	c.countCompressed(value)
+
+	return nil
+}
+
+// Invalidate removes the element like Remove, regardless of its leases, and evicts all its dependents, see
+// AddWithDependencies. The key doesn't have to be in the cache, so the dependents of the value kept elsewhere are
+// invalidated when it changes. Returns the number of the elements which have left the cache
+func (c *cache) Invalidate(key string) int {
+	key = c.normalize(key)
+
+	c.lock()
+	defer c.unlock()
+
+	size := c.chain.Len()
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+		c.queueRemoval(element.key, element.value)
+		c.queueAudit(element.key)
+		c.entomb(element.key)
+		c.release(element)
+	} else {
+		c.entomb(key)
+		c.cascade(key)
+	}
+
+	return size - c.chain.Len()
+}
+
+// depend registers the element as the dependent of its parents
+func (c *cache) depend(element *item) {
+	if len(element.parents) == 0 {
+		return
+	}
+	if c.dependents == nil {
+		c.dependents = make(map[string]map[string]struct{})
+	}
+
+	for _, parent := range element.parents {
+		dependents, ok := c.dependents[parent]
+		if !ok {
+			dependents = make(map[string]struct{})
+			c.dependents[parent] = dependents
+		}
+		dependents[element.key] = struct{}{}
+	}
+}
+
+// undepend removes the element from the dependents of its parents
+func (c *cache) undepend(element *item) {
+	for _, parent := range element.parents {
+		dependents := c.dependents[parent]
+		delete(dependents, element.key)
+		if len(dependents) == 0 {
+			delete(c.dependents, parent)
+		}
+	}
+}
+
+// cascade evicts all the direct and transitive dependents of the key. The whole closure is collected before anything
+// is evicted, so the cycles of the dependencies end, and the key itself stays in the cache even if it depends on its
+// own dependent. Within deferCascades the key is only remembered, and its dependents are evicted when the traversal
+// ends
+func (c *cache) cascade(key string) {
+	if len(c.dependents) == 0 {
+		return
+	}
+	if c.deferring {
+		c.deferred = append(c.deferred, key)
+		return
+	}
+
+	seen := map[string]struct{}{key: {}}
+	var doomed []string
+	for queue := []string{key}; len(queue) != 0; queue = queue[1:] {
+		for dependent := range c.dependents[queue[0]] {
+			if _, ok := seen[dependent]; !ok {
+				seen[dependent] = struct{}{}
+				doomed = append(doomed, dependent)
+				queue = append(queue, dependent)
+			}
+		}
+		delete(c.dependents, queue[0])
+	}
+
+	for _, dependent := range doomed {
+		if element, ok := c.items[dependent]; ok {
+			c.evict(element, EvictedByDependency)
+		}
+	}
+}
+
+// deferCascades runs the traversal of the elements evicting some of them and evicts their dependents after it ends.
+// Otherwise the cascade could evict the element the traversal is about to visit, and it would meet the element
+// already unlinked from the list
+func (c *cache) deferCascades(traverse func()) {
+	c.deferring = true
+	traverse()
+	c.deferring = false
+
+	deferred := c.deferred
+	c.deferred = nil
+	for _, key := range deferred {
+		c.cascade(key)
+	}
+}
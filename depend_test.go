@@ -0,0 +1,123 @@
+package golru
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddWithDependencies(t *testing.T) {
+	evicted := map[string]EvictReason{}
+	c, err := NewCache(0, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted[key] = reason
+	}))
+	require.NoError(t, err)
+
+	c.Add("user", 1)
+	c.Add("orders", 2)
+	require.NoError(t, c.AddWithDependencies("summary", 3, "user", "orders"))
+	require.NoError(t, c.AddWithDependencies("report", 4, "summary"))
+	require.NoError(t, c.AddWithDependencies("other", 5, "orders"))
+
+	require.True(t, c.ChangeValue("user", 10))
+	require.Equal(t, map[string]EvictReason{"summary": EvictedByDependency, "report": EvictedByDependency}, evicted)
+	keys := c.Keys()
+	sort.Strings(keys)
+	require.Equal(t, []string{"orders", "other", "user"}, keys)
+
+	require.True(t, c.Remove("orders"))
+	require.Equal(t, EvictedByDependency, evicted["other"])
+	require.Equal(t, []string{"user"}, c.Keys())
+	require.Empty(t, c.(*cache).dependents)
+}
+
+func TestInvalidate(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithDependencies("price", 1, "db:products"))
+	require.NoError(t, c.AddWithDependencies("page", 2, "price"))
+	c.Add("unrelated", 3)
+	require.Equal(t, 0, c.Invalidate("db:missing"))
+	require.Equal(t, 2, c.Invalidate("db:products"))
+	require.Equal(t, []string{"unrelated"}, c.Keys())
+	require.Equal(t, 1, c.Invalidate("unrelated"))
+	require.Equal(t, 0, c.Len())
+}
+
+func TestDependencyCycle(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithDependencies("a", 1, "b", "a"))
+	require.NoError(t, c.AddWithDependencies("b", 2, "a"))
+	require.True(t, c.ChangeValue("a", 3))
+	require.Equal(t, []string{"a"}, c.Keys())
+	value, _ := c.Get("a")
+	require.Equal(t, 3, value)
+
+	require.NoError(t, c.AddWithDependencies("b", 2, "a"))
+	require.NoError(t, c.AddWithDependencies("c", 3, "b"))
+	clone := c.Clone(nil)
+	require.Equal(t, 3, clone.Invalidate("a"))
+	require.Equal(t, 3, c.Len())
+}
+
+func TestDependencyEvictedByCapacity(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	c.Add("parent", 1)
+	require.NoError(t, c.AddWithDependencies("child", 2, "parent"))
+	c.Add("new", 3)
+	require.Equal(t, []string{"new"}, c.Keys())
+}
+
+func TestDependencyCascadeDuringSweep(t *testing.T) {
+	c, err := NewCache(0, WithTTL(0.05))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithDependencies("b", 1, "a"))
+	c.Add("a", 2)
+	time.Sleep(60 * time.Millisecond)
+
+	require.NotPanics(t, c.(*cache).inspect)
+	require.Zero(t, c.Len())
+	require.Empty(t, c.(*cache).dependents)
+}
+
+func TestDependencyCascadeDuringBucketExpiration(t *testing.T) {
+	c, err := NewCache(0, WithTTL(0.05), WithTTLBuckets(10*time.Millisecond))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithDependencies("b", 1, "a"))
+	c.Add("a", 2)
+	time.Sleep(80 * time.Millisecond)
+
+	require.NotPanics(t, c.(*cache).inspect)
+	require.Zero(t, c.Len())
+	require.Empty(t, c.(*cache).dependents)
+}
+
+func TestDependencyCascadeDuringPurge(t *testing.T) {
+	evicted := map[string]EvictReason{}
+	c, err := NewCache(0, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted[key] = reason
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithDependencies("b", 1, "a"))
+	c.Add("c", 3)
+	c.Add("a", 2)
+
+	var purged int
+	require.NotPanics(t, func() {
+		purged = c.Purge(func(e Entry) bool { return e.Key != "c" })
+	})
+	require.Equal(t, 2, purged)
+	require.Equal(t, []string{"c"}, c.Keys())
+	require.Empty(t, evicted)
+	require.Empty(t, c.(*cache).dependents)
+}
@@ -0,0 +1,102 @@
+package golru
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const redactedValue = "<redacted>"
+
+// DumpOption changes the output of Dump
+type DumpOption func(*dumpConfig)
+
+// dumpConfig describes the output of Dump
+type dumpConfig struct {
+	redactValues bool
+	redactKey    func(key string) string
+}
+
+// WithRedactedValues hides the values in the output of Dump, leaving only the keys and the statistics
+func WithRedactedValues() DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.redactValues = true
+	}
+}
+
+// String returns the concise description of the cache with its size, capacity and ttl
+func (c *cache) String() string {
+	c.lock()
+	defer c.unlock()
+
+	return c.describe()
+}
+
+// Dump writes all the elements in the order of the list, from the most recently used to the least recently used
+// one, with their age, remaining lifetime and hits. It doesn't change the order of the list nor the statistics. The
+// keys are redacted by WithKeyRedactor
+func (c *cache) Dump(w io.Writer, opts ...DumpOption) error {
+	cfg := &dumpConfig{redactKey: c.redact}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	c.lock()
+	header := c.describe()
+	entries := make([]Entry, 0, c.chain.Len())
+	for current := c.chain.Front(); current != nil; current = c.chain.Next(current) {
+		entries = append(entries, c.snapshot(current))
+	}
+	c.unlock()
+	c.reveal(entries)
+
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, entry := range entries {
+		if err := dumpEntry(w, i, entry, now, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describe returns the description of the cache, the cache must be locked
+func (c *cache) describe() string {
+	capacity := "unbounded"
+	if c.capacity != 0 {
+		capacity = fmt.Sprint(c.capacity)
+	}
+
+	ttl := "none"
+	if c.ttl != 0 {
+		ttl = c.lifetime.String()
+	}
+
+	return fmt.Sprintf("golru.cache{len: %d, cap: %s, ttl: %s}", c.chain.Len(), capacity, ttl)
+}
+
+// dumpEntry writes a single line describing the entry
+func dumpEntry(w io.Writer, i int, entry Entry, now time.Time, cfg *dumpConfig) error {
+	ttl := "none"
+	if !entry.Expiration.IsZero() {
+		remaining := entry.Expiration.Sub(now)
+		ttl = remaining.Round(time.Millisecond).String()
+		if remaining <= 0 {
+			ttl = "expired"
+		}
+	}
+
+	var value interface{} = entry.Value
+	if cfg.redactValues {
+		value = redactedValue
+	}
+
+	_, err := fmt.Fprintf(w, "%d. key=%q age=%s ttl=%s hits=%d value=%v\n", i+1, cfg.redactKey(entry.Key),
+		now.Sub(entry.Created).Round(time.Millisecond), ttl, entry.Hits, value)
+
+	return err
+}
@@ -0,0 +1,58 @@
+package golru
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	c, err := NewCache(3, WithTTL(1.5))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	require.Equal(t, "golru.cache{len: 1, cap: 3, ttl: 1.5s}", c.(*cache).String())
+
+	unbounded, err := NewCache(0)
+	require.NoError(t, err)
+	require.Equal(t, "golru.cache{len: 0, cap: unbounded, ttl: none}", unbounded.String())
+}
+
+func TestDump(t *testing.T) {
+	c, err := NewCache(3, WithTTL(60))
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", "secret")
+	c.Get("first")
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Dump(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "golru.cache{len: 2, cap: 3, ttl: 1m0s}", lines[0])
+	require.True(t, strings.HasPrefix(lines[1], `1. key="first" age=`))
+	require.Contains(t, lines[1], "hits=1 value=1")
+	require.Contains(t, lines[2], "value=secret")
+
+	buf.Reset()
+	require.NoError(t, c.Dump(&buf, WithRedactedValues()))
+	require.NotContains(t, buf.String(), "secret")
+	require.Contains(t, buf.String(), "value=<redacted>")
+	require.Equal(t, uint64(1), c.Stats().Hits)
+}
+
+func TestShardedDump(t *testing.T) {
+	s, err := NewShardedCache(2, 10)
+	require.NoError(t, err)
+
+	s.Add("test", 42)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Dump(&buf))
+	require.Contains(t, buf.String(), "golru.ShardedCache{shards: 2, len: 1}")
+	require.Contains(t, buf.String(), "shard 1: golru.cache")
+	require.Contains(t, buf.String(), `key="test"`)
+}
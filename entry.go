@@ -0,0 +1,192 @@
+package golru
+
+import (
+	"sort"
+	"time"
+)
+
+// Entry is a snapshot of the element of the cache together with its access statistics. Expiration is zero if the
+// element never expires. TTL is the effective ttl of the element, which depends on its hits with the adaptive ttl.
+// Version is the version of the value, see AddVersion. Meta is the metadata of the element, see AddWithMeta
+type Entry struct {
+	Key        string
+	Value      interface{}
+	Created    time.Time
+	Expiration time.Time
+	TTL        time.Duration
+	LastAccess time.Time
+	Hits       uint64
+	Version    uint64
+	Meta       interface{}
+}
+
+// GetEntry returns the snapshot of the element without counting it as an access, so neither the order of the list
+// nor the statistics change. Returns false if there is no such key or the element has expired
+func (c *cache) GetEntry(key string) (Entry, bool) {
+	c.lock()
+	element, ok := c.validate(c.normalize(key))
+	if !ok || c.expired(element, time.Now()) {
+		c.unlock()
+		return Entry{}, false
+	}
+	entry := c.snapshot(element)
+	c.unlock()
+
+	return c.reveal([]Entry{entry})[0], true
+}
+
+// Entries returns the snapshots of all the live elements in the order of the list, from the most recently used to
+// the least recently used one
+func (c *cache) Entries() []Entry {
+	c.lock()
+	now := time.Now()
+	entries := make([]Entry, 0, c.chain.Len())
+	for current := c.chain.Front(); current != nil; current = c.chain.Next(current) {
+		if !c.expired(current, now) {
+			entries = append(entries, c.snapshot(current))
+		}
+	}
+	c.unlock()
+
+	return c.reveal(entries)
+}
+
+// TopKeys returns the snapshots of up to n live elements with the largest number of hits in descending order. The
+// elements with the same number of hits are ordered by recency
+func (c *cache) TopKeys(n int) []Entry {
+	return rank(c.Entries(), n, false)
+}
+
+// FrequentKeys returns the keys of up to n live elements with the largest number of hits in descending order, the
+// elements with the same number of hits are ordered by recency. Unlike TopKeys, it doesn't take the snapshots of the
+// elements, only their keys
+func (c *cache) FrequentKeys(n int) []string {
+	c.lock()
+	defer c.unlock()
+
+	now := time.Now()
+	live := make([]*item, 0, c.chain.Len())
+	for current := c.chain.Front(); current != nil; current = c.chain.Next(current) {
+		if !c.expired(current, now) {
+			live = append(live, current)
+		}
+	}
+
+	sort.SliceStable(live, func(i, j int) bool {
+		return live[i].hits > live[j].hits
+	})
+
+	if n < 0 {
+		n = 0
+	}
+	if n > len(live) {
+		n = len(live)
+	}
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = live[i].key
+	}
+
+	return keys
+}
+
+// ColdestKeys returns the snapshots of up to n live elements with the smallest number of hits in ascending order. The
+// elements with the same number of hits are ordered from the least recently used one
+func (c *cache) ColdestKeys(n int) []Entry {
+	return rank(c.Entries(), n, true)
+}
+
+// rank sorts the entries ordered by recency by their hits and returns the first n of them
+func rank(entries []Entry, n int, coldest bool) []Entry {
+	if coldest {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if coldest {
+			return entries[i].Hits < entries[j].Hits
+		}
+		return entries[i].Hits > entries[j].Hits
+	})
+
+	if n < 0 {
+		n = 0
+	}
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+
+	return entries
+}
+
+// entry makes the snapshot of the element with the original value, for the callers which need it under the lock
+func (c *cache) entry(element *item) Entry {
+	entry := c.snapshot(element)
+	entry.Value = c.plain(entry.Value)
+
+	return entry
+}
+
+// reveal replaces the stored values of the snapshots taken under the lock by the original ones, so the values are
+// decompressed and decoded after the cache is unlocked
+func (c *cache) reveal(entries []Entry) []Entry {
+	if c.transforms() {
+		for i := range entries {
+			entries[i].Value = c.plain(entries[i].Value)
+		}
+	}
+
+	return entries
+}
+
+// snapshot makes the snapshot of the element with the stored form of the value, see reveal
+func (c *cache) snapshot(element *item) Entry {
+	return Entry{
+		Key:        element.key,
+		Value:      element.value,
+		Created:    element.creationTime,
+		Expiration: c.expiration(element),
+		TTL:        c.lifetimeOf(element),
+		LastAccess: element.lastAccess,
+		Hits:       element.hits,
+		Version:    element.version,
+		Meta:       element.meta,
+	}
+}
+
+// lifetimeOf returns the ttl of the element, or zero if it doesn't expire by ttl
+func (c *cache) lifetimeOf(element *item) time.Duration {
+	if c.adaptive != nil {
+		return c.adaptive.lifetime(element.hits)
+	}
+
+	return c.lifetime
+}
+
+// expiration returns the moment when the lifetime of the element comes to an end, or zero if it never expires
+func (c *cache) expiration(element *item) time.Time {
+	expiration := element.deadline
+	if lifetime := c.lifetimeOf(element); lifetime != 0 {
+		expiration = earliest(expiration, element.creationTime.Add(lifetime))
+	}
+	if c.idle != 0 {
+		expiration = earliest(expiration, lastUse(element).Add(c.idle))
+	}
+	if c.maxAge != 0 {
+		expiration = earliest(expiration, element.bornTime.Add(c.maxAge))
+	}
+
+	return expiration
+}
+
+// earliest returns the earlier of the moments, a zero moment means there is none
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+
+	return a
+}
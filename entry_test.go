@@ -0,0 +1,85 @@
+package golru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntry(t *testing.T) {
+	c, err := NewCache(3, WithTTL(60))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Add("new", 43)
+	c.Get("test")
+	c.Get("test")
+
+	entry, ok := c.GetEntry("test")
+	require.True(t, ok)
+	require.Equal(t, "test", entry.Key)
+	require.Equal(t, 42, entry.Value)
+	require.Equal(t, uint64(2), entry.Hits)
+	require.False(t, entry.LastAccess.Before(entry.Created))
+	require.Equal(t, entry.Created.Add(time.Minute), entry.Expiration)
+
+	entry, ok = c.GetEntry("new")
+	require.True(t, ok)
+	require.Zero(t, entry.Hits)
+	require.True(t, entry.LastAccess.IsZero())
+
+	_, ok = c.GetEntry("missing")
+	require.False(t, ok)
+	require.Equal(t, uint64(2), c.Stats().Hits)
+}
+
+func TestEntries(t *testing.T) {
+	c, err := NewCache(3)
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	c.Add("third", 3)
+	c.Get("first")
+
+	entries := c.Entries()
+	require.Len(t, entries, 3)
+	require.Equal(t, "first", entries[0].Key)
+	require.Equal(t, "third", entries[1].Key)
+	require.Equal(t, "second", entries[2].Key)
+	require.True(t, entries[0].Expiration.IsZero())
+	require.Equal(t, "first", c.(*cache).chain.Front().key)
+}
+
+func TestTopAndColdestKeys(t *testing.T) {
+	c, err := NewCache(4)
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	c.Add("third", 3)
+	c.Add("fourth", 4)
+	for i := 0; i < 3; i++ {
+		c.Get("second")
+	}
+	c.Get("third")
+	c.Get("first")
+
+	keys := func(entries []Entry) []string {
+		result := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, entry.Key)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"second", "first"}, keys(c.TopKeys(2)))
+	require.Equal(t, []string{"fourth", "third"}, keys(c.ColdestKeys(2)))
+	require.Len(t, c.TopKeys(10), 4)
+	require.Empty(t, c.ColdestKeys(0))
+
+	require.Equal(t, []string{"second", "first", "third"}, c.FrequentKeys(3))
+	require.Equal(t, keys(c.TopKeys(10)), c.FrequentKeys(10))
+	require.Empty(t, c.FrequentKeys(-1))
+}
@@ -0,0 +1,302 @@
+package golru
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultEvictQueue = 1024
+
+// OverflowPolicy decides what happens with the evicted element when the queue of the eviction workers is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the operation causing the eviction wait for the room in the queue outside the cache lock
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest queued element to make room for the new one
+	OverflowDropOldest
+	// OverflowDropNewest drops the new element
+	OverflowDropNewest
+	// OverflowReport drops the new element and logs a warning with the total number of the dropped elements
+	OverflowReport
+)
+
+// evictEvent is the evicted element waiting for the eviction callback
+type evictEvent struct {
+	key      string
+	value    interface{}
+	meta     interface{}
+	reason   EvictReason
+	callback bool
+	close    bool
+}
+
+// dispatcher runs the eviction callbacks on a bounded pool of workers. Workers are started on demand and exit as
+// soon as the queue is empty, so an idle cache keeps no goroutines. Pending counts the events not yet passed to the
+// callback or dropped, idle is closed and forgotten when it reaches zero to wake up Drain, and ids are the goroutines
+// of the running workers, all of them guarded by mu
+type dispatcher struct {
+	queue    chan evictEvent
+	workers  int32
+	running  int32
+	dropped  uint64
+	overflow OverflowPolicy
+	mu       sync.Mutex
+	pending  int
+	idle     chan struct{}
+	ids      map[uint64]struct{}
+	run      func(evictEvent)
+	report   func(dropped uint64)
+}
+
+// WithEvictWorkers makes the cache run the eviction callbacks on a pool of up to the given number of goroutines
+// instead of the goroutine of the operation which caused the eviction. Queue is the number of the evicted elements
+// waiting for a free worker, 1024 by default. When it is full, the operation causing the eviction waits for the room
+// in the queue, but never under the cache lock. Use Drain to wait for the outstanding callbacks
+func WithEvictWorkers(workers int, queue int) CacheOption {
+	return func(cache *cache) {
+		if workers <= 0 {
+			cache.dispatcher = nil
+			return
+		}
+		if queue <= 0 {
+			queue = defaultEvictQueue
+		}
+		cache.dispatcher = &dispatcher{queue: make(chan evictEvent, queue), workers: int32(workers)}
+	}
+}
+
+// WithEvictOverflow sets the policy for the evicted elements which don't fit into the queue of the eviction workers.
+// The dropped elements never reach the eviction callback and are counted in Stats. It works only together with
+// WithEvictWorkers, OverflowBlock is used by default
+func WithEvictOverflow(policy OverflowPolicy) CacheOption {
+	return func(cache *cache) {
+		cache.overflow = policy
+	}
+}
+
+// Drain waits until all the evicted elements queued so far are passed to the eviction callback. Returns the error of
+// the context if it is done earlier. Without the eviction workers the callbacks are called synchronously, and there
+// is nothing to wait for
+func (c *cache) Drain(ctx context.Context) error {
+	if c.dispatcher == nil {
+		return nil
+	}
+
+	select {
+	case <-c.dispatcher.drained():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// queueEviction remembers the evicted element to pass it to the callback after the cache is unlocked
+func (c *cache) queueEviction(element *item, reason EvictReason) {
+	c.queueEvent(evictEvent{key: element.key, value: element.value, meta: element.meta, reason: reason,
+		callback: c.evictCallbacks(), close: c.autoClose})
+}
+
+// queueEvent remembers the event if there is anything to do with it after the cache is unlocked
+func (c *cache) queueEvent(event evictEvent) {
+	if !event.callback && !event.close {
+		return
+	}
+
+	c.evicted = append(c.evicted, event)
+}
+
+// notify passes the evicted elements to the callback, directly or through the workers. The cache must be unlocked
+func (c *cache) notify(events []evictEvent) {
+	if c.dispatcher == nil {
+		for _, event := range events {
+			c.callOnEvict(event)
+		}
+		return
+	}
+
+	for _, event := range events {
+		c.dispatcher.dispatch(event)
+	}
+}
+
+// callOnEvict calls the eviction callback for a single element and closes its value if WithAutoClose is set
+func (c *cache) callOnEvict(event evictEvent) {
+	value := c.plain(event.value)
+	if event.callback && c.onEvict != nil {
+		c.safely(callbackOnEvict, event.key, func() {
+			c.onEvict(event.key, value, event.reason)
+		})
+	}
+	if event.callback && c.onEvictMeta != nil {
+		c.safely(callbackOnEvict, event.key, func() {
+			c.onEvictMeta(event.key, value, event.meta, event.reason)
+		})
+	}
+	if event.close {
+		c.closeValue(event.key, value)
+	}
+}
+
+// drained returns the channel closed as soon as there are no pending events
+func (d *dispatcher) drained() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	if d.pending == 0 {
+		close(d.idle)
+		d.idle = nil
+	}
+
+	return idle
+}
+
+// add counts the event about to be queued
+func (d *dispatcher) add() {
+	d.mu.Lock()
+	d.pending++
+	d.mu.Unlock()
+}
+
+// done counts the event passed to the callback or dropped and wakes up Drain when nothing is pending
+func (d *dispatcher) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending--
+	if d.pending == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// dispatch puts the event to the queue and makes sure there is a worker to process it. If the queue is full, the
+// event is handled according to the overflow policy. A worker whose callback causes another eviction never waits for
+// the room in the full queue, as it may be the only one to make it, and runs the callback for the new event itself
+func (d *dispatcher) dispatch(event evictEvent) {
+	d.add()
+
+	select {
+	case d.queue <- event:
+		d.spawn()
+		return
+	default:
+	}
+
+	d.spawn()
+	switch d.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case d.queue <- event:
+				return
+			default:
+			}
+
+			select {
+			case <-d.queue:
+				d.drop()
+			default:
+			}
+		}
+	case OverflowDropNewest, OverflowReport:
+		d.drop()
+		if d.overflow == OverflowReport && d.report != nil {
+			d.report(atomic.LoadUint64(&d.dropped))
+		}
+	default:
+		if d.worker() {
+			d.run(event)
+			d.done()
+			return
+		}
+		d.queue <- event
+	}
+}
+
+// drop counts the event which won't reach the callback
+func (d *dispatcher) drop() {
+	atomic.AddUint64(&d.dropped, 1)
+	d.done()
+}
+
+// worker tells whether the current goroutine is one of the workers of the dispatcher
+func (d *dispatcher) worker() bool {
+	id := goroutineID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.ids[id]
+	return ok
+}
+
+// goroutineID returns the id of the current goroutine from the header of its stack trace, "goroutine 42 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i >= 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
+// spawn starts a new worker if the limit allows it
+func (d *dispatcher) spawn() {
+	for {
+		running := atomic.LoadInt32(&d.running)
+		if running >= d.workers {
+			return
+		}
+		if atomic.CompareAndSwapInt32(&d.running, running, running+1) {
+			go d.work()
+			return
+		}
+	}
+}
+
+// work processes the queue until it is empty. Before exiting the worker checks the queue once more, as an event
+// could be queued while the number of workers was at the limit
+func (d *dispatcher) work() {
+	id := goroutineID()
+	d.mu.Lock()
+	if d.ids == nil {
+		d.ids = make(map[uint64]struct{})
+	}
+	d.ids[id] = struct{}{}
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.ids, id)
+		d.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case event := <-d.queue:
+			d.run(event)
+			d.done()
+			continue
+		default:
+		}
+
+		atomic.AddInt32(&d.running, -1)
+		if len(d.queue) == 0 {
+			return
+		}
+
+		running := atomic.LoadInt32(&d.running)
+		if running >= d.workers || !atomic.CompareAndSwapInt32(&d.running, running, running+1) {
+			return
+		}
+	}
+}
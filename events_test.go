@@ -0,0 +1,172 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnEvictOutsideLock(t *testing.T) {
+	var c Cacher
+	var lengths []int
+	c, err := NewCache(1, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		lengths = append(lengths, c.Len())
+		_, ok := c.Get(key)
+		require.False(t, ok)
+	}))
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	require.Equal(t, []int{1}, lengths)
+	require.NoError(t, c.Drain(context.Background()))
+}
+
+func TestEvictWorkers(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []string
+	var running, maxRunning int32
+	release := make(chan struct{})
+
+	c, err := NewCache(1, WithEvictWorkers(2, 4), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+
+		<-release
+		mu.Lock()
+		evicted = append(evicted, key)
+		mu.Unlock()
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+	require.Equal(t, 1, c.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.Drain(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, c.Drain(context.Background()))
+	require.ElementsMatch(t, []string{"test0", "test1", "test2", "test3"}, evicted)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&c.(*cache).dispatcher.running) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestEvictOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		policy  OverflowPolicy
+		evicted []string
+	}{
+		{name: "drop oldest", policy: OverflowDropOldest, evicted: []string{"test0", "test2", "test3"}},
+		{name: "drop newest", policy: OverflowDropNewest, evicted: []string{"test0", "test1", "test2"}},
+		{name: "report", policy: OverflowReport, evicted: []string{"test0", "test1", "test2"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var evicted []string
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			c, err := NewCache(1, WithEvictWorkers(1, 2), WithEvictOverflow(tc.policy),
+				WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+					if key == "test0" {
+						close(started)
+						<-release
+					}
+					mu.Lock()
+					evicted = append(evicted, key)
+					mu.Unlock()
+				}))
+			require.NoError(t, err)
+
+			c.Add("test0", 0)
+			c.Add("test1", 1)
+			<-started
+			for i := 2; i < 5; i++ {
+				c.Add("test"+strconv.Itoa(i), i)
+			}
+
+			close(release)
+			require.NoError(t, c.Drain(context.Background()))
+			require.Equal(t, tc.evicted, evicted)
+			require.Equal(t, uint64(1), c.Stats().DroppedEvictions)
+		})
+	}
+}
+
+func TestEvictOverflowBlockNested(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []string
+	var c Cacher
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	c, err := NewCache(1, WithEvictWorkers(1, 1), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		if key == "test0" {
+			close(started)
+			<-release
+			c.Add("nested", 0)
+		}
+		mu.Lock()
+		evicted = append(evicted, key)
+		mu.Unlock()
+	}))
+	require.NoError(t, err)
+
+	c.Add("test0", 0)
+	c.Add("test1", 1)
+	<-started
+	c.Add("test2", 2)
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, c.Drain(ctx))
+	require.ElementsMatch(t, []string{"test0", "test1", "test2"}, evicted)
+	_, ok := c.Get("nested")
+	require.True(t, ok)
+}
+
+func TestDrainConcurrent(t *testing.T) {
+	c, err := NewCache(1, WithEvictWorkers(2, 4), WithOnEvict(func(string, interface{}, EvictReason) {}))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				c.Add(strconv.Itoa(i)+":"+strconv.Itoa(j), j)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				require.NoError(t, c.Drain(context.Background()))
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, c.Drain(context.Background()))
+	require.Zero(t, c.(*cache).dispatcher.pending)
+}
@@ -0,0 +1,113 @@
+package golru
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+	"runtime/metrics"
+)
+
+const (
+	defaultGCGrowth = 0.5
+	defaultGCDrop   = 0.1
+)
+
+var heapLiveSamples = []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+
+// GCConfig describes the reaction of the cache to the growth of the heap. After every garbage collection the live
+// heap is compared with the one after the previous collection, and if it has grown by more than Growth (0.5 by
+// default, which is 50%), the cache drops Drop of its coldest elements (0.1 by default). The heap smaller than MinHeap
+// is never considered growing. HeapLive allows you to replace the source of the heap size, by default it is the live
+// heap marked by the last collection
+type GCConfig struct {
+	Growth   float64
+	Drop     float64
+	MinHeap  uint64
+	HeapLive func() uint64
+}
+
+// gcHook is the object collected by every garbage collection, its finalizer reports the collection and arms the hook
+// again until the watching is stopped
+type gcHook struct {
+	done   <-chan struct{}
+	signal chan<- struct{}
+}
+
+// WatchGC starts watching the garbage collections and drops the tail of the cache with the EvictedByPressure reason
+// when the heap grows too fast between them, before the hard limits are hit. Unlike WatchMemory, it doesn't lower the
+// capacity, so the cache fills up again as soon as the heap allows it. Watching stops when the context is done
+func (c *cache) WatchGC(ctx context.Context, cfg GCConfig) error {
+	if cfg.Growth <= 0 {
+		cfg.Growth = defaultGCGrowth
+	}
+	if cfg.Drop <= 0 || cfg.Drop >= 1 {
+		cfg.Drop = defaultGCDrop
+	}
+	if cfg.HeapLive == nil {
+		cfg.HeapLive = heapLive
+	}
+
+	signal := make(chan struct{}, 1)
+	previous := cfg.HeapLive()
+	c.background(ctx, "gc", func(ctx context.Context) {
+		armGCHook(ctx.Done(), signal)
+		for {
+			select {
+			case <-signal:
+				heap := cfg.HeapLive()
+				if heap >= cfg.MinHeap && float64(heap) > float64(previous)*(1+cfg.Growth) {
+					c.dropCold(cfg.Drop, heap, previous)
+				}
+				previous = heap
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// armGCHook creates the object whose finalizer is run by the next garbage collection
+func armGCHook(done <-chan struct{}, signal chan<- struct{}) {
+	runtime.SetFinalizer(&gcHook{done: done, signal: signal}, func(h *gcHook) {
+		select {
+		case <-h.done:
+			return
+		default:
+		}
+
+		select {
+		case h.signal <- struct{}{}:
+		default:
+		}
+		armGCHook(h.done, h.signal)
+	})
+}
+
+// dropCold evicts the given share of the least recently used elements, skipping the leased ones
+func (c *cache) dropCold(share float64, heap, previous uint64) {
+	c.lock()
+	defer c.unlock()
+
+	dropped := 0
+	for n := int(math.Ceil(float64(c.chain.Len()) * share)); dropped < n; dropped++ {
+		victim := c.tail()
+		if victim == nil {
+			break
+		}
+		c.evict(victim, EvictedByPressure)
+	}
+	c.log(slog.LevelWarn, "golru: heap is growing fast, dropping the cold elements", "dropped", dropped,
+		"heap", heap, "previous", previous)
+}
+
+// heapLive returns the live heap marked by the last garbage collection
+func heapLive() uint64 {
+	samples := make([]metrics.Sample, len(heapLiveSamples))
+	copy(samples, heapLiveSamples)
+	metrics.Read(samples)
+
+	return samples[0].Value.Uint64()
+}
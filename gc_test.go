@@ -0,0 +1,70 @@
+package golru
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchGCDropsColdElements(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	for i := 0; i < 10; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+	_, ok := c.Get("test0")
+	require.True(t, ok)
+
+	var heap uint64 = 100
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, c.WatchGC(ctx, GCConfig{
+		Drop:     0.2,
+		MinHeap:  50,
+		HeapLive: func() uint64 { return atomic.LoadUint64(&heap) },
+	}))
+
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 10, c.Len())
+
+	atomic.StoreUint64(&heap, 200)
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return c.Len() == 8
+	}, time.Second, 5*time.Millisecond)
+
+	_, ok = c.Get("test1")
+	require.False(t, ok)
+	_, ok = c.Get("test0")
+	require.True(t, ok)
+	require.Equal(t, uint32(10), tc.capacity)
+	require.Equal(t, uint64(2), c.Stats().Evictions)
+
+	runtime.GC()
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 8, c.Len())
+}
+
+func TestWatchGCStops(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, c.WatchGC(ctx, GCConfig{}))
+	require.Equal(t, 1, c.Health().Background["gc"])
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return c.Health().Background["gc"] == 0
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,45 @@
+package golru
+
+import "time"
+
+// CostFunc returns the cost of getting the value again after it is evicted, like the time or the money spent by its
+// source, in any units common to all the values
+type CostFunc func(key string, value interface{}) float64
+
+// WithRetrievalCost sets the function giving the cost of getting the values again for PolicyGDSF. Without it all the
+// values cost the same, and the policy prefers the small and frequently used ones. The function is called on every
+// addition and access of the element, so it should be cheap
+func WithRetrievalCost(fn CostFunc) CacheOption {
+	return func(cache *cache) {
+		cache.retrievalCost = fn
+	}
+}
+
+// prioritize sets the priority of the element by PolicyGDSF: the inflation of the cache plus its frequency counter plus
+// one for the addition, multiplied by its retrieval cost and divided by its size. The size includes the key and the
+// item itself, so it is never zero
+func (c *cache) prioritize(element *item) {
+	if c.policy != PolicyGDSF {
+		return
+	}
+
+	cost := 1.0
+	if c.retrievalCost != nil {
+		cost = c.retrievalCost(element.key, element.value)
+	}
+	size := float64(c.valueBytes(element) + uint64(len(element.key)) + itemBytes)
+	element.priority = c.inflation + float64(c.frequency(element)+1)*cost/size
+}
+
+// gdsfVictim returns the element with the lowest priority out of the random sample and raises the inflation to its
+// priority, so the elements used long ago age compared with the ones used since then
+func (c *cache) gdsfVictim() *item {
+	victim := c.sampledVictim(func(element *item, _ time.Time) float64 {
+		return element.priority
+	})
+	if victim != nil && victim.priority > c.inflation {
+		c.inflation = victim.priority
+	}
+
+	return victim
+}
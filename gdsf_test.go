@@ -0,0 +1,62 @@
+package golru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGDSFPrefersSmallValues(t *testing.T) {
+	c, err := NewCache(3, WithEvictionPolicy(PolicyGDSF), WithEvictionSamples(100))
+	require.NoError(t, err)
+
+	c.Add("big", make([]byte, 10000))
+	c.Add("mid", make([]byte, 1000))
+	c.Add("small", make([]byte, 10))
+	c.Add("new", make([]byte, 10))
+
+	_, ok := c.GetEntry("big")
+	require.False(t, ok)
+	require.Greater(t, c.(*cache).inflation, 0.0)
+}
+
+func TestGDSFRetrievalCost(t *testing.T) {
+	c, err := NewCache(3, WithEvictionPolicy(PolicyGDSF), WithEvictionSamples(100),
+		WithRetrievalCost(func(key string, value interface{}) float64 {
+			if key == "big" {
+				return 1000
+			}
+			return 1
+		}))
+	require.NoError(t, err)
+
+	c.Add("big", make([]byte, 10000))
+	c.Add("mid", make([]byte, 1000))
+	c.Add("small", make([]byte, 10))
+	c.Add("new", make([]byte, 10))
+
+	_, ok := c.GetEntry("big")
+	require.True(t, ok)
+	_, ok = c.GetEntry("mid")
+	require.False(t, ok)
+}
+
+func TestGDSFFrequency(t *testing.T) {
+	c, err := NewCache(2, WithEvictionPolicy(PolicyGDSF), WithEvictionSamples(100))
+	require.NoError(t, err)
+
+	c.Add("used", "value")
+	c.Add("unused", "value")
+	for i := 0; i < 5; i++ {
+		c.Get("used")
+	}
+	c.Add("new", "value")
+
+	_, ok := c.GetEntry("used")
+	require.True(t, ok)
+	_, ok = c.GetEntry("unused")
+	require.False(t, ok)
+
+	c.Clear()
+	require.Zero(t, c.(*cache).inflation)
+}
@@ -0,0 +1,228 @@
+// Package gocachestore is the store of github.com/eko/gocache backed by golru, so the cache slots into the
+// applications built on that abstraction together with its marshaler and its chain of caches. It doesn't depend on
+// gocache: Store has the methods of its StoreInterface with the plain structs instead of the functional options, and
+// the adapter of lib/v4 is a few lines:
+//
+//	type golruStore struct{ *gocachestore.Store }
+//
+//	func (s golruStore) Get(ctx context.Context, key any) (any, error) {
+//		value, err := s.Store.Get(ctx, key)
+//		if errors.Is(err, gocachestore.ErrNotFound) {
+//			return nil, store.NotFoundWithCause(err)
+//		}
+//		return value, err
+//	}
+//
+//	func (s golruStore) Set(ctx context.Context, key, value any, options ...store.Option) error {
+//		o := store.ApplyOptions(options...)
+//		return s.Store.Set(ctx, key, value, gocachestore.Options{Expiration: o.Expiration, Tags: o.Tags})
+//	}
+//
+//	func (s golruStore) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+//		o := store.ApplyInvalidateOptions(options...)
+//		return s.Store.Invalidate(ctx, gocachestore.InvalidateOptions{Tags: o.Tags})
+//	}
+//
+// GetWithTTL is wrapped the same way as Get. The chain of caches uses the ttl it returns to fill the upper caches, and
+// the marshaler stores the encoded bytes as any other value
+package gocachestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// Type is the type of the store returned by GetType
+const Type = "golru"
+
+// tagPrefix prefixes the keys of the elements keeping the keys of the tags, as the stores of gocache do
+const tagPrefix = "gocache_tag_"
+
+var ErrNotFound = errors.New("value not found in golru store")
+
+// Cache is the part of the cache used by the store, golru.Cacher satisfies it
+type Cache interface {
+	AddE(key string, value interface{}) error
+	AddWithDeadline(key string, value interface{}, deadline time.Time) error
+	GetE(key string) (interface{}, error)
+	GetEntry(key string) (golru.Entry, bool)
+	RemoveE(key string) error
+	Update(key string, fn golru.UpdateFunc) (interface{}, error)
+	Clear()
+}
+
+// Options are the options of Set. Expiration is the lifetime of the element, the ttl of the cache applies without it.
+// Tags are the tags the element is invalidated by
+type Options struct {
+	Expiration time.Duration
+	Tags       []string
+}
+
+// InvalidateOptions are the options of Invalidate. Tags are the tags whose elements are removed
+type InvalidateOptions struct {
+	Tags []string
+}
+
+// Store is the store of gocache backed by the cache
+type Store struct {
+	cache Cache
+}
+
+// New creates the store backed by the cache
+func New(cache Cache) *Store {
+	return &Store{cache: cache}
+}
+
+// Get returns the value of the key, or ErrNotFound if there is no such key or it has expired
+func (s *Store) Get(_ context.Context, key any) (any, error) {
+	value, err := s.cache.GetE(keyOf(key))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return value, nil
+}
+
+// GetWithTTL works like Get and also returns the remaining lifetime of the element, zero if it never expires
+func (s *Store) GetWithTTL(_ context.Context, key any) (any, time.Duration, error) {
+	k := keyOf(key)
+	value, err := s.cache.GetE(k)
+	if err != nil {
+		return nil, 0, notFound(err)
+	}
+
+	var ttl time.Duration
+	if entry, ok := s.cache.GetEntry(k); ok && !entry.Expiration.IsZero() {
+		if ttl = time.Until(entry.Expiration); ttl <= 0 {
+			return nil, 0, ErrNotFound
+		}
+	}
+
+	return value, ttl, nil
+}
+
+// Set puts the value replacing the existing one of the key, and remembers the key under every tag of the options. The
+// keys of a tag are kept in the cache as an element too, so they may be evicted as any other element, and then
+// Invalidate misses them
+func (s *Store) Set(_ context.Context, key, value any, opts Options) error {
+	k := keyOf(key)
+	var deadline time.Time
+	if opts.Expiration > 0 {
+		deadline = time.Now().Add(opts.Expiration)
+	}
+
+	if err := s.put(k, value, deadline); err != nil {
+		return err
+	}
+	for _, tag := range opts.Tags {
+		if err := s.tag(tag, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the key. The absent key isn't an error
+func (s *Store) Delete(_ context.Context, key any) error {
+	return s.remove(keyOf(key))
+}
+
+// Invalidate removes all the elements with the tags of the options
+func (s *Store) Invalidate(_ context.Context, opts InvalidateOptions) error {
+	for _, tag := range opts.Tags {
+		value, err := s.cache.GetE(tagPrefix + tag)
+		if err != nil {
+			continue
+		}
+		keys, _ := value.([]string)
+		for _, key := range keys {
+			if err = s.remove(key); err != nil {
+				return err
+			}
+		}
+		if err = s.remove(tagPrefix + tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear removes all the elements of the cache
+func (s *Store) Clear(_ context.Context) error {
+	s.cache.Clear()
+
+	return nil
+}
+
+// GetType returns the type of the store
+func (s *Store) GetType() string {
+	return Type
+}
+
+// put adds the value with the deadline, replacing the existing element of the key
+func (s *Store) put(key string, value interface{}, deadline time.Time) error {
+	for {
+		var err error
+		if deadline.IsZero() {
+			err = s.cache.AddE(key, value)
+		} else {
+			err = s.cache.AddWithDeadline(key, value, deadline)
+		}
+		if !errors.Is(err, golru.ErrKeyExists) {
+			return err
+		}
+		if err = s.remove(key); err != nil {
+			return err
+		}
+	}
+}
+
+// tag adds the key to the keys of the tag unless it is already there
+func (s *Store) tag(tag, key string) error {
+	_, err := s.cache.Update(tagPrefix+tag, func(old interface{}, exists bool) (interface{}, error) {
+		keys, _ := old.([]string)
+		for _, k := range keys {
+			if k == key {
+				return keys, nil
+			}
+		}
+		return append(keys[:len(keys):len(keys)], key), nil
+	})
+
+	return err
+}
+
+// remove removes the key, the absent or expired key isn't an error
+func (s *Store) remove(key string) error {
+	err := s.cache.RemoveE(key)
+	if errors.Is(err, golru.ErrKeyNotFound) || errors.Is(err, golru.ErrExpired) {
+		return nil
+	}
+
+	return err
+}
+
+// notFound turns the miss of the cache into ErrNotFound and keeps the other errors
+func notFound(err error) error {
+	if errors.Is(err, golru.ErrKeyNotFound) || errors.Is(err, golru.ErrExpired) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// keyOf returns the key of the cache for the key of gocache, which converts the keys other than strings to their
+// checksums before passing them to the store, so the rest are formatted in the Go syntax
+func keyOf(key any) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+
+	return fmt.Sprintf("%#v", key)
+}
@@ -0,0 +1,101 @@
+package gocachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) (*Store, golru.Cacher) {
+	c, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	return New(c), c
+}
+
+func TestSetGet(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newStore(t)
+
+	_, err := s.Get(ctx, "key")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Set(ctx, "key", "first", Options{}))
+	require.NoError(t, s.Set(ctx, "key", "second", Options{}))
+	value, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, "second", value)
+
+	require.NoError(t, s.Set(ctx, 42, []byte("answer"), Options{}))
+	value, err = s.Get(ctx, 42)
+	require.NoError(t, err)
+	require.Equal(t, []byte("answer"), value)
+
+	require.Equal(t, Type, s.GetType())
+}
+
+func TestGetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newStore(t)
+
+	require.NoError(t, s.Set(ctx, "forever", 1, Options{}))
+	_, ttl, err := s.GetWithTTL(ctx, "forever")
+	require.NoError(t, err)
+	require.Zero(t, ttl)
+
+	require.NoError(t, s.Set(ctx, "short", 2, Options{Expiration: time.Hour}))
+	value, ttl, err := s.GetWithTTL(ctx, "short")
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+	require.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	require.NoError(t, s.Set(ctx, "short", 3, Options{Expiration: 20 * time.Millisecond}))
+	time.Sleep(30 * time.Millisecond)
+	_, _, err = s.GetWithTTL(ctx, "short")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	s, c := newStore(t)
+
+	require.NoError(t, s.Set(ctx, "key", 1, Options{}))
+	require.NoError(t, s.Delete(ctx, "key"))
+	require.NoError(t, s.Delete(ctx, "key"))
+	require.Zero(t, c.Len())
+}
+
+func TestInvalidate(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newStore(t)
+
+	require.NoError(t, s.Set(ctx, "first", 1, Options{Tags: []string{"users"}}))
+	require.NoError(t, s.Set(ctx, "second", 2, Options{Tags: []string{"users", "admins"}}))
+	require.NoError(t, s.Set(ctx, "second", 2, Options{Tags: []string{"users"}}))
+	require.NoError(t, s.Set(ctx, "third", 3, Options{Tags: []string{"admins"}}))
+
+	require.NoError(t, s.Invalidate(ctx, InvalidateOptions{Tags: []string{"users", "missing"}}))
+	_, err := s.Get(ctx, "first")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, "second")
+	require.ErrorIs(t, err, ErrNotFound)
+	value, err := s.Get(ctx, "third")
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+
+	require.NoError(t, s.Invalidate(ctx, InvalidateOptions{Tags: []string{"admins"}}))
+	_, err = s.Get(ctx, "third")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+	s, c := newStore(t)
+
+	require.NoError(t, s.Set(ctx, "key", 1, Options{Tags: []string{"tag"}}))
+	require.NoError(t, s.Clear(ctx))
+	require.Zero(t, c.Len())
+}
@@ -0,0 +1,84 @@
+package golru
+
+import (
+	"strings"
+	"time"
+)
+
+// groupLinks link the element into the list of its group
+type groupLinks struct {
+	prev, next *item
+}
+
+// group is the intrusive list of the elements sharing a tenant, a caller or a prefix, kept in the order of the main
+// list, so the least recently used element of the group is found without walking the whole cache. Links returns the
+// links of the element in the lists of this kind of the groups
+type group struct {
+	front, back *item
+	links       func(it *item) *groupLinks
+}
+
+// pushFront links the element at the front of the group
+func (g *group) pushFront(it *item) {
+	l := g.links(it)
+	l.prev, l.next = nil, g.front
+	if g.front != nil {
+		g.links(g.front).prev = it
+	} else {
+		g.back = it
+	}
+	g.front = it
+}
+
+// remove excludes the element from the group
+func (g *group) remove(it *item) {
+	l := g.links(it)
+	if l.prev != nil {
+		g.links(l.prev).next = l.next
+	} else {
+		g.front = l.next
+	}
+	if l.next != nil {
+		g.links(l.next).prev = l.prev
+	} else {
+		g.back = l.prev
+	}
+	l.prev, l.next = nil, nil
+}
+
+// moveToFront moves the element to the front of the group after it is moved to the front of the main list
+func (g *group) moveToFront(it *item) {
+	if g.front != it {
+		g.remove(it)
+		g.pushFront(it)
+	}
+}
+
+// groupTail returns the last unpinned element of the group
+func (c *cache) groupTail(g *group) *item {
+	now := time.Now()
+	for current := g.back; current != nil; current = g.links(current).prev {
+		if current.leases == nil || !c.pinned(current, now) {
+			return current
+		}
+	}
+
+	return nil
+}
+
+// regroup moves the element to the front of its groups after it is moved to the front of the main list
+func (c *cache) regroup(element *item) {
+	if element.tenant != nil {
+		element.tenant.elements.moveToFront(element)
+	}
+	if element.caller != nil {
+		element.caller.elements.moveToFront(element)
+	}
+	if element.prefixLinks != nil {
+		for _, limit := range c.prefixes {
+			if strings.HasPrefix(element.key, limit.prefix) {
+				limit.elements.moveToFront(element)
+			}
+		}
+	}
+}
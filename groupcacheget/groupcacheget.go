@@ -0,0 +1,66 @@
+// Package groupcacheget is the getter of github.com/golang/groupcache backed by golru, so the values loaded by this
+// peer stay in the local cache of this process and the origin isn't asked for them again while they are there. It
+// doesn't depend on groupcache: Sink is the part of groupcache.Sink used by the getter, and the getter is put into the
+// group by a closure, for example the one wrapping the getter the group has used so far:
+//
+//	getter := groupcacheget.New(cache, func(ctx context.Context, key string) ([]byte, error) {
+//		var value []byte
+//		err := origin.Get(ctx, key, groupcache.AllocatingByteSliceSink(&value))
+//		return value, err
+//	})
+//
+//	group := groupcache.NewGroup("users", 64<<20, groupcache.GetterFunc(
+//		func(ctx context.Context, key string, dest groupcache.Sink) error {
+//			return getter.Get(ctx, key, dest)
+//		}))
+//
+// Groupcache calls the getter only for the keys this peer owns, once at a time for every key, and keeps the result in
+// its own main cache too, so golru adds what the group lacks, such as the lifetime of the values and the statistics,
+// and keeps the values the group has evicted by its limit of the bytes
+package groupcacheget
+
+import "context"
+
+// Sink is the part of groupcache.Sink used by the getter, groupcache.Sink satisfies it
+type Sink interface {
+	SetBytes(v []byte) error
+}
+
+// LoadFunc loads the value of the key from the origin on the miss of the cache
+type LoadFunc func(ctx context.Context, key string) ([]byte, error)
+
+// Cache is the part of the cache used by the getter, golru.Cacher satisfies it
+type Cache interface {
+	GetContext(ctx context.Context, key string) (interface{}, error)
+	AddContext(ctx context.Context, key string, value interface{}) error
+}
+
+// Getter fills the sinks of groupcache from the cache, loading the missing values from the origin
+type Getter struct {
+	cache Cache
+	load  LoadFunc
+}
+
+// New creates the getter of the cache loading the missing values by the function
+func New(cache Cache, load LoadFunc) *Getter {
+	return &Getter{cache: cache, load: load}
+}
+
+// Get sets the value of the key to the sink. The cached value is set as it is, as all the sinks of groupcache copy
+// the bytes. The missing value is loaded and added to the cache, and the failure to add it doesn't fail the Get. The
+// context is passed to the cache, so the key is scoped by WithKeyScope and the hooks see the caller
+func (g *Getter) Get(ctx context.Context, key string, dest Sink) error {
+	if value, err := g.cache.GetContext(ctx, key); err == nil {
+		if b, ok := value.([]byte); ok {
+			return dest.SetBytes(b)
+		}
+	}
+
+	value, err := g.load(ctx, key)
+	if err != nil {
+		return err
+	}
+	_ = g.cache.AddContext(ctx, key, value)
+
+	return dest.SetBytes(value)
+}
@@ -0,0 +1,82 @@
+package groupcacheget
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantKey is the key of the tenant in the context
+type tenantKey struct{}
+
+// byteSink copies the bytes like the sinks of groupcache
+type byteSink struct {
+	value []byte
+}
+
+func (s *byteSink) SetBytes(v []byte) error {
+	s.value = append([]byte(nil), v...)
+	return nil
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	c, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	loads := 0
+	g := New(c, func(ctx context.Context, key string) ([]byte, error) {
+		loads++
+		return []byte("value of " + key), nil
+	})
+
+	for i := 0; i < 3; i++ {
+		var sink byteSink
+		require.NoError(t, g.Get(ctx, "key", &sink))
+		require.Equal(t, "value of key", string(sink.value))
+	}
+	require.Equal(t, 1, loads)
+
+	value, ok := c.Get("key")
+	require.True(t, ok)
+	require.Equal(t, []byte("value of key"), value)
+}
+
+func TestGetLoadError(t *testing.T) {
+	ctx := context.Background()
+	c, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	errOrigin := errors.New("origin is down")
+	g := New(c, func(ctx context.Context, key string) ([]byte, error) {
+		return nil, errOrigin
+	})
+
+	var sink byteSink
+	require.ErrorIs(t, g.Get(ctx, "key", &sink), errOrigin)
+	require.Nil(t, sink.value)
+	require.Zero(t, c.Len())
+}
+
+func TestGetScoped(t *testing.T) {
+	c, err := golru.NewCache(10, golru.WithKeyScope(func(ctx context.Context) string {
+		scope, _ := ctx.Value(tenantKey{}).(string)
+		return scope
+	}))
+	require.NoError(t, err)
+
+	g := New(c, func(ctx context.Context, key string) ([]byte, error) {
+		return []byte(ctx.Value(tenantKey{}).(string)), nil
+	})
+
+	for _, tenant := range []string{"a", "b"} {
+		var sink byteSink
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		require.NoError(t, g.Get(ctx, "key", &sink))
+		require.Equal(t, tenant, string(sink.value))
+	}
+	require.Equal(t, 2, c.Len())
+}
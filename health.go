@@ -0,0 +1,67 @@
+package golru
+
+import (
+	"sync/atomic"
+)
+
+// Health describes the state of the cache and its background work, it is meant for the readiness and liveness checks of
+// the service. Background is the number of the running goroutines by their kind: expire, trim, tune, pressure, gc and
+// purge. Janitor tells whether the expiration goroutine is running. EvictQueue and EvictQueueCap are the length and the
+// size of the queue of the eviction workers, both are zero without them. Problems lists the reasons why the cache is
+// not healthy, it is empty for a healthy one
+type Health struct {
+	Closed           bool
+	Janitor          bool
+	Background       map[string]int
+	EvictQueue       int
+	EvictQueueCap    int
+	DroppedEvictions uint64
+	Len              int
+	Cap              int
+	Problems         []string
+}
+
+// Healthy reports whether there are no problems
+func (h Health) Healthy() bool {
+	return len(h.Problems) == 0
+}
+
+// Health returns the current state of the cache. The cache is not healthy when it is closed, when the elements can
+// expire but Expire isn't running, so the expired data stays in memory until it is accessed, when the soft capacity is
+// set but Trim isn't running, and when the queue of the eviction workers is full
+func (c *cache) Health() Health {
+	background := c.life.snapshot()
+
+	c.lock()
+	h := Health{
+		Closed:     c.closed,
+		Janitor:    background["expire"] > 0,
+		Background: background,
+		Len:        c.chain.Len(),
+		Cap:        int(c.capacity),
+	}
+	expiring, soft := c.expiring(), c.softCapacity != 0
+	c.unlock()
+
+	if c.dispatcher != nil {
+		h.EvictQueue, h.EvictQueueCap = len(c.dispatcher.queue), cap(c.dispatcher.queue)
+		h.DroppedEvictions = atomic.LoadUint64(&c.dispatcher.dropped)
+	}
+
+	switch {
+	case h.Closed:
+		h.Problems = append(h.Problems, "cache is closed")
+	default:
+		if expiring && !h.Janitor {
+			h.Problems = append(h.Problems, "expiration is not running")
+		}
+		if soft && background["trim"] == 0 {
+			h.Problems = append(h.Problems, "trimming to the soft capacity is not running")
+		}
+		if h.EvictQueueCap != 0 && h.EvictQueue == h.EvictQueueCap {
+			h.Problems = append(h.Problems, "eviction queue is full")
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,67 @@
+package golru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	c, err := NewCache(10, WithTTL(1), WithSoftCapacity(5, 1))
+	require.NoError(t, err)
+	c.Add("test", 1)
+
+	h := c.Health()
+	require.False(t, h.Healthy())
+	require.False(t, h.Janitor)
+	require.Len(t, h.Problems, 2)
+	require.Equal(t, 1, h.Len)
+	require.Equal(t, 10, h.Cap)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, c.Expire(ctx))
+	require.NoError(t, c.Trim(ctx))
+
+	h = c.Health()
+	require.True(t, h.Healthy(), h.Problems)
+	require.True(t, h.Janitor)
+	require.Equal(t, map[string]int{"expire": 1, "trim": 1}, h.Background)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return len(c.Health().Background) == 0
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Close())
+	h = c.Health()
+	require.True(t, h.Closed)
+	require.Equal(t, []string{"cache is closed"}, h.Problems)
+}
+
+func TestHealthEvictQueue(t *testing.T) {
+	release := make(chan struct{})
+	c, err := NewCache(1, WithEvictWorkers(1, 2), WithEvictOverflow(OverflowDropNewest),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			<-release
+		}))
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	require.Eventually(t, func() bool {
+		return c.Health().EvictQueue == 0
+	}, time.Second, time.Millisecond)
+	c.Add("third", 3)
+	c.Add("fourth", 4)
+
+	h := c.Health()
+	require.Equal(t, 2, h.EvictQueue)
+	require.Equal(t, 2, h.EvictQueueCap)
+	require.Equal(t, []string{"eviction queue is full"}, h.Problems)
+
+	close(release)
+	require.NoError(t, c.Drain(context.Background()))
+	require.True(t, c.Health().Healthy())
+}
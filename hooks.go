@@ -0,0 +1,316 @@
+package golru
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Op is the kind of the cache operation passed to the hooks
+type Op int
+
+const (
+	OpAdd Op = iota + 1
+	OpGet
+	OpRemove
+	OpChangeValue
+	OpClear
+	OpGetOrAdd
+	OpSwap
+	OpAddReturningEvicted
+	OpUpdate
+)
+
+// BeforeHook is called before the operation with the key
+type BeforeHook func(op Op, key string)
+
+// AfterHook is called after the operation with the key. Hit reports whether the operation succeeded: the key was
+// found by Get, Remove or ChangeValue, or the element was inserted by Add. D is the duration of the operation
+type AfterHook func(op Op, key string, hit bool, d time.Duration)
+
+// hooks keeps the functions called around the operations
+type hooks struct {
+	before BeforeHook
+	after  AfterHook
+}
+
+// WithHooks sets the functions called around every operation with a key: Add, Get, Remove and ChangeValue including
+// their variants, and around Clear, which gets an empty key. They are called outside the cache lock, so they can use
+// the cache. Either of them can be nil
+func WithHooks(before BeforeHook, after AfterHook) CacheOption {
+	return func(cache *cache) {
+		if before == nil && after == nil {
+			cache.hooks = nil
+			return
+		}
+		cache.hooks = &hooks{before: before, after: after}
+	}
+}
+
+// String returns the name of the operation
+func (o Op) String() string {
+	switch o {
+	case OpAdd:
+		return "add"
+	case OpGet:
+		return "get"
+	case OpRemove:
+		return "remove"
+	case OpChangeValue:
+		return "change_value"
+	case OpClear:
+		return "clear"
+	case OpGetOrAdd:
+		return "get_or_add"
+	case OpSwap:
+		return "swap"
+	case OpAddReturningEvicted:
+		return "add_returning_evicted"
+	case OpUpdate:
+		return "update"
+	default:
+		return "unknown"
+	}
+}
+
+// OpFunc runs the operation with the key. Value is the new value for all the operations adding or changing the
+// elements, and the result is the found value for Get, the previous value for Swap and the new version of the
+// element as uint64 for Add and ChangeValue. GetOrAdd results in the value in the cache after the operation, and
+// ErrKeyExists if it was already there. AddReturningEvicted results in the Entry of the evicted element, if any.
+// The value of Update is the UpdateFunc, and the result is the new value
+type OpFunc func(op Op, key string, value interface{}) (interface{}, error)
+
+// Middleware wraps the operation to add the behaviour before or after it, or instead of it
+type Middleware func(next OpFunc) OpFunc
+
+// WithMiddleware sets the chain of middleware wrapping every operation with a key and Clear. The first middleware is
+// the outermost one, and the last one calls the cache itself. The hooks, if set, surround the whole chain. Middleware
+// runs outside the cache lock and may return its own errors, which are passed to the caller of the E-variants
+func WithMiddleware(mw ...Middleware) CacheOption {
+	return func(cache *cache) {
+		cache.middleware = append(cache.middleware, mw...)
+	}
+}
+
+// buildPipeline joins the key normalizer, the trace recorder, the hooks, the key validator and the middleware into
+// the single function running the operations. Without them the operations go directly to exec. The middleware is the
+// innermost part, so the context of the operation is passed to all the others
+func (c *cache) buildPipeline() {
+	if len(c.middleware) == 0 && c.hooks == nil && c.ctxHooks == nil && c.tracer == nil && c.normalizer == nil &&
+		c.validator == nil && c.auditor == nil {
+		c.pipeline = nil
+		return
+	}
+
+	c.pipeline = c.compose(c.exec)
+}
+
+// compose wraps the function running the operations by the middleware, the hooks and the rest of the pipeline, see
+// buildPipeline
+func (c *cache) compose(exec OpFunc) ctxOpFunc {
+	core := exec
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		core = c.middleware[i](core)
+	}
+
+	next := ctxOpFunc(func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return core(op, key, value)
+	})
+	if c.validator != nil {
+		next = c.wrapValidator(next)
+	}
+	if c.ctxHooks != nil {
+		next = c.wrapContextHooks(next)
+	}
+	if c.hooks != nil {
+		next = c.wrapHooks(next)
+	}
+	if c.tracer != nil {
+		next = c.wrapTrace(next)
+	}
+	if c.auditor != nil {
+		next = c.wrapAudit(next)
+	}
+	if c.normalizer != nil {
+		next = c.wrapNormalizer(next)
+	}
+
+	return next
+}
+
+// wrapHooks surrounds the operation by the hooks
+func (c *cache) wrapHooks(next ctxOpFunc) ctxOpFunc {
+	h := c.hooks
+
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		if h.before != nil {
+			c.safely(callbackBeforeHook, key, func() {
+				h.before(op, key)
+			})
+		}
+
+		start := time.Now()
+		result, err := next(ctx, op, key, value)
+
+		if h.after != nil {
+			c.safely(callbackAfterHook, key, func() {
+				h.after(op, key, err == nil, time.Since(start))
+			})
+		}
+
+		return result, err
+	}
+}
+
+// do runs the operation through the middleware and the hooks if they are set
+func (c *cache) do(op Op, key string, value interface{}) (interface{}, error) {
+	return c.doContext(context.Background(), op, key, value)
+}
+
+// exec runs the operation under the cache lock. The values are copied, encoded and compressed outside it, if the
+// value copier, the codec or the compression is set
+func (c *cache) exec(op Op, key string, value interface{}) (interface{}, error) {
+	if !c.transforms() && len(c.indexes) == 0 {
+		return c.locked(op, key, value, nil)
+	}
+
+	var attrs map[string]string
+	switch op {
+	case OpAdd, OpChangeValue, OpGetOrAdd, OpSwap, OpAddReturningEvicted:
+		attrs = c.attributes(value)
+		var err error
+		if value, err = c.store(value); err != nil {
+			return nil, err
+		}
+	}
+	result, err := c.locked(op, key, value, attrs)
+	switch {
+	case (op == OpGet || op == OpSwap) && err == nil:
+		return c.load(result)
+	case op == OpAddReturningEvicted && result != nil:
+		return c.reveal([]Entry{result.(Entry)})[0], nil
+	case op == OpGetOrAdd && (err == nil || err == ErrKeyExists):
+		loaded, loadErr := c.load(result)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return loaded, err
+	}
+
+	return result, err
+}
+
+// transforms reports whether the stored values differ from the ones passed to the cache and returned by it
+func (c *cache) transforms() bool {
+	return c.copier != nil || c.codec != nil || c.compressor != nil
+}
+
+// store returns the form of the new value kept by the cache
+func (c *cache) store(value interface{}) (interface{}, error) {
+	if c.copier != nil && c.copyMode&CopyOnAdd != 0 {
+		value = c.copier(value)
+	}
+	if c.codec != nil {
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("golru: encode value: %w", err)
+		}
+		value = data
+	}
+	if c.compressor != nil {
+		value = c.compress(value)
+	}
+
+	return value, nil
+}
+
+// load returns the value handed out by Get from the stored one
+func (c *cache) load(value interface{}) (interface{}, error) {
+	value, err := c.decode(value)
+	if err != nil {
+		return nil, err
+	}
+	if c.copier != nil && c.copyMode&CopyOnGet != 0 {
+		value = c.copier(value)
+	}
+
+	return value, nil
+}
+
+// decode returns the original value from the stored one, decompressing and decoding it if needed
+func (c *cache) decode(value interface{}) (interface{}, error) {
+	if c.compressor != nil {
+		var err error
+		if value, err = c.decompress(value); err != nil {
+			return nil, err
+		}
+	}
+	if c.codec != nil {
+		data, ok := value.([]byte)
+		if !ok {
+			return value, nil
+		}
+		decoded, err := c.codec.Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("golru: decode value: %w", err)
+		}
+		value = decoded
+	}
+
+	return value, nil
+}
+
+// locked runs the operation under the cache lock. Attrs are the index attributes of the new value extracted outside it
+func (c *cache) locked(op Op, key string, value interface{}, attrs map[string]string) (interface{}, error) {
+	c.lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, ErrCacheClosed
+	}
+	c.stage(key, attrs)
+
+	switch op {
+	case OpAdd:
+		if err := c.add(key, value); err != nil {
+			return nil, err
+		}
+		c.countCompressed(value)
+		return c.version, nil
+	case OpGet:
+		return c.get(key)
+	case OpRemove:
+		return nil, c.remove(key)
+	case OpChangeValue:
+		if err := c.changeValue(key, value); err != nil {
+			return nil, err
+		}
+		c.countCompressed(value)
+		return c.version, nil
+	case OpClear:
+		c.clear()
+		return nil, nil
+	case OpGetOrAdd:
+		return c.getOrAdd(key, value)
+	case OpSwap:
+		return c.swap(key, value)
+	case OpUpdate:
+		return c.update(key, value.(UpdateFunc))
+	case OpAddReturningEvicted:
+		victim, evicted, err := c.addEvicting(key, value)
+		if err != nil {
+			return nil, err
+		}
+		c.countCompressed(value)
+		if !evicted {
+			return nil, nil
+		}
+		return victim, nil
+	default:
+		return nil, nil
+	}
+}
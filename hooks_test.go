@@ -0,0 +1,105 @@
+package golru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	type call struct {
+		op  Op
+		key string
+		hit bool
+	}
+
+	var before []call
+	var after []call
+	var c Cacher
+	c, err := NewCache(2, WithHooks(func(op Op, key string) {
+		before = append(before, call{op: op, key: key})
+	}, func(op Op, key string, hit bool, d time.Duration) {
+		require.GreaterOrEqual(t, d, time.Duration(0))
+		require.Equal(t, 1, c.Len())
+		after = append(after, call{op: op, key: key, hit: hit})
+	}))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	c.GetBytes([]byte("missing"))
+	c.ChangeValue("test", 43)
+	c.AddE("test", 44)
+
+	require.Equal(t, []call{
+		{op: OpAdd, key: "test"},
+		{op: OpGet, key: "test"},
+		{op: OpGet, key: "missing"},
+		{op: OpChangeValue, key: "test"},
+		{op: OpAdd, key: "test"},
+	}, before)
+	require.Equal(t, []call{
+		{op: OpAdd, key: "test", hit: true},
+		{op: OpGet, key: "test", hit: true},
+		{op: OpGet, key: "missing", hit: false},
+		{op: OpChangeValue, key: "test", hit: true},
+		{op: OpAdd, key: "test", hit: false},
+	}, after)
+	require.Equal(t, "change_value", OpChangeValue.String())
+}
+
+func TestHooksOnlyAfter(t *testing.T) {
+	var ops []Op
+	c, err := NewCache(2, WithHooks(nil, func(op Op, key string, hit bool, d time.Duration) {
+		ops = append(ops, op)
+	}))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Remove("test")
+	require.Equal(t, []Op{OpAdd, OpRemove}, ops)
+}
+
+func TestMiddleware(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next OpFunc) OpFunc {
+			return func(op Op, key string, value interface{}) (interface{}, error) {
+				order = append(order, name+" "+op.String())
+				return next(op, key, value)
+			}
+		}
+	}
+	readOnly := func(next OpFunc) OpFunc {
+		return func(op Op, key string, value interface{}) (interface{}, error) {
+			if op == OpRemove {
+				return nil, ErrRejected
+			}
+			return next(op, key, value)
+		}
+	}
+
+	var hooked []Op
+	c, err := NewCache(2, WithMiddleware(trace("outer"), trace("inner")), WithMiddleware(readOnly),
+		WithHooks(nil, func(op Op, key string, hit bool, d time.Duration) {
+			hooked = append(hooked, op)
+		}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddE("test", 42))
+	require.ErrorIs(t, c.RemoveE("test"), ErrRejected)
+	require.False(t, c.RemoveBytes([]byte("test")))
+
+	value, ok := c.Get("test")
+	require.True(t, ok)
+	require.Equal(t, 42, value)
+
+	require.Equal(t, []string{
+		"outer add", "inner add",
+		"outer remove", "inner remove",
+		"outer remove", "inner remove",
+		"outer get", "inner get",
+	}, order)
+	require.Equal(t, []Op{OpAdd, OpRemove, OpRemove, OpGet}, hooked)
+}
@@ -0,0 +1,133 @@
+package golru
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	defaultHotWindow  = time.Second
+	defaultHotMaxKeys = 10000
+)
+
+// HotKeyConfig describes the detection of the hot keys. A key becomes hot when it is read Threshold times within a
+// single Window, 1s by default, whether the reads hit or miss. Up to MaxKeys distinct keys are counted in a window,
+// 10000 by default, the keys read first after it is full aren't counted till the next window. OnHot is called once
+// per window for every key that became hot, outside the cache lock
+type HotKeyConfig struct {
+	Threshold uint64
+	Window    time.Duration
+	MaxKeys   int
+	OnHot     func(key string, reads uint64)
+}
+
+// HotKey is the key read at least the threshold number of times within the current window
+type HotKey struct {
+	Key   string
+	Reads uint64
+}
+
+// hotKeys counts the reads of the keys within the current window
+type hotKeys struct {
+	cfg    HotKeyConfig
+	start  time.Time
+	counts map[string]uint64
+}
+
+// hotEvent is the key which became hot waiting for the callback
+type hotEvent struct {
+	key   string
+	reads uint64
+}
+
+// WithHotKeys enables the detection of the keys read too often, which is the early warning of the key whose expiration
+// would flood the source of the values. Every detection is counted in the HotKeys of Stats, and HotKeys lists the keys
+// hot in the current window
+func WithHotKeys(cfg HotKeyConfig) CacheOption {
+	return func(cache *cache) {
+		if cfg.Threshold == 0 {
+			cache.hot = nil
+			return
+		}
+		if cfg.Window <= 0 {
+			cfg.Window = defaultHotWindow
+		}
+		if cfg.MaxKeys <= 0 {
+			cfg.MaxKeys = defaultHotMaxKeys
+		}
+		cache.hot = &hotKeys{cfg: cfg, counts: make(map[string]uint64)}
+	}
+}
+
+// HotKeys returns the keys hot in the current window from the most read one, or nil without WithHotKeys
+func (c *cache) HotKeys() []HotKey {
+	c.lock()
+	defer c.unlock()
+
+	if c.hot == nil || time.Since(c.hot.start) >= c.hot.cfg.Window {
+		return nil
+	}
+
+	var keys []HotKey
+	for key, reads := range c.hot.counts {
+		if reads >= c.hot.cfg.Threshold {
+			keys = append(keys, HotKey{Key: key, Reads: reads})
+		}
+	}
+	sortHotKeys(keys)
+
+	return keys
+}
+
+// sortHotKeys orders the hot keys from the most read one, by the key among equals
+func sortHotKeys(keys []HotKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Reads != keys[j].Reads {
+			return keys[i].Reads > keys[j].Reads
+		}
+		return keys[i].Key < keys[j].Key
+	})
+}
+
+// observeHot counts the read of the key and queues the callback if the key has just became hot
+func (c *cache) observeHot(key string) {
+	if c.hot == nil {
+		return
+	}
+
+	reads, became := c.hot.read(key, time.Now())
+	if !became {
+		return
+	}
+	c.stats.HotKeys++
+	if c.hot.cfg.OnHot != nil {
+		c.hotEvents = append(c.hotEvents, hotEvent{key: key, reads: reads})
+	}
+}
+
+// read counts the read of the key at the moment now starting a new window if the current one is over, and reports
+// whether the key has just reached the threshold
+func (h *hotKeys) read(key string, now time.Time) (uint64, bool) {
+	if now.Sub(h.start) >= h.cfg.Window {
+		h.start = now
+		h.counts = make(map[string]uint64)
+	}
+
+	reads, ok := h.counts[key]
+	if !ok && len(h.counts) >= h.cfg.MaxKeys {
+		return 0, false
+	}
+	reads++
+	h.counts[key] = reads
+
+	return reads, reads == h.cfg.Threshold
+}
+
+// notifyHot calls the hot key callback for the queued keys. The cache must be unlocked
+func (c *cache) notifyHot(events []hotEvent) {
+	for _, event := range events {
+		c.safely(callbackOnHot, event.key, func() {
+			c.hot.cfg.OnHot(event.key, event.reads)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package golru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotKeys(t *testing.T) {
+	var mu sync.Mutex
+	var hot []HotKey
+	var c Cacher
+	c, err := NewCache(10, WithHotKeys(HotKeyConfig{Threshold: 3, Window: time.Hour,
+		OnHot: func(key string, reads uint64) {
+			c.Len()
+			mu.Lock()
+			hot = append(hot, HotKey{Key: key, Reads: reads})
+			mu.Unlock()
+		}}))
+	require.NoError(t, err)
+
+	c.Add("cached", 1)
+	for i := 0; i < 5; i++ {
+		c.Get("cached")
+		c.Get("missing")
+	}
+	c.Get("cold")
+
+	require.Equal(t, []HotKey{{Key: "cached", Reads: 3}, {Key: "missing", Reads: 3}}, hot)
+	require.Equal(t, uint64(2), c.Stats().HotKeys)
+	require.Equal(t, []HotKey{{Key: "cached", Reads: 5}, {Key: "missing", Reads: 5}}, c.HotKeys())
+}
+
+func TestHotKeysGetBytes(t *testing.T) {
+	c, err := NewCache(10, WithHotKeys(HotKeyConfig{Threshold: 2, Window: time.Hour}))
+	require.NoError(t, err)
+
+	c.Add("cached", 1)
+	for i := 0; i < 5; i++ {
+		c.GetBytes([]byte("cached"))
+	}
+
+	require.Equal(t, uint64(1), c.Stats().HotKeys)
+	require.Equal(t, []HotKey{{Key: "cached", Reads: 5}}, c.HotKeys())
+}
+
+func TestHotKeysWindow(t *testing.T) {
+	c, err := NewCache(10, WithHotKeys(HotKeyConfig{Threshold: 2, Window: 20 * time.Millisecond}))
+	require.NoError(t, err)
+
+	c.Get("key")
+	time.Sleep(30 * time.Millisecond)
+	c.Get("key")
+	require.Empty(t, c.HotKeys())
+
+	c.Get("key")
+	require.Len(t, c.HotKeys(), 1)
+	time.Sleep(30 * time.Millisecond)
+	require.Empty(t, c.HotKeys())
+}
+
+func TestHotKeysLimit(t *testing.T) {
+	c, err := NewCache(10, WithHotKeys(HotKeyConfig{Threshold: 2, Window: time.Hour, MaxKeys: 3}))
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		c.Get(strconv.Itoa(i))
+		c.Get(strconv.Itoa(i))
+	}
+	require.Len(t, c.HotKeys(), 3)
+	require.Equal(t, uint64(3), c.Stats().HotKeys)
+}
+
+func TestShardedHotKeys(t *testing.T) {
+	s, err := NewShardedCache(4, 40, WithHotKeys(HotKeyConfig{Threshold: 2, Window: time.Hour}))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j <= i; j++ {
+			s.Get("key" + strconv.Itoa(i+1))
+		}
+	}
+	require.Equal(t, []HotKey{{Key: "key3", Reads: 3}, {Key: "key2", Reads: 2}}, s.HotKeys())
+}
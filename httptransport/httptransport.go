@@ -0,0 +1,449 @@
+// Package httptransport is the http.RoundTripper caching the responses to GET in golru by the rules of the private
+// cache of RFC 9111. The fresh response is served from the cache, and the stale one is revalidated by If-None-Match
+// and If-Modified-Since built from its validators, so the origin answers 304 Not Modified instead of the whole body:
+//
+//	cache, _ := golru.NewCache(1000)
+//	client := &http.Client{Transport: httptransport.New(cache, nil)}
+//
+// The freshness is given by max-age of Cache-Control, by Expires, or heuristically by 10% of the time since
+// Last-Modified. The responses with no-store aren't stored, and the ones with no-cache are revalidated every time.
+// Vary is respected, the requests with Range or their own conditional headers pass through the cache, and the
+// successful unsafe requests, such as POST, invalidate the stored response of their URL. The stale responses are never
+// served, even if the origin fails
+package httptransport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XFromCache is the header set to "1" in the responses served from the cache, including the revalidated ones
+const XFromCache = "X-From-Cache"
+
+// Cache is the part of the cache used by the transport, golru.Cacher satisfies it
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	AddE(key string, value interface{}) error
+	AddWithDeadline(key string, value interface{}, deadline time.Time) error
+	Remove(key string) bool
+}
+
+// Option configures the transport
+type Option func(*Transport)
+
+// WithMaxBodyBytes limits the body of the stored response. The larger response is passed to the caller as it is, but
+// isn't stored, so the transport never buffers more than the limit. Zero means no limit
+func WithMaxBodyBytes(n int64) Option {
+	return func(t *Transport) {
+		t.maxBody = n
+	}
+}
+
+// Transport is the caching http.RoundTripper
+type Transport struct {
+	cache   Cache
+	next    http.RoundTripper
+	maxBody int64
+}
+
+// New creates the transport storing the responses of next in the cache, http.DefaultTransport is used if next is nil
+func New(cache Cache, next http.RoundTripper, opts ...Option) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Transport{cache: cache, next: next}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// stored is the response kept in the cache. Vary keeps the values of the request headers named by Vary of the
+// response. The stored response is never changed, the revalidated one replaces it
+type stored struct {
+	status    int
+	header    http.Header
+	body      []byte
+	vary      map[string]string
+	requested time.Time
+	received  time.Time
+}
+
+// understood are the status codes whose responses are stored. They are heuristically cacheable by RFC 9110, so they
+// are fresh by the heuristic lifetime without the explicit one too
+var understood = map[int]bool{
+	http.StatusOK: true, http.StatusNonAuthoritativeInfo: true, http.StatusNoContent: true,
+	http.StatusMultipleChoices: true, http.StatusMovedPermanently: true, http.StatusPermanentRedirect: true,
+	http.StatusNotFound: true, http.StatusMethodNotAllowed: true, http.StatusGone: true,
+	http.StatusRequestURITooLong: true, http.StatusNotImplemented: true,
+}
+
+// RoundTrip serves the request from the cache if the stored response is fresh, revalidates the stale one, and stores
+// the new response if it is allowed to
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.URL.String()
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return t.next.RoundTrip(req)
+	default:
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			t.cache.Remove(key)
+		}
+		return resp, err
+	}
+
+	directives := cacheControl(req.Header)
+	if _, ok := directives["no-store"]; ok || req.Header.Get("Range") != "" || conditional(req.Header) {
+		return t.next.RoundTrip(req)
+	}
+
+	cached := t.lookup(key, req)
+	now := time.Now()
+	if cached != nil && cached.fresh(req.Header, directives, now) {
+		return cached.response(req, now), nil
+	}
+	if _, ok := directives["only-if-cached"]; ok {
+		return gatewayTimeout(req), nil
+	}
+
+	out := req
+	if cached != nil && cached.validated() {
+		out = req.Clone(req.Context())
+		if etag := cached.header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.header.Get("Last-Modified"); modified != "" {
+			out.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	requested := time.Now()
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	received := time.Now()
+
+	if out != req && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		updated := cached.revalidated(resp.Header, requested, received)
+		t.store(key, updated)
+		return updated.response(req, received), nil
+	}
+
+	return t.keep(key, req, resp, requested, received)
+}
+
+// lookup returns the stored response of the key if it was selected by the same values of the headers named by Vary
+func (t *Transport) lookup(key string, req *http.Request) *stored {
+	value, ok := t.cache.Get(key)
+	if !ok {
+		return nil
+	}
+	s, ok := value.(*stored)
+	if !ok {
+		return nil
+	}
+	for name, v := range s.vary {
+		if headerValue(req.Header, name) != v {
+			return nil
+		}
+	}
+
+	return s
+}
+
+// keep stores the response if it is allowed to, reading its body into the memory, and returns the response to the
+// caller with the body which can still be read
+func (t *Transport) keep(key string, req *http.Request, resp *http.Response, requested, received time.Time) (*http.Response, error) {
+	vary, ok := varyOf(req, resp)
+	if !ok || !storable(resp) {
+		return resp, nil
+	}
+
+	limit := t.maxBody
+	if limit <= 0 {
+		limit = -1
+	}
+	body, complete, err := readBody(resp.Body, limit)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if !complete {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store(key, &stored{
+		status:    resp.StatusCode,
+		header:    resp.Header.Clone(),
+		body:      body,
+		vary:      vary,
+		requested: requested,
+		received:  received,
+	})
+
+	return resp, nil
+}
+
+// store replaces the stored response of the key. The response with the validators is kept until it is evicted, as it
+// can be revalidated after it becomes stale, and the one without them is kept until it expires
+func (t *Transport) store(key string, s *stored) {
+	t.cache.Remove(key)
+	if s.validated() {
+		_ = t.cache.AddE(key, s)
+		return
+	}
+
+	expires := s.received.Add(s.lifetime() - s.initialAge())
+	if expires.After(time.Now()) {
+		_ = t.cache.AddWithDeadline(key, s, expires)
+	}
+}
+
+// fresh reports whether the stored response can be served without the revalidation
+func (s *stored) fresh(header http.Header, directives map[string]string, now time.Time) bool {
+	if _, ok := directives["no-cache"]; ok {
+		return false
+	}
+	if _, ok := header["Cache-Control"]; !ok && strings.Contains(strings.ToLower(header.Get("Pragma")), "no-cache") {
+		return false
+	}
+	if _, ok := cacheControl(s.header)["no-cache"]; ok {
+		return false
+	}
+
+	age := s.age(now)
+	if maxAge, ok := seconds(directives, "max-age"); ok && age > maxAge {
+		return false
+	}
+
+	return age < s.lifetime()
+}
+
+// validated reports whether the stored response has the validators to revalidate it
+func (s *stored) validated() bool {
+	return s.header.Get("ETag") != "" || s.header.Get("Last-Modified") != ""
+}
+
+// lifetime returns the freshness lifetime of the response: max-age, the time from Date to Expires, or 10% of the time
+// from Last-Modified to Date. The invalid Expires means the response is already stale
+func (s *stored) lifetime() time.Duration {
+	if maxAge, ok := seconds(cacheControl(s.header), "max-age"); ok {
+		return maxAge
+	}
+	if expires, ok := s.header["Expires"]; ok {
+		at, err := http.ParseTime(strings.Join(expires, ""))
+		if err != nil {
+			return 0
+		}
+		return at.Sub(s.date())
+	}
+	if modified, err := http.ParseTime(s.header.Get("Last-Modified")); err == nil && understood[s.status] {
+		return s.date().Sub(modified) / 10
+	}
+
+	return 0
+}
+
+// date returns the moment the response was generated by Date, or the moment it was received without it
+func (s *stored) date() time.Time {
+	if date, err := http.ParseTime(s.header.Get("Date")); err == nil {
+		return date
+	}
+
+	return s.received
+}
+
+// initialAge returns the age of the response when it was received: the larger of the apparent age by Date and the
+// age given by Age corrected by the delay of the response
+func (s *stored) initialAge() time.Duration {
+	apparent := s.received.Sub(s.date())
+	if apparent < 0 {
+		apparent = 0
+	}
+	corrected := s.received.Sub(s.requested)
+	if age, err := strconv.ParseInt(s.header.Get("Age"), 10, 64); err == nil && age > 0 {
+		corrected += time.Duration(age) * time.Second
+	}
+	if corrected > apparent {
+		return corrected
+	}
+
+	return apparent
+}
+
+// age returns the current age of the response
+func (s *stored) age(now time.Time) time.Duration {
+	return s.initialAge() + now.Sub(s.received)
+}
+
+// revalidated returns the stored response updated by the headers of 304 Not Modified received for it
+func (s *stored) revalidated(header http.Header, requested, received time.Time) *stored {
+	updated := *s
+	updated.header = s.header.Clone()
+	for name, values := range header {
+		if name != "Content-Length" {
+			updated.header[name] = values
+		}
+	}
+	updated.requested, updated.received = requested, received
+
+	return &updated
+}
+
+// response returns the stored response to the request with its current age
+func (s *stored) response(req *http.Request, now time.Time) *http.Response {
+	header := s.header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(s.age(now)/time.Second), 10))
+	header.Set(XFromCache, "1")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.status, http.StatusText(s.status)),
+		StatusCode:    s.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       req,
+	}
+}
+
+// storable reports whether the response may be stored: its status is understood, it has no no-store, and it is
+// either fresh for some time or can be revalidated
+func storable(resp *http.Response) bool {
+	if !understood[resp.StatusCode] {
+		return false
+	}
+	directives := cacheControl(resp.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if _, ok := directives["max-age"]; ok {
+		return true
+	}
+	if _, ok := resp.Header["Expires"]; ok {
+		return true
+	}
+
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// varyOf returns the values of the request headers named by Vary of the response. Returns false for Vary: *, which
+// never matches a following request
+func varyOf(req *http.Request, resp *http.Response) (map[string]string, bool) {
+	var vary map[string]string
+	for _, line := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			if vary == nil {
+				vary = make(map[string]string)
+			}
+			vary[http.CanonicalHeaderKey(name)] = headerValue(req.Header, name)
+		}
+	}
+
+	return vary, true
+}
+
+// headerValue returns all the values of the header joined by commas
+func headerValue(header http.Header, name string) string {
+	return strings.Join(header.Values(name), ", ")
+}
+
+// conditional reports whether the request has its own conditional headers, then the caller handles 304 itself
+func conditional(header http.Header) bool {
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		if header.Get(name) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheControl returns the directives of Cache-Control by their names in lower case, the directives without the
+// argument have the empty value
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return directives
+}
+
+// seconds returns the argument of the directive as the duration in seconds
+func seconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return time.Duration(n) * time.Second, true
+}
+
+// readBody reads the body up to the limit, or entirely if it is negative. Complete is false if the body is larger
+func readBody(body io.Reader, limit int64) (data []byte, complete bool, err error) {
+	if limit < 0 {
+		data, err = io.ReadAll(body)
+		return data, err == nil, err
+	}
+
+	data, err = io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) > limit {
+		return data, false, nil
+	}
+
+	return data, true, nil
+}
+
+// gatewayTimeout returns the response to only-if-cached when nothing is stored, as RFC 9111 requires
+func gatewayTimeout(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", http.StatusGatewayTimeout, http.StatusText(http.StatusGatewayTimeout)),
+		StatusCode: http.StatusGatewayTimeout,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
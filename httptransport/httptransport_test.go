@@ -0,0 +1,206 @@
+package httptransport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns the client caching the responses of the handler and the counter of the requests to it
+func newClient(t *testing.T, handler http.HandlerFunc, opts ...Option) (*http.Client, string, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := golru.NewCache(100)
+	require.NoError(t, err)
+
+	return &http.Client{Transport: New(c, http.DefaultTransport, opts...)}, server.URL, &hits
+}
+
+// get requests the url and returns the response with its body
+func get(t *testing.T, client *http.Client, url string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(body)
+}
+
+func TestFresh(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "body")
+	})
+
+	resp, body := get(t, client, url, nil)
+	require.Equal(t, "body", body)
+	require.Empty(t, resp.Header.Get(XFromCache))
+
+	resp, body = get(t, client, url, nil)
+	require.Equal(t, "body", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+	require.Equal(t, "0", resp.Header.Get("Age"))
+	require.EqualValues(t, 1, atomic.LoadInt32(hits))
+
+	_, _ = get(t, client, url, http.Header{"Cache-Control": {"max-age=0"}})
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestRevalidateETag(t *testing.T) {
+	var bodies int32
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("X-Revalidated", "yes")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&bodies, 1)
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	resp, body := get(t, client, url, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "body", body)
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+	require.Equal(t, "yes", resp.Header.Get("X-Revalidated"))
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+	require.EqualValues(t, 1, atomic.LoadInt32(&bodies))
+}
+
+func TestRevalidateLastModified(t *testing.T) {
+	modified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("Last-Modified", modified)
+		if r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	resp, body := get(t, client, url, nil)
+	require.Equal(t, "body", body)
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestHeuristicFreshness(t *testing.T) {
+	modified := time.Now().Add(-100 * time.Hour).UTC().Format(http.TimeFormat)
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modified)
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	resp, _ := get(t, client, url, nil)
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+	require.EqualValues(t, 1, atomic.LoadInt32(hits))
+}
+
+func TestExpires(t *testing.T) {
+	expires := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Expires", expires)
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	_, _ = get(t, client, url, nil)
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestNoStore(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	_, body := get(t, client, url, nil)
+	require.Equal(t, "body", body)
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestVary(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Language"))
+	})
+
+	_, body := get(t, client, url, http.Header{"Accept-Language": {"en"}})
+	require.Equal(t, "en", body)
+	_, body = get(t, client, url, http.Header{"Accept-Language": {"en"}})
+	require.Equal(t, "en", body)
+	require.EqualValues(t, 1, atomic.LoadInt32(hits))
+
+	_, body = get(t, client, url, http.Header{"Accept-Language": {"de"}})
+	require.Equal(t, "de", body)
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestUnsafeInvalidates(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "body")
+	})
+
+	_, _ = get(t, client, url, nil)
+	resp, err := client.Post(url, "text/plain", strings.NewReader("update"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	_, _ = get(t, client, url, nil)
+	require.EqualValues(t, 3, atomic.LoadInt32(hits))
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "larger than the limit")
+	}, WithMaxBodyBytes(4))
+
+	_, body := get(t, client, url, nil)
+	require.Equal(t, "larger than the limit", body)
+	_, _ = get(t, client, url, nil)
+	require.EqualValues(t, 2, atomic.LoadInt32(hits))
+}
+
+func TestOnlyIfCached(t *testing.T) {
+	client, url, hits := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "body")
+	})
+
+	resp, _ := get(t, client, url, http.Header{"Cache-Control": {"only-if-cached"}})
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	require.Zero(t, atomic.LoadInt32(hits))
+
+	_, _ = get(t, client, url, nil)
+	resp, body := get(t, client, url, http.Header{"Cache-Control": {"only-if-cached"}})
+	require.Equal(t, "body", body)
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+}
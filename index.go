@@ -0,0 +1,178 @@
+package golru
+
+import "time"
+
+// IndexFunc extracts the attribute of the value by which the elements are indexed, for example the tenant of the
+// value. Elements with the empty attribute are not indexed. It gets the value as it was passed to the cache, and is
+// called outside the lock when the value is added or changed, except within Update and the copies of the cache, so
+// it must not use the methods of the cache either way
+type IndexFunc func(value interface{}) string
+
+// index keeps the keys of the elements grouped by the attribute, and the attribute of every indexed key, so the
+// element is found in the index even if its value was mutated after it was added
+type index struct {
+	extract IndexFunc
+	keys    map[string]map[string]struct{}
+	attrs   map[string]string
+}
+
+// WithIndex registers the secondary index with the given name. The index is updated whenever an element is added,
+// changed, removed or evicted, so it never refers to the keys which have left the cache
+func WithIndex(name string, fn IndexFunc) CacheOption {
+	return func(cache *cache) {
+		if cache.indexes == nil {
+			cache.indexes = make(map[string]*index)
+		}
+		cache.indexes[name] = newIndex(fn)
+	}
+}
+
+// newIndex returns an empty index with the given extractor
+func newIndex(fn IndexFunc) *index {
+	return &index{
+		extract: fn,
+		keys:    make(map[string]map[string]struct{}),
+		attrs:   make(map[string]string),
+	}
+}
+
+// GetByIndex returns the snapshots of the live elements with the given attribute in the named index, in no particular
+// order. Like GetEntry, it doesn't count as an access. Returns nil if there is no such index
+func (c *cache) GetByIndex(name, attr string) []Entry {
+	c.lock()
+	idx, ok := c.indexes[name]
+	if !ok {
+		c.unlock()
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(idx.keys[attr]))
+	for key := range idx.keys[attr] {
+		if element := c.items[key]; !c.expired(element, now) {
+			entries = append(entries, c.snapshot(element))
+		}
+	}
+	c.unlock()
+
+	return c.reveal(entries)
+}
+
+// InvalidateByIndex removes all the elements with the given attribute in the named index, like Remove does, except
+// the leased ones. Returns the number of removed elements
+func (c *cache) InvalidateByIndex(name, attr string) int {
+	c.lock()
+	defer c.unlock()
+
+	idx, ok := c.indexes[name]
+	if !ok {
+		return 0
+	}
+
+	removed := 0
+	now := time.Now()
+	for key := range idx.keys[attr] {
+		element := c.items[key]
+		if c.pinned(element, now) {
+			continue
+		}
+		c.removeElement(element)
+		c.queueRemoval(element.key, element.value)
+		c.queueAudit(element.key)
+		c.entomb(element.key)
+		c.release(element)
+		removed++
+	}
+
+	return removed
+}
+
+// attributes extracts the attributes of the new value for every index before the value is stored. It is called
+// outside the lock, the result is handed to reindex with stage once the lock is taken
+func (c *cache) attributes(value interface{}) map[string]string {
+	if len(c.indexes) == 0 {
+		return nil
+	}
+
+	attrs := make(map[string]string, len(c.indexes))
+	for name, idx := range c.indexes {
+		attrs[name] = idx.extract(value)
+	}
+
+	return attrs
+}
+
+// stage remembers the attributes of the new value of the key for reindex until the cache is unlocked
+func (c *cache) stage(key string, attrs map[string]string) {
+	if attrs == nil {
+		return
+	}
+	if c.staged == nil {
+		c.staged = make(map[string]map[string]string)
+	}
+	c.staged[key] = attrs
+}
+
+// reindex puts the element to every index according to its current value. The attributes staged for the key are
+// used if there are any, otherwise they are extracted from the original form of the stored value
+func (c *cache) reindex(element *item) {
+	if len(c.indexes) == 0 {
+		return
+	}
+
+	attrs, ok := c.staged[element.key]
+	if !ok {
+		attrs = make(map[string]string, len(c.indexes))
+		value := c.plain(element.value)
+		for name, idx := range c.indexes {
+			attrs[name] = idx.extract(value)
+		}
+	}
+	for name, idx := range c.indexes {
+		idx.remove(element.key)
+		idx.add(element.key, attrs[name])
+	}
+}
+
+// unindex deletes the element from every index
+func (c *cache) unindex(element *item) {
+	for _, idx := range c.indexes {
+		idx.remove(element.key)
+	}
+}
+
+// resetIndexes empties every index
+func (c *cache) resetIndexes() {
+	for name, idx := range c.indexes {
+		c.indexes[name] = newIndex(idx.extract)
+	}
+}
+
+// add puts the key to the group of the attribute
+func (idx *index) add(key, attr string) {
+	if attr == "" {
+		return
+	}
+
+	group, ok := idx.keys[attr]
+	if !ok {
+		group = make(map[string]struct{})
+		idx.keys[attr] = group
+	}
+	group[key] = struct{}{}
+	idx.attrs[key] = attr
+}
+
+// remove deletes the key from the group of its attribute
+func (idx *index) remove(key string) {
+	attr, ok := idx.attrs[key]
+	if !ok {
+		return
+	}
+
+	delete(idx.attrs, key)
+	delete(idx.keys[attr], key)
+	if len(idx.keys[attr]) == 0 {
+		delete(idx.keys, attr)
+	}
+}
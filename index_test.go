@@ -0,0 +1,98 @@
+package golru
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tenantValue struct {
+	tenant string
+	data   int
+}
+
+func tenantIndex(value interface{}) string {
+	if v, ok := value.(tenantValue); ok {
+		return v.tenant
+	}
+	return ""
+}
+
+func indexedKeys(entries []Entry) []string {
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestIndex(t *testing.T) {
+	c, err := NewCache(3, WithIndex("tenant", tenantIndex))
+	require.NoError(t, err)
+
+	c.Add("a", tenantValue{"acme", 1})
+	c.Add("b", tenantValue{"acme", 2})
+	c.Add("c", tenantValue{"other", 3})
+
+	require.Equal(t, []string{"a", "b"}, indexedKeys(c.GetByIndex("tenant", "acme")))
+	require.Nil(t, c.GetByIndex("unknown", "acme"))
+
+	c.Add("d", tenantValue{"other", 4})
+	require.Equal(t, []string{"b"}, indexedKeys(c.GetByIndex("tenant", "acme")))
+
+	c.ChangeValue("b", tenantValue{"other", 5})
+	require.Empty(t, c.GetByIndex("tenant", "acme"))
+	require.Equal(t, []string{"b", "c", "d"}, indexedKeys(c.GetByIndex("tenant", "other")))
+
+	c.Remove("c")
+	require.Equal(t, 2, c.InvalidateByIndex("tenant", "other"))
+	require.Zero(t, c.Len())
+	require.Zero(t, c.InvalidateByIndex("tenant", "other"))
+}
+
+func TestIndexClearAndClone(t *testing.T) {
+	c, err := NewCache(0, WithIndex("tenant", tenantIndex))
+	require.NoError(t, err)
+
+	c.Add("a", tenantValue{"acme", 1})
+	c.Add("b", "not indexed")
+
+	clone := c.Clone(nil)
+	require.Equal(t, []string{"a"}, indexedKeys(clone.GetByIndex("tenant", "acme")))
+
+	c.Clear()
+	require.Empty(t, c.GetByIndex("tenant", "acme"))
+	require.Len(t, clone.GetByIndex("tenant", "acme"), 1)
+}
+
+func TestIndexStoredValues(t *testing.T) {
+	var c Cacher
+	var underLock bool
+	c, err := NewCache(0, WithCodec(GobCodec()), WithCompression(FlateCompressor(1), 1),
+		WithIndex("tenant", func(value interface{}) string {
+			if c.(*cache).mu.TryLock() {
+				c.(*cache).mu.Unlock()
+			} else {
+				underLock = true
+			}
+			tenant, _, _ := strings.Cut(value.(string), ":")
+			return tenant
+		}))
+	require.NoError(t, err)
+
+	c.Add("a", "acme:1")
+	c.Add("b", "acme:2")
+	require.True(t, c.ChangeValue("b", "other:2"))
+	require.False(t, underLock)
+	require.Equal(t, []string{"a"}, indexedKeys(c.GetByIndex("tenant", "acme")))
+	require.Equal(t, []string{"b"}, indexedKeys(c.GetByIndex("tenant", "other")))
+
+	_, err = c.Update("a", func(old interface{}, ok bool) (interface{}, error) {
+		return "other:1", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, indexedKeys(c.GetByIndex("tenant", "other")))
+}
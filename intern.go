@@ -0,0 +1,20 @@
+package golru
+
+// WithKeyInterning makes the cache store the canonical copy of every key, shared by all the caches with this option
+// and by the other users of the unique package. Inside a single cache the map and the list already share the bytes
+// of a key, so interning pays off when the same long keys are kept by several caches or shards. It works only with
+// Go 1.23 and newer, where the unique package is available, and does nothing otherwise
+func WithKeyInterning() CacheOption {
+	return func(cache *cache) {
+		cache.interning = true
+	}
+}
+
+// internKey replaces the key of the item by its canonical copy if interning is enabled
+func (c *cache) internKey(it *item) {
+	if !c.interning {
+		return
+	}
+
+	it.key, it.keyHandle = intern(it.key)
+}
@@ -0,0 +1,8 @@
+//go:build !go1.23
+
+package golru
+
+// intern returns the key itself, as the unique package isn't available before Go 1.23
+func intern(key string) (string, interface{}) {
+	return key, nil
+}
@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package golru
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyInterning(t *testing.T) {
+	first, err := NewCache(2, WithKeyInterning())
+	require.NoError(t, err)
+	second, err := NewCache(2, WithKeyInterning())
+	require.NoError(t, err)
+
+	buffer := strings.Join([]string{"https://example.com/first", "https://example.com/second"}, " ")
+	key := buffer[:strings.Index(buffer, " ")]
+	first.Add(key, 1)
+	second.Add(strings.Clone(key), 2)
+
+	firstKey := first.(*cache).chain.Front().key
+	secondKey := second.(*cache).chain.Front().key
+	require.Equal(t, key, firstKey)
+	require.Equal(t, unsafe.StringData(firstKey), unsafe.StringData(secondKey))
+
+	value, ok := first.Get(key)
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+}
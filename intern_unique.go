@@ -0,0 +1,12 @@
+//go:build go1.23
+
+package golru
+
+import "unique"
+
+// intern returns the canonical copy of the key and the handle which keeps it alive
+func intern(key string) (string, interface{}) {
+	handle := unique.Make(key)
+
+	return handle.Value(), handle
+}
@@ -0,0 +1,230 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// KeySeparator separates the parts of the keys made by Key and KeyBuilder
+	KeySeparator = ':'
+	// keyEscape precedes the separator and itself inside the parts, so the key can be split back
+	keyEscape = '\\'
+)
+
+// maxReportedKey limits the length of the key quoted by KeyError
+const maxReportedKey = 64
+
+var (
+	ErrEmptyKey   = errors.New("key is empty")
+	ErrKeyTooLong = errors.New("key is too long")
+)
+
+// KeyError is returned by the operations with the key rejected by the validator set by WithKeyValidator. Err is the
+// error returned by the validator
+type KeyError struct {
+	Key string
+	Err error
+}
+
+// Error quotes the beginning of the key, as the rejected keys tend to be huge
+func (e *KeyError) Error() string {
+	key := e.Key
+	if len(key) > maxReportedKey {
+		key = key[:maxReportedKey] + "..."
+	}
+
+	return fmt.Sprintf("golru: invalid key %q: %v", key, e.Err)
+}
+
+// Unwrap returns the error of the validator
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// Key joins the parts into a namespaced key, for example Key("user", id, "profile"). The separator and the escape
+// character inside the parts are escaped, so SplitKey returns exactly the same parts. The only allocation is the
+// resulting string
+func Key(parts ...string) string {
+	size := 0
+	for _, part := range parts {
+		size += len(part) + strings.Count(part, string(KeySeparator)) + strings.Count(part, string(keyEscape)) + 1
+	}
+
+	var b strings.Builder
+	if size != 0 {
+		b.Grow(size - 1)
+	}
+	for i, part := range parts {
+		if i != 0 {
+			b.WriteByte(KeySeparator)
+		}
+		for j := 0; j < len(part); j++ {
+			if part[j] == KeySeparator || part[j] == keyEscape {
+				b.WriteByte(keyEscape)
+			}
+			b.WriteByte(part[j])
+		}
+	}
+
+	return b.String()
+}
+
+// SplitKey returns the parts of the key made by Key or KeyBuilder
+func SplitKey(key string) []string {
+	parts := make([]string, 0, strings.Count(key, string(KeySeparator))+1)
+
+	var part []byte
+	start, escaped := 0, false
+	for i := 0; i < len(key); i++ {
+		switch {
+		case escaped:
+			part = append(part, key[i])
+			escaped = false
+		case key[i] == keyEscape:
+			if part == nil {
+				part = append(make([]byte, 0, len(key)-start), key[start:i]...)
+			}
+			escaped = true
+		case key[i] == KeySeparator:
+			parts = append(parts, splitPart(key[start:i], part))
+			part, start = nil, i+1
+		case part != nil:
+			part = append(part, key[i])
+		}
+	}
+
+	return append(parts, splitPart(key[start:], part))
+}
+
+// splitPart returns the unescaped part if there was anything to unescape, or the raw part of the key otherwise
+func splitPart(raw string, unescaped []byte) string {
+	if unescaped == nil {
+		return raw
+	}
+
+	return string(unescaped)
+}
+
+// KeyBuilder makes the keys like Key, reusing its buffer between the keys. Together with GetBytes or RemoveBytes,
+// the lookup by the built key doesn't allocate at all. The zero value is ready to use
+type KeyBuilder struct {
+	buf []byte
+}
+
+// Reset empties the builder keeping its buffer
+func (b *KeyBuilder) Reset() *KeyBuilder {
+	b.buf = b.buf[:0]
+
+	return b
+}
+
+// Add appends the part to the key, escaping it the way Key does
+func (b *KeyBuilder) Add(part string) *KeyBuilder {
+	if len(b.buf) != 0 {
+		b.buf = append(b.buf, KeySeparator)
+	}
+	for i := 0; i < len(part); i++ {
+		if part[i] == KeySeparator || part[i] == keyEscape {
+			b.buf = append(b.buf, keyEscape)
+		}
+		b.buf = append(b.buf, part[i])
+	}
+
+	return b
+}
+
+// Bytes returns the built key. The slice is valid until the next change of the builder
+func (b *KeyBuilder) Bytes() []byte {
+	return b.buf
+}
+
+// String returns the built key as a new string
+func (b *KeyBuilder) String() string {
+	return string(b.buf)
+}
+
+// WithKeyNormalizer sets the function applied to the key of every operation before anything else, including the
+// hooks and the middleware, for example strings.ToLower for a case-insensitive domain. The elements are stored under
+// the normalized keys, so this is also what Keys and the eviction callback return
+func WithKeyNormalizer(fn func(string) string) CacheOption {
+	return func(cache *cache) {
+		cache.normalizer = fn
+	}
+}
+
+// normalize returns the key as it is stored in the cache
+func (c *cache) normalize(key string) string {
+	if c.normalizer == nil {
+		return key
+	}
+
+	return c.normalizer(key)
+}
+
+// wrapNormalizer applies the normalizer to the key of the operation
+func (c *cache) wrapNormalizer(next ctxOpFunc) ctxOpFunc {
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		if op != OpClear {
+			key = c.normalizer(key)
+		}
+
+		return next(ctx, op, key, value)
+	}
+}
+
+// WithKeyValidator sets the function checking the key of every operation after the normalization. The operation
+// with the rejected key isn't performed and returns KeyError wrapping the error of the validator, which the
+// E-variants pass to the caller. The hooks still observe the rejected operations
+func WithKeyValidator(fn func(string) error) CacheOption {
+	return func(cache *cache) {
+		cache.validator = fn
+	}
+}
+
+// NonEmptyKey is the key validator rejecting the empty keys
+func NonEmptyKey(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+
+	return nil
+}
+
+// MaxKeyLength returns the key validator rejecting the keys longer than n bytes
+func MaxKeyLength(n int) func(string) error {
+	return func(key string) error {
+		if len(key) > n {
+			return ErrKeyTooLong
+		}
+
+		return nil
+	}
+}
+
+// prepare normalizes and validates the key for the methods which don't go through the pipeline
+func (c *cache) prepare(key string) (string, error) {
+	key = c.normalize(key)
+	if c.validator != nil {
+		if err := c.validator(key); err != nil {
+			return "", &KeyError{Key: key, Err: err}
+		}
+	}
+
+	return key, nil
+}
+
+// wrapValidator rejects the operation if the validator doesn't accept its key
+func (c *cache) wrapValidator(next ctxOpFunc) ctxOpFunc {
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		if op != OpClear {
+			if err := c.validator(key); err != nil {
+				return nil, &KeyError{Key: key, Err: err}
+			}
+		}
+
+		return next(ctx, op, key, value)
+	}
+}
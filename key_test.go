@@ -0,0 +1,105 @@
+package golru
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	require.Equal(t, "user:42:profile", Key("user", "42", "profile"))
+	require.Equal(t, "", Key())
+	require.Equal(t, `a\:b:c\\`, Key("a:b", `c\`))
+
+	for _, parts := range [][]string{
+		{"user", "42", "profile"},
+		{"a:b", `c\`, ""},
+		{""},
+		{`\:`, "::", "x"},
+	} {
+		require.Equal(t, parts, SplitKey(Key(parts...)))
+	}
+}
+
+func TestKeyBuilder(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	c.Add(Key("user", "42"), 1)
+
+	var b KeyBuilder
+	require.Equal(t, Key("user", "a:b"), b.Add("user").Add("a:b").String())
+
+	b.Reset().Add("user").Add("42")
+	value, ok := c.GetBytes(b.Bytes())
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Reset().Add("user").Add("42")
+		c.GetBytes(b.Bytes())
+	})
+	require.Zero(t, allocs)
+	require.Equal(t, 1.0, testing.AllocsPerRun(100, func() {
+		_ = Key("user", "42", "profile")
+	}))
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	var evicted []string
+	c, err := NewCache(1, WithKeyNormalizer(strings.ToLower), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		evicted = append(evicted, key)
+	}))
+	require.NoError(t, err)
+
+	require.True(t, c.Add("Foo", 1))
+	require.False(t, c.Add("FOO", 2))
+	require.Equal(t, []string{"foo"}, c.Keys())
+
+	value, ok := c.GetBytes([]byte("fOo"))
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+	_, ok = c.GetEntry("FoO")
+	require.True(t, ok)
+
+	require.True(t, c.ChangeValue("FOO", 3))
+	c.Add("Bar", 4)
+	require.Equal(t, []string{"foo"}, evicted)
+	require.True(t, c.Remove("BAR"))
+
+	s, err := NewShardedCache(8, 80, WithKeyNormalizer(strings.ToLower))
+	require.NoError(t, err)
+	for _, key := range []string{"Foo", "FOO", "foo", "fOO"} {
+		s.Add(key, key)
+	}
+	require.Equal(t, 1, s.Len())
+}
+
+func TestKeyValidator(t *testing.T) {
+	var observed []bool
+	c, err := NewCache(2, WithKeyValidator(MaxKeyLength(8)), WithHooks(nil, func(op Op, key string, hit bool, d time.Duration) {
+		observed = append(observed, hit)
+	}))
+	require.NoError(t, err)
+
+	long := strings.Repeat("x", 1000)
+	err = c.AddE(long, 1)
+	var keyErr *KeyError
+	require.ErrorAs(t, err, &keyErr)
+	require.ErrorIs(t, err, ErrKeyTooLong)
+	require.Equal(t, long, keyErr.Key)
+	require.Less(t, len(err.Error()), 200)
+	require.Zero(t, c.Len())
+
+	_, err = c.GetE(long)
+	require.ErrorIs(t, err, ErrKeyTooLong)
+	require.True(t, c.Add("short", 1))
+	require.Equal(t, []bool{false, false, true}, observed)
+
+	c, err = NewCache(2, WithKeyValidator(NonEmptyKey))
+	require.NoError(t, err)
+	require.ErrorIs(t, c.AddE("", 1), ErrEmptyKey)
+	require.False(t, c.AddBytes(nil, 1))
+}
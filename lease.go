@@ -0,0 +1,162 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrLeased        = errors.New("element is leased")
+	ErrLeaseDuration = errors.New("lease duration should be greater than 0")
+)
+
+// Lease is the token of the lease acquired by AcquireLease. While the lease is active, the element is neither
+// evicted nor expired, and it can't be changed or removed
+type Lease struct {
+	c        *cache
+	key      string
+	id       uint64
+	deadline time.Time
+}
+
+// AcquireLease leases the element for the duration d. Several leases of the same element may be active at once, the
+// element is protected until all of them are released or expired. The capacity limit may be exceeded while all the
+// elements at the end of the list are leased. Clear still removes the leased elements. The key is normalized and
+// validated like the keys of the other methods. Returns ErrKeyNotFound if there is no such key, and ErrExpired if the
+// element has already expired
+func (c *cache) AcquireLease(key string, d time.Duration) (Lease, error) {
+	if d <= 0 {
+		return Lease{}, ErrLeaseDuration
+	}
+	key, err := c.prepare(key)
+	if err != nil {
+		return Lease{}, err
+	}
+
+	return c.lease(key, time.Now().Add(d))
+}
+
+// PinWithContext leases the element until the context is done, then the lease is released automatically, see
+// AcquireLease. The key is scoped with WithKeyScope, then normalized and validated. Returns the error of the context
+// if it is already done
+func (c *cache) PinWithContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key, err := c.prepare(c.scoped(ctx, key))
+	if err != nil {
+		return err
+	}
+
+	lease, err := c.lease(key, time.Time{})
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, func() {
+		lease.Release()
+	})
+
+	return nil
+}
+
+// lease leases the element until the deadline, a zero deadline makes the lease last until it is released
+func (c *cache) lease(key string, deadline time.Time) (Lease, error) {
+	c.lock()
+	defer c.unlock()
+
+	element, ok := c.validate(key)
+	if !ok {
+		return Lease{}, ErrKeyNotFound
+	}
+	if c.expired(element, time.Now()) {
+		c.evict(element, EvictedByTTL)
+		return Lease{}, ErrExpired
+	}
+
+	c.leaseSeq++
+	if element.leases == nil {
+		element.leases = make(map[uint64]time.Time, 1)
+	}
+	element.leases[c.leaseSeq] = deadline
+
+	return Lease{c: c, key: key, id: c.leaseSeq, deadline: deadline}, nil
+}
+
+// Key returns the key of the leased element
+func (l Lease) Key() string {
+	return l.key
+}
+
+// Deadline returns the moment when the lease expires, or zero if it lasts until it is released
+func (l Lease) Deadline() time.Time {
+	return l.deadline
+}
+
+// Release ends the lease. Returns false if the lease has already been released or expired, or the element has left
+// the cache
+func (l Lease) Release() bool {
+	if l.c == nil {
+		return false
+	}
+
+	c := l.c
+	c.lock()
+	defer c.unlock()
+
+	element, ok := c.validate(l.key)
+	if !ok {
+		return false
+	}
+	deadline, ok := element.leases[l.id]
+	if !ok {
+		return false
+	}
+
+	delete(element.leases, l.id)
+	if len(element.leases) == 0 {
+		element.leases = nil
+	}
+
+	return deadline.IsZero() || time.Now().Before(deadline)
+}
+
+// pinned reports whether the element has an active lease at the moment now. The expired leases are forgotten
+func (c *cache) pinned(element *item, now time.Time) bool {
+	if element.leases == nil {
+		return false
+	}
+
+	for id, deadline := range element.leases {
+		if deadline.IsZero() || now.Before(deadline) {
+			return true
+		}
+		delete(element.leases, id)
+	}
+	element.leases = nil
+
+	return false
+}
+
+// tail returns the next victim of the eviction policy, the least recently used element which isn't leased by default,
+// or nil if all of them are leased
+func (c *cache) tail() *item {
+	switch c.policy {
+	case PolicySIEVE:
+		return c.sieveVictim()
+	case PolicyS3FIFO:
+		return c.s3Victim()
+	case PolicyHyperbolic:
+		return c.sampledVictim(c.hyperbolic)
+	case PolicyGDSF:
+		return c.gdsfVictim()
+	}
+
+	for current := c.chain.Back(); current != nil; current = c.chain.Prev(current) {
+		if current.leases == nil || !c.pinned(current, time.Now()) {
+			return current
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package golru
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	_, err = c.AcquireLease("missing", time.Minute)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	c.Add("handle", 1)
+	_, err = c.AcquireLease("handle", 0)
+	require.ErrorIs(t, err, ErrLeaseDuration)
+
+	lease, err := c.AcquireLease("handle", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "handle", lease.Key())
+
+	c.Add("second", 2)
+	c.Add("third", 3)
+	_, ok := c.Get("handle")
+	require.True(t, ok)
+	_, ok = c.Get("second")
+	require.False(t, ok)
+
+	require.False(t, c.ChangeValue("handle", 10))
+	require.ErrorIs(t, c.RemoveE("handle"), ErrLeased)
+
+	require.True(t, lease.Release())
+	require.False(t, lease.Release())
+	require.True(t, c.ChangeValue("handle", 10))
+	require.NoError(t, c.RemoveE("handle"))
+	require.False(t, Lease{}.Release())
+}
+
+func TestLeaseNormalizedKey(t *testing.T) {
+	c, err := NewCache(2, WithKeyNormalizer(strings.ToLower), WithKeyValidator(func(key string) error {
+		if key == "" {
+			return ErrRejected
+		}
+		return nil
+	}))
+	require.NoError(t, err)
+
+	c.Add("Handle", 1)
+	lease, err := c.AcquireLease("HANDLE", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "handle", lease.Key())
+	require.False(t, c.Remove("handle"))
+
+	_, err = c.AcquireLease("", time.Minute)
+	var keyErr *KeyError
+	require.ErrorAs(t, err, &keyErr)
+	require.ErrorIs(t, err, ErrRejected)
+}
+
+func TestLeaseBlocksExpiration(t *testing.T) {
+	c, err := NewCache(1, WithTTL(0.01))
+	require.NoError(t, err)
+
+	c.Add("handle", 1)
+	short, err := c.AcquireLease("handle", 30*time.Millisecond)
+	require.NoError(t, err)
+	long, err := c.AcquireLease("handle", time.Minute)
+	require.NoError(t, err)
+
+	c.Add("other", 2)
+	require.Equal(t, 2, c.Len())
+	require.Equal(t, 1, c.ChangeCapacity(1))
+	require.Equal(t, []string{"handle"}, c.Keys())
+
+	time.Sleep(40 * time.Millisecond)
+	require.False(t, short.Release())
+	_, ok := c.Get("handle")
+	require.True(t, ok)
+
+	require.True(t, long.Release())
+	_, err = c.GetE("handle")
+	require.ErrorIs(t, err, ErrExpired)
+}
+
+func TestPinWithContext(t *testing.T) {
+	c, err := NewCache(1)
+	require.NoError(t, err)
+
+	c.Add("pinned", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, c.PinWithContext(ctx, "pinned"))
+	require.ErrorIs(t, c.PinWithContext(ctx, "missing"), ErrKeyNotFound)
+
+	c.Add("other", 2)
+	require.Equal(t, 2, c.Len())
+	require.False(t, c.Remove("pinned"))
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return c.Remove("pinned")
+	}, time.Second, time.Millisecond)
+	require.ErrorIs(t, c.PinWithContext(ctx, "other"), context.Canceled)
+}
+
+func TestPinWithContextNormalizedKey(t *testing.T) {
+	c, err := NewCache(1, WithKeyNormalizer(strings.ToLower), WithKeyValidator(func(key string) error {
+		if key == "" {
+			return ErrRejected
+		}
+		return nil
+	}))
+	require.NoError(t, err)
+
+	c.Add("Pinned", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.PinWithContext(ctx, "PINNED"))
+	require.False(t, c.Remove("pinned"))
+	require.ErrorIs(t, c.PinWithContext(ctx, ""), ErrRejected)
+}
@@ -0,0 +1,111 @@
+package golru
+
+// chain is an intrusive doubly linked list of items. Links are stored inside the items themselves, so adding an
+// element doesn't allocate anything except the item, and access to the data doesn't need type assertions. The list
+// is circular: root is a sentinel, root.next is the front and root.prev is the back of the list. Slots keep the
+// items in no particular order to pick them by index
+type chain struct {
+	root  item
+	len   int
+	slots []*item
+}
+
+// newChain returns an initialized empty list
+func newChain() *chain {
+	l := &chain{}
+	l.root.next = &l.root
+	l.root.prev = &l.root
+
+	return l
+}
+
+// Len returns the number of items in the list
+func (l *chain) Len() int {
+	return l.len
+}
+
+// Front returns the first item of the list or nil if the list is empty
+func (l *chain) Front() *item {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back returns the last item of the list or nil if the list is empty
+func (l *chain) Back() *item {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// Next returns the item after the given one or nil if it is the last item
+func (l *chain) Next(it *item) *item {
+	if it.next == &l.root {
+		return nil
+	}
+	return it.next
+}
+
+// Prev returns the item before the given one or nil if it is the first item
+func (l *chain) Prev(it *item) *item {
+	if it.prev == &l.root {
+		return nil
+	}
+	return it.prev
+}
+
+// PushFront links the item at the front of the list
+func (l *chain) PushFront(it *item) *item {
+	l.insertAfter(it, &l.root)
+	it.slot = len(l.slots)
+	l.slots = append(l.slots, it)
+
+	return it
+}
+
+// At returns the item in the i-th slot, the slots are numbered from 0 to Len()-1 in no particular order
+func (l *chain) At(i int) *item {
+	return l.slots[i]
+}
+
+// MoveToFront moves the item linked to this list to the front
+func (l *chain) MoveToFront(it *item) {
+	if l.root.next == it {
+		return
+	}
+	l.unlink(it)
+	l.insertAfter(it, &l.root)
+}
+
+// Remove unlinks the item from the list
+func (l *chain) Remove(it *item) *item {
+	l.unlink(it)
+	it.next = nil
+	it.prev = nil
+
+	last := l.slots[len(l.slots)-1]
+	l.slots[it.slot] = last
+	last.slot = it.slot
+	l.slots[len(l.slots)-1] = nil
+	l.slots = l.slots[:len(l.slots)-1]
+
+	return it
+}
+
+// insertAfter links the item after at
+func (l *chain) insertAfter(it, at *item) {
+	it.prev = at
+	it.next = at.next
+	at.next.prev = it
+	at.next = it
+	l.len++
+}
+
+// unlink excludes the item from the list without clearing its links
+func (l *chain) unlink(it *item) {
+	it.prev.next = it.next
+	it.next.prev = it.prev
+	l.len--
+}
@@ -0,0 +1,324 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrLoadPanic = errors.New("loader panicked while loading the key")
+
+// BatchLoadFunc loads the values of the keys missing in the cache. The keys absent in the result don't exist, and
+// the error fails the load of all the keys of the batch. The value may be wrapped into Loaded to set its own lifetime
+type BatchLoadFunc func(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+// Loaded is the value returned by the loader with the lifetime given by the origin, such as max-age of the HTTP
+// response or the validity of the database row. Expires is the absolute moment the value expires at, TTL is the
+// lifetime counted from the load and is used if Expires is zero. The loaded value is added as by AddWithDeadline, so
+// the ttl of the cache still limits it, and the value already expired is returned to the caller, but isn't cached.
+// Without both of them the value is added as the plain one. The callers get the unwrapped value
+type Loaded struct {
+	Value   interface{}
+	TTL     time.Duration
+	Expires time.Time
+}
+
+// deadline returns the moment the loaded value expires at, or zero if the origin didn't set its lifetime
+func (l Loaded) deadline(now time.Time) time.Time {
+	if !l.Expires.IsZero() || l.TTL == 0 {
+		return l.Expires
+	}
+
+	return now.Add(l.TTL)
+}
+
+// flight is the load of a single key which the concurrent callers wait for
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	found bool
+	err   error
+}
+
+// flights keeps the loads in progress by the normalized keys
+type flights struct {
+	mu    sync.Mutex
+	byKey map[string]*flight
+}
+
+// GetMulti returns the values of the keys found in the cache and loads the missing ones in a single batch. The loaded
+// values are added to the cache. The concurrent calls with the overlapping keys are coalesced: a key being loaded by
+// another call isn't passed to the loader again, the call waits for the result of that load instead, so every key is
+// loaded once however many callers ask for it at the same time. Returns the values found so far with the first error
+// of the loads or of the context, which limits the waiting. Without the loader only the cached values are returned.
+// Without the hooks, the middleware and the key normalization all the keys are looked up under a single lock
+// acquisition, otherwise every key goes through them as Get. The keys are stored within the scope of WithKeyScope,
+// while the loader and the result get them as they are given
+func (c *cache) GetMulti(ctx context.Context, keys []string, load BatchLoadFunc) (map[string]interface{}, error) {
+	return getMulti(ctx, []keyGroup{{cache: c, keys: keys}}, load, func(string) *cache { return c })
+}
+
+// keyGroup is the keys owned by a single cache
+type keyGroup struct {
+	cache *cache
+	keys  []string
+}
+
+// getMulti implements GetMulti for the groups of the keys: the keys of every group are looked up together, and the
+// missing keys of all the groups are loaded in a single batch. Owner returns the cache of the key
+func getMulti(ctx context.Context, groups []keyGroup, load BatchLoadFunc, owner func(key string) *cache) (map[string]interface{}, error) {
+	size := 0
+	for _, group := range groups {
+		size += len(group.keys)
+	}
+
+	values := make(map[string]interface{}, size)
+	var missing []string
+	var absent map[string]struct{}
+	for _, group := range groups {
+		missing = group.cache.lookupMany(ctx, group.keys, values, missing, &absent)
+	}
+	if len(missing) == 0 || load == nil {
+		return values, nil
+	}
+
+	own := make(map[string]*flight, len(missing))
+	waiting := make(map[string]*flight)
+	for _, group := range groups {
+		group.cache.claim(ctx, group.keys, values, own, waiting)
+	}
+	var firstErr error
+	if len(own) != 0 {
+		firstErr = loadBatch(ctx, own, load, owner)
+	}
+
+	for _, key := range missing {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		f := own[key]
+		if f == nil {
+			f = waiting[key]
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				if firstErr == nil {
+					firstErr = ctx.Err()
+				}
+				continue
+			}
+		}
+		switch {
+		case f.err != nil:
+			if firstErr == nil {
+				firstErr = f.err
+			}
+		case f.found:
+			values[key] = f.value
+		}
+	}
+
+	return values, firstErr
+}
+
+// lookupMany puts the values of the found keys to values and appends the missing keys to missing once, remembering
+// them in absent. Without the pipeline all the keys are read under a single lock, and the values are decoded after it
+// is released
+func (c *cache) lookupMany(ctx context.Context, keys []string, values map[string]interface{}, missing []string, absent *map[string]struct{}) []string {
+	miss := func(key string) {
+		if *absent == nil {
+			*absent = make(map[string]struct{})
+		}
+		if _, ok := (*absent)[key]; !ok {
+			(*absent)[key] = struct{}{}
+			missing = append(missing, key)
+		}
+	}
+
+	if c.pipeline != nil {
+		for _, key := range keys {
+			if _, ok := values[key]; ok {
+				continue
+			}
+			if value, ok := c.Get(c.scoped(ctx, key)); ok {
+				values[key] = value
+				continue
+			}
+			miss(key)
+		}
+		return missing
+	}
+
+	var found []string
+	transforms := c.transforms()
+	c.lock()
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if !c.closed {
+			if value, err := c.get(c.scoped(ctx, key)); err == nil {
+				values[key] = value
+				if transforms {
+					found = append(found, key)
+				}
+				continue
+			}
+		}
+		miss(key)
+	}
+	c.unlock()
+
+	for _, key := range found {
+		value, err := c.load(values[key])
+		if err != nil {
+			delete(values, key)
+			miss(key)
+			continue
+		}
+		values[key] = value
+	}
+
+	return missing
+}
+
+// claim starts the flights of the missing keys of this cache nobody is loading and puts them to own, and puts the
+// flights of the keys being loaded by the other calls to waiting. The found keys are skipped. The keys without the
+// flights are looked up once more, as another call could load them after they were missed, and the values found are
+// put to found without counting them as the accesses
+func (c *cache) claim(ctx context.Context, keys []string, found map[string]interface{}, own, waiting map[string]*flight) {
+	c.flights.mu.Lock()
+	defer c.flights.mu.Unlock()
+
+	if c.flights.byKey == nil {
+		c.flights.byKey = make(map[string]*flight)
+	}
+
+	var unclaimed, normalized []string
+	for _, key := range keys {
+		if _, ok := found[key]; ok {
+			continue
+		}
+		if _, ok := own[key]; ok {
+			continue
+		}
+		if _, ok := waiting[key]; ok {
+			continue
+		}
+		stored := c.normalize(c.scoped(ctx, key))
+		if f, ok := c.flights.byKey[stored]; ok {
+			waiting[key] = f
+			continue
+		}
+		unclaimed, normalized = append(unclaimed, key), append(normalized, stored)
+	}
+
+	loaded := c.storedValues(normalized)
+	for i, key := range unclaimed {
+		if value, ok := loaded[normalized[i]]; ok {
+			if value, err := c.load(value); err == nil {
+				found[key] = value
+				continue
+			}
+		}
+		if _, ok := own[key]; ok {
+			continue
+		}
+		f := &flight{done: make(chan struct{})}
+		c.flights.byKey[normalized[i]] = f
+		own[key] = f
+	}
+}
+
+// storedValues returns the stored values of the live elements with the given keys under a single lock
+func (c *cache) storedValues(keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil
+	}
+	now := time.Now()
+	values := make(map[string]interface{})
+	for _, key := range keys {
+		if element, ok := c.validate(key); ok && !c.expired(element, now) {
+			values[key] = element.value
+		}
+	}
+
+	return values
+}
+
+// loadBatch loads the claimed keys, adds the found values to their caches and finishes the flights. The panic of the
+// loader is recovered and reported to the panic handler, the flights are finished with ErrLoadPanic, so the waiting
+// callers are never stuck, and so is the load
+func loadBatch(ctx context.Context, own map[string]*flight, load BatchLoadFunc, owner func(key string) *cache) (err error) {
+	keys := make([]string, 0, len(own))
+	for key := range own {
+		keys = append(keys, key)
+	}
+
+	finished := false
+	defer func() {
+		if finished {
+			return
+		}
+		if recovered := recover(); recovered != nil {
+			owner(keys[0]).reportPanic(callbackLoad, keys[0], recovered)
+		}
+		for _, f := range own {
+			f.err = ErrLoadPanic
+		}
+		land(ctx, own, owner)
+		err = ErrLoadPanic
+	}()
+
+	loaded, err := load(ctx, keys)
+	if err != nil {
+		owner(keys[0]).log(slog.LevelWarn, "golru: loader failed", "keys", len(keys), "key",
+			owner(keys[0]).redact(keys[0]), "error", err)
+	}
+	now := time.Now()
+	for key, f := range own {
+		f.err = err
+		if err == nil {
+			f.value, f.found = loaded[key]
+		}
+		if !f.found {
+			continue
+		}
+		c := owner(key)
+		if l, ok := f.value.(Loaded); ok {
+			f.value = l.Value
+			if deadline := l.deadline(now); !deadline.IsZero() {
+				_ = c.AddWithDeadline(c.scoped(ctx, key), f.value, deadline)
+				continue
+			}
+		}
+		_ = c.AddE(c.scoped(ctx, key), f.value)
+	}
+	finished = true
+	land(ctx, own, owner)
+
+	return err
+}
+
+// land removes the finished flights from their caches and wakes their waiters up
+func land(ctx context.Context, own map[string]*flight, owner func(key string) *cache) {
+	for key := range own {
+		c := owner(key)
+		c.flights.mu.Lock()
+		delete(c.flights.byKey, c.normalize(c.scoped(ctx, key)))
+		c.flights.mu.Unlock()
+	}
+
+	for _, f := range own {
+		close(f.done)
+	}
+}
@@ -0,0 +1,292 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMulti(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+	c.Add("cached", 0)
+
+	var batches [][]string
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		batches = append(batches, keys)
+		values := make(map[string]interface{})
+		for _, key := range keys {
+			if key != "absent" {
+				values[key] = "loaded " + key
+			}
+		}
+		return values, nil
+	}
+
+	values, err := c.GetMulti(context.Background(), []string{"cached", "first", "absent", "first"}, load)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"cached": 0, "first": "loaded first"}, values)
+	require.Len(t, batches, 1)
+	require.ElementsMatch(t, []string{"first", "absent"}, batches[0])
+
+	value, ok := c.Get("first")
+	require.True(t, ok)
+	require.Equal(t, "loaded first", value)
+
+	values, err = c.GetMulti(context.Background(), []string{"first", "second"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"first": "loaded first"}, values)
+}
+
+func TestGetMultiCoalescing(t *testing.T) {
+	c, err := NewCache(100)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	loads := make(map[string]int)
+	release := make(chan struct{})
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		mu.Lock()
+		for _, key := range keys {
+			loads[key]++
+		}
+		mu.Unlock()
+		<-release
+		values := make(map[string]interface{})
+		for _, key := range keys {
+			values[key] = key
+		}
+		return values, nil
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			keys := []string{"shared", "key" + strconv.Itoa(g), "key" + strconv.Itoa(g+1)}
+			values, err := c.GetMulti(context.Background(), keys, load)
+			require.NoError(t, err)
+			require.Len(t, values, 3)
+		}(g)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Len(t, loads, 10)
+	for key, n := range loads {
+		require.Equal(t, 1, n, key)
+	}
+}
+
+func TestGetMultiErrors(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	failure := errors.New("failure")
+	_, err = c.GetMulti(context.Background(), []string{"test"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return nil, failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 0, c.Len())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = c.GetMulti(context.Background(), []string{"slow"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+			close(started)
+			<-release
+			return map[string]interface{}{"slow": 1}, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.GetMulti(ctx, []string{"slow"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		t.Fatal("the key is loaded twice")
+		return nil, nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	close(release)
+
+}
+
+func TestGetMultiLoaderPanic(t *testing.T) {
+	var panics []string
+	c, err := NewCache(10, WithPanicHandler(func(callback string, key string, value interface{}) {
+		panics = append(panics, callback+":"+key+":"+value.(string))
+	}))
+	require.NoError(t, err)
+	c.Add("cached", 1)
+
+	values, err := c.GetMulti(context.Background(), []string{"cached", "panic"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		panic("loader")
+	})
+	require.ErrorIs(t, err, ErrLoadPanic)
+	require.Equal(t, map[string]interface{}{"cached": 1}, values)
+	require.Equal(t, []string{"load:panic:loader"}, panics)
+
+	tc := c.(*cache)
+	tc.flights.mu.Lock()
+	_, ok := tc.flights.byKey["panic"]
+	tc.flights.mu.Unlock()
+	require.False(t, ok)
+
+	values, err = c.GetMulti(context.Background(), []string{"panic"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"panic": 2}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"panic": 2}, values)
+}
+
+func TestGetMultiLoadedTTL(t *testing.T) {
+	c, err := NewCache(10, WithTTL(60))
+	require.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour)
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"short":   Loaded{Value: 1, TTL: 20 * time.Millisecond},
+			"row":     Loaded{Value: 2, Expires: expires},
+			"stale":   Loaded{Value: 3, Expires: time.Now().Add(-time.Second)},
+			"plain":   4,
+			"default": Loaded{Value: 5},
+		}, nil
+	}
+
+	values, err := c.GetMulti(context.Background(), []string{"short", "row", "stale", "plain", "default"}, load)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"short": 1, "row": 2, "stale": 3, "plain": 4, "default": 5}, values)
+
+	_, ok := c.GetEntry("stale")
+	require.False(t, ok)
+	entry, ok := c.GetEntry("row")
+	require.True(t, ok)
+	require.Equal(t, entry.Created.Add(time.Minute), entry.Expiration)
+	entry, _ = c.GetEntry("default")
+	require.Equal(t, 5, entry.Value)
+	require.Equal(t, entry.Created.Add(time.Minute), entry.Expiration)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = c.GetE("short")
+	require.ErrorIs(t, err, ErrExpired)
+	value, ok := c.Get("plain")
+	require.True(t, ok)
+	require.Equal(t, 4, value)
+}
+
+func TestGetMultiSingleLock(t *testing.T) {
+	c, err := NewCache(100, WithLockMetrics())
+	require.NoError(t, err)
+
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Add(keys[i], i)
+	}
+
+	before := c.Stats().Locks.Acquisitions
+	values, err := c.GetMulti(context.Background(), keys, nil)
+	require.NoError(t, err)
+	require.Len(t, values, 50)
+	require.Equal(t, before+2, c.Stats().Locks.Acquisitions)
+	require.Equal(t, uint64(50), c.Stats().Hits)
+}
+
+func TestShardedGetMulti(t *testing.T) {
+	s, err := NewShardedCache(8, 100)
+	require.NoError(t, err)
+	s.Add("cached", 0)
+
+	var batches int
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		batches++
+		values := make(map[string]interface{})
+		for _, key := range keys {
+			values[key] = "loaded " + key
+		}
+		return values, nil
+	}
+
+	keys := []string{"cached"}
+	for i := 0; i < 20; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	values, err := s.GetMulti(context.Background(), keys, load)
+	require.NoError(t, err)
+	require.Len(t, values, 21)
+	require.Equal(t, 0, values["cached"])
+	require.Equal(t, 1, batches)
+
+	value, ok := s.Get("7")
+	require.True(t, ok)
+	require.Equal(t, "loaded 7", value)
+
+	_, err = s.GetMulti(context.Background(), keys, load)
+	require.NoError(t, err)
+	require.Equal(t, 1, batches)
+}
+
+func BenchmarkGetMulti(b *testing.B) {
+	c, _ := NewCache(1000)
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Add(keys[i], i)
+	}
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = c.GetMulti(context.Background(), keys, nil)
+		}
+	})
+}
+
+func BenchmarkGetMultiByKey(b *testing.B) {
+	c, _ := NewCache(1000)
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Add(keys[i], i)
+	}
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			values := make(map[string]interface{}, len(keys))
+			for _, key := range keys {
+				if value, ok := c.Get(key); ok {
+					values[key] = value
+				}
+			}
+		}
+	})
+}
+
+func TestGetMultiLoadedMeanwhile(t *testing.T) {
+	var c Cacher
+	c, err := NewCache(10, WithHooks(nil, func(op Op, key string, hit bool, d time.Duration) {
+		if op == OpGet && key == "late" && !hit {
+			c.Add("late", 1)
+		}
+	}))
+	require.NoError(t, err)
+
+	var loaded []string
+	values, err := c.GetMulti(context.Background(), []string{"late", "missing"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		loaded = append(loaded, keys...)
+		return map[string]interface{}{"missing": 2}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"late": 1, "missing": 2}, values)
+	require.Equal(t, []string{"missing"}, loaded)
+}
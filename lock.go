@@ -0,0 +1,89 @@
+package golru
+
+import "time"
+
+// LockStats describes the contention on the cache lock. It is collected only with WithLockMetrics. Contended is the
+// number of acquisitions which had to wait for another operation, Wait is their total waiting time, and Hold is the
+// total time spent under the lock by all the acquisitions
+type LockStats struct {
+	Acquisitions uint64
+	Contended    uint64
+	Wait         time.Duration
+	MaxWait      time.Duration
+	Hold         time.Duration
+	MaxHold      time.Duration
+}
+
+// WithLockMetrics enables measuring of the waiting for the cache lock and of the time spent under it. An uncontended
+// acquisition costs a single clock reading, a contended one costs two more
+func WithLockMetrics() CacheOption {
+	return func(cache *cache) {
+		cache.lockMetrics = true
+	}
+}
+
+// lock acquires the cache lock and counts the waiting if the lock metrics are enabled
+func (c *cache) lock() {
+	if !c.lockMetrics {
+		c.mu.Lock()
+		return
+	}
+
+	if c.mu.TryLock() {
+		c.stats.Locks.Acquisitions++
+		c.lockedAt = time.Now()
+		return
+	}
+
+	start := time.Now()
+	c.mu.Lock()
+	c.lockedAt = time.Now()
+
+	wait := c.lockedAt.Sub(start)
+	c.stats.Locks.Acquisitions++
+	c.stats.Locks.Contended++
+	c.stats.Locks.Wait += wait
+	if wait > c.stats.Locks.MaxWait {
+		c.stats.Locks.MaxWait = wait
+	}
+}
+
+// unlock releases the cache lock and counts the time spent under it if the lock metrics are enabled. The elements
+// evicted under the lock are passed to the eviction callback after it is released
+func (c *cache) unlock() {
+	if c.lockMetrics {
+		hold := time.Since(c.lockedAt)
+		c.stats.Locks.Hold += hold
+		if hold > c.stats.Locks.MaxHold {
+			c.stats.Locks.MaxHold = hold
+		}
+	}
+
+	evicted, hot, audits := c.evicted, c.hotEvents, c.audits
+	c.evicted, c.hotEvents, c.audits, c.staged = nil, nil, nil, nil
+	c.mu.Unlock()
+
+	if len(evicted) != 0 {
+		c.notify(evicted)
+	}
+	if len(audits) != 0 {
+		c.notifyAudit(audits)
+	}
+	if len(hot) != 0 {
+		c.notifyHot(hot)
+	}
+}
+
+// add sums the counters of other lock stats into these ones, keeping the maximums
+func (s *LockStats) add(other LockStats) {
+	s.Acquisitions += other.Acquisitions
+	s.Contended += other.Contended
+	s.Wait += other.Wait
+	s.Hold += other.Hold
+	if other.MaxWait > s.MaxWait {
+		s.MaxWait = other.MaxWait
+	}
+	if other.MaxHold > s.MaxHold {
+		s.MaxHold = other.MaxHold
+	}
+}
@@ -0,0 +1,43 @@
+package golru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockMetrics(t *testing.T) {
+	c, err := NewCache(10, WithLockMetrics())
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("test", 42)
+
+	tc.lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Get("test")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	tc.unlock()
+	<-done
+
+	locks := c.Stats().Locks
+	require.Equal(t, uint64(4), locks.Acquisitions)
+	require.Equal(t, uint64(1), locks.Contended)
+	require.GreaterOrEqual(t, locks.Wait, 10*time.Millisecond)
+	require.Equal(t, locks.Wait, locks.MaxWait)
+	require.GreaterOrEqual(t, locks.MaxHold, 10*time.Millisecond)
+	require.GreaterOrEqual(t, locks.Hold, locks.MaxHold)
+}
+
+func TestLockMetricsDisabled(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	require.Zero(t, c.Stats().Locks)
+}
@@ -0,0 +1,55 @@
+package golru
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger sets the logger for the events of the cache: evictions and janitor sweeps at the debug level, memory
+// pressure and configuration anomalies at the warn level. The cache doesn't log anything without it
+func WithLogger(logger *slog.Logger) CacheOption {
+	return func(cache *cache) {
+		cache.logger = logger
+	}
+}
+
+// String returns the name of the reason used in logs
+func (r EvictReason) String() string {
+	switch r {
+	case EvictedByCapacity:
+		return "capacity"
+	case EvictedByTTL:
+		return "ttl"
+	case EvictedByPressure:
+		return "pressure"
+	case EvictedByClear:
+		return "clear"
+	case EvictedByReplace:
+		return "replace"
+	case EvictedByDependency:
+		return "dependency"
+	default:
+		return "unknown"
+	}
+}
+
+// log writes the event if the logger is set and the level is enabled
+func (c *cache) log(level slog.Level, msg string, args ...interface{}) {
+	if c.logger == nil || !c.logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	c.logger.Log(context.Background(), level, msg, args...)
+}
+
+// checkConfig warns about the combinations of options which are valid, but most likely are mistakes
+func (c *cache) checkConfig() {
+	if c.capacity == 0 && !c.expiring() {
+		c.log(slog.LevelWarn, "golru: unbounded cache without ttl grows without limit")
+	}
+}
+
+// reportDropped warns that the evicted element was dropped because the queue of the eviction workers is full
+func (c *cache) reportDropped(dropped uint64) {
+	c.log(slog.LevelWarn, "golru: eviction queue is full, evicted element dropped", "dropped", dropped)
+}
@@ -0,0 +1,84 @@
+package golru
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is the buffer of the log safe to read while the janitor writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestLogger(t *testing.T) {
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c, err := NewCache(1, WithLogger(logger), WithTTL(0.05))
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	c.Add("second", 2)
+	require.Contains(t, buf.String(), "golru: element evicted")
+	require.Contains(t, buf.String(), "key=first reason=capacity")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Expire(ctx))
+	require.Contains(t, buf.String(), `msg="golru: janitor sweep" removed=0 len=1`)
+
+	time.Sleep(100 * time.Millisecond)
+	c.Get("second")
+	require.Contains(t, buf.String(), "key=second reason=ttl")
+}
+
+func TestLoggerConfigAnomaly(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	_, err := NewCache(0, WithLogger(logger))
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "level=WARN")
+	require.Contains(t, buf.String(), "unbounded cache without ttl")
+
+	buf.Reset()
+	_, err = NewCache(10, WithLogger(logger))
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+}
+
+func TestLoggerLoaderFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	c, err := NewCache(10, WithLogger(logger))
+	require.NoError(t, err)
+
+	_, err = c.GetMulti(context.Background(), []string{"missing"}, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return nil, errors.New("backend is down")
+	})
+	require.Error(t, err)
+	require.Contains(t, buf.String(), `level=WARN msg="golru: loader failed" keys=1 key=missing error="backend is down"`)
+}
@@ -0,0 +1,261 @@
+// Package lrucompat is the adapter with the method set of Cache of github.com/hashicorp/golang-lru/v2 backed by golru,
+// so the code using that cache switches to golru by changing the import:
+//
+//	import lru "github.com/qiwik/golru/lrucompat"
+//
+//	cache, err := lru.New[string, int](128)
+//	evicted := cache.Add("answer", 42)
+//
+// The keys may be of any comparable type like in the original. The keys of the cache with the string keys are stored
+// as they are, the others are stored as their type and Go-syntax representation, so the keys of the different types
+// of the cache with the interface keys never collide, and every element keeps its original key next to the value, so
+// Keys and the eviction callback return the keys of their own type. The options of golru are passed to the constructors,
+// but the options changing the stored values, such as WithCodec and WithCompression, can't encode the elements of the
+// adapter. Unlike the original, ContainsOrAdd and PeekOrAdd check the key and add it in two steps, so the concurrent
+// Add of the same key between them wins
+package lrucompat
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qiwik/golru"
+)
+
+var ErrSize = errors.New("must provide a positive size")
+
+// Cache is the LRU cache of the fixed size with the method set of Cache of github.com/hashicorp/golang-lru/v2. It is
+// safe for the concurrent use
+type Cache[K comparable, V any] struct {
+	cache     golru.Cacher
+	onEvicted func(key K, value V)
+}
+
+// element is the value stored in golru, it keeps the original key of the element
+type element[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New creates the cache of the given size. The options configure the underlying golru cache
+func New[K comparable, V any](size int, opts ...golru.CacheOption) (*Cache[K, V], error) {
+	return NewWithEvict[K, V](size, nil, opts...)
+}
+
+// NewWithEvict creates the cache of the given size with the callback called for every element leaving the cache: the
+// evicted ones, the removed ones and the purged ones, as the original does. The callback replaces the one set by
+// golru.WithOnEvict in the options
+func NewWithEvict[K comparable, V any](size int, onEvicted func(key K, value V), opts ...golru.CacheOption) (*Cache[K, V], error) {
+	if size <= 0 {
+		return nil, ErrSize
+	}
+
+	c := &Cache[K, V]{onEvicted: onEvicted}
+	if onEvicted != nil {
+		opts = append(opts[:len(opts):len(opts)], golru.WithOnEvict(func(_ string, value interface{}, reason golru.EvictReason) {
+			if e, ok := value.(element[K, V]); ok && reason != golru.EvictedByReplace {
+				onEvicted(e.key, e.value)
+			}
+		}))
+	}
+
+	cache, err := golru.NewCache(uint32(size), opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+
+	return c, nil
+}
+
+// Add adds the value to the cache or replaces the value of the existing key, moving it to the top of the list in both
+// cases. Returns true if the oldest element was evicted to make room for the new one
+func (c *Cache[K, V]) Add(key K, value V) (evicted bool) {
+	k, stored := keyOf(key), element[K, V]{key: key, value: value}
+	for {
+		if c.cache.ChangeValue(k, stored) {
+			return false
+		}
+		if _, _, evicted = c.cache.AddReturningEvicted(k, stored); evicted {
+			return true
+		}
+		if _, ok := c.cache.GetEntry(k); ok {
+			return false
+		}
+	}
+}
+
+// Get returns the value of the key and moves it to the top of the list
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	found, ok := c.cache.Get(keyOf(key))
+	if !ok {
+		return value, false
+	}
+
+	return valueOf[K, V](found), true
+}
+
+// Contains reports whether the key is in the cache without moving it
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.cache.GetEntry(keyOf(key))
+
+	return ok
+}
+
+// Peek returns the value of the key without moving it
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	entry, ok := c.cache.GetEntry(keyOf(key))
+	if !ok {
+		return value, false
+	}
+
+	return valueOf[K, V](entry.Value), true
+}
+
+// ContainsOrAdd adds the value if there is no such key. Returns true if the key was already there, and whether the
+// oldest element was evicted by the added one
+func (c *Cache[K, V]) ContainsOrAdd(key K, value V) (ok, evicted bool) {
+	if c.Contains(key) {
+		return true, false
+	}
+
+	_, _, evicted = c.cache.AddReturningEvicted(keyOf(key), element[K, V]{key: key, value: value})
+
+	return false, evicted
+}
+
+// PeekOrAdd returns the value of the existing key without moving it, or adds the given value. Returns true if the key
+// was already there, and whether the oldest element was evicted by the added one
+func (c *Cache[K, V]) PeekOrAdd(key K, value V) (previous V, ok, evicted bool) {
+	if previous, ok = c.Peek(key); ok {
+		return previous, true, false
+	}
+
+	_, _, evicted = c.cache.AddReturningEvicted(keyOf(key), element[K, V]{key: key, value: value})
+
+	return previous, false, evicted
+}
+
+// Remove removes the key from the cache. Returns true if it was there
+func (c *Cache[K, V]) Remove(key K) (present bool) {
+	k := keyOf(key)
+	entry, _ := c.cache.GetEntry(k)
+	if !c.cache.Remove(k) {
+		return false
+	}
+	if c.onEvicted != nil {
+		if e, ok := entry.Value.(element[K, V]); ok {
+			c.onEvicted(e.key, e.value)
+		}
+	}
+
+	return true
+}
+
+// Resize changes the size of the cache evicting the oldest elements which don't fit into it. Returns the number of
+// the evicted elements. The non-positive size is ignored, as the zero capacity of golru would make the cache unbounded
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	if size <= 0 {
+		return 0
+	}
+
+	return c.cache.ChangeCapacity(uint32(size))
+}
+
+// RemoveOldest removes the least recently used element and returns it. The oldest element is found by the snapshot
+// of the whole list, so it takes the time linear in the length of the cache
+func (c *Cache[K, V]) RemoveOldest() (key K, value V, ok bool) {
+	for {
+		entry, found := c.oldest()
+		if !found {
+			return key, value, false
+		}
+		if !c.cache.Remove(entry.Key) {
+			continue
+		}
+
+		e, _ := entry.Value.(element[K, V])
+		if c.onEvicted != nil {
+			c.onEvicted(e.key, e.value)
+		}
+
+		return e.key, e.value, true
+	}
+}
+
+// GetOldest returns the least recently used element without moving it. Like RemoveOldest, it takes the time linear
+// in the length of the cache
+func (c *Cache[K, V]) GetOldest() (key K, value V, ok bool) {
+	entry, found := c.oldest()
+	if !found {
+		return key, value, false
+	}
+
+	e, _ := entry.Value.(element[K, V])
+
+	return e.key, e.value, true
+}
+
+// Keys returns the keys of the cache from the oldest to the newest
+func (c *Cache[K, V]) Keys() []K {
+	entries := c.cache.Entries()
+	keys := make([]K, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e, ok := entries[i].Value.(element[K, V]); ok {
+			keys = append(keys, e.key)
+		}
+	}
+
+	return keys
+}
+
+// Values returns the values of the cache from the oldest to the newest
+func (c *Cache[K, V]) Values() []V {
+	entries := c.cache.Entries()
+	values := make([]V, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e, ok := entries[i].Value.(element[K, V]); ok {
+			values = append(values, e.value)
+		}
+	}
+
+	return values
+}
+
+// Len returns the number of the elements in the cache
+func (c *Cache[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Purge removes all the elements from the cache passing them to the eviction callback
+func (c *Cache[K, V]) Purge() {
+	c.cache.Clear()
+}
+
+// oldest returns the snapshot of the least recently used element
+func (c *Cache[K, V]) oldest() (golru.Entry, bool) {
+	entries := c.cache.Entries()
+	if len(entries) == 0 {
+		return golru.Entry{}, false
+	}
+
+	return entries[len(entries)-1], true
+}
+
+// keyOf returns the key of golru for the key of the adapter. Only the keys of the string type are kept as they are,
+// so the string key of the cache with the interface keys can't collide with the formatted key of another type
+func keyOf[K comparable](key K) string {
+	var zero K
+	if _, ok := any(zero).(string); ok {
+		return any(key).(string)
+	}
+
+	return fmt.Sprintf("%T:%#v", key, key)
+}
+
+// valueOf returns the value of the stored element
+func valueOf[K comparable, V any](stored interface{}) V {
+	e, _ := stored.(element[K, V])
+
+	return e.value
+}
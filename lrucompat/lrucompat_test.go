@@ -0,0 +1,165 @@
+package lrucompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSize(t *testing.T) {
+	_, err := New[string, int](0)
+	require.ErrorIs(t, err, ErrSize)
+}
+
+func TestAddEvicts(t *testing.T) {
+	c, err := New[string, int](2)
+	require.NoError(t, err)
+
+	require.False(t, c.Add("first", 1))
+	require.False(t, c.Add("second", 2))
+	require.False(t, c.Add("first", 10))
+	require.True(t, c.Add("third", 3))
+
+	require.Equal(t, 2, c.Len())
+	require.False(t, c.Contains("second"))
+	value, ok := c.Get("first")
+	require.True(t, ok)
+	require.Equal(t, 10, value)
+	require.Equal(t, []string{"third", "first"}, c.Keys())
+	require.Equal(t, []int{3, 10}, c.Values())
+}
+
+func TestPeekDoesNotMove(t *testing.T) {
+	c, err := New[string, int](2)
+	require.NoError(t, err)
+	c.Add("first", 1)
+	c.Add("second", 2)
+
+	value, ok := c.Peek("first")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+	_, ok = c.Peek("missing")
+	require.False(t, ok)
+
+	c.Add("third", 3)
+	require.False(t, c.Contains("first"))
+}
+
+func TestOrAdd(t *testing.T) {
+	c, err := New[string, int](1)
+	require.NoError(t, err)
+
+	ok, evicted := c.ContainsOrAdd("first", 1)
+	require.False(t, ok)
+	require.False(t, evicted)
+	ok, evicted = c.ContainsOrAdd("first", 10)
+	require.True(t, ok)
+	require.False(t, evicted)
+
+	previous, ok, evicted := c.PeekOrAdd("first", 100)
+	require.True(t, ok)
+	require.False(t, evicted)
+	require.Equal(t, 1, previous)
+
+	_, ok, evicted = c.PeekOrAdd("second", 2)
+	require.False(t, ok)
+	require.True(t, evicted)
+	require.Equal(t, []string{"second"}, c.Keys())
+}
+
+func TestOldest(t *testing.T) {
+	c, err := New[int, string](3)
+	require.NoError(t, err)
+	c.Add(1, "one")
+	c.Add(2, "two")
+	c.Add(3, "three")
+	c.Get(1)
+
+	key, value, ok := c.GetOldest()
+	require.True(t, ok)
+	require.Equal(t, 2, key)
+	require.Equal(t, "two", value)
+
+	key, value, ok = c.RemoveOldest()
+	require.True(t, ok)
+	require.Equal(t, 2, key)
+	require.Equal(t, "two", value)
+	require.Equal(t, []int{3, 1}, c.Keys())
+
+	c.Purge()
+	_, _, ok = c.RemoveOldest()
+	require.False(t, ok)
+	_, _, ok = c.GetOldest()
+	require.False(t, ok)
+}
+
+func TestResize(t *testing.T) {
+	c, err := New[string, int](3)
+	require.NoError(t, err)
+	c.Add("first", 1)
+	c.Add("second", 2)
+	c.Add("third", 3)
+
+	require.Equal(t, 2, c.Resize(1))
+	require.Equal(t, []string{"third"}, c.Keys())
+	require.Equal(t, 0, c.Resize(2))
+	require.False(t, c.Add("fourth", 4))
+	require.True(t, c.Add("fifth", 5))
+
+	require.Zero(t, c.Resize(0))
+	require.Zero(t, c.Resize(-1))
+	require.True(t, c.Add("sixth", 6))
+	require.Equal(t, 2, c.Len())
+}
+
+func TestInterfaceKeys(t *testing.T) {
+	c, err := New[any, string](10)
+	require.NoError(t, err)
+
+	c.Add(1, "int")
+	c.Add("1", "string")
+	c.Add("int:1", "formatted")
+	c.Add(int64(1), "int64")
+	require.Equal(t, 4, c.Len())
+
+	value, ok := c.Get(1)
+	require.True(t, ok)
+	require.Equal(t, "int", value)
+	value, _ = c.Get("int:1")
+	require.Equal(t, "formatted", value)
+	value, _ = c.Get(int64(1))
+	require.Equal(t, "int64", value)
+}
+
+func TestNewWithEvict(t *testing.T) {
+	type key struct {
+		tenant string
+		id     int
+	}
+	evicted := make(map[key]int)
+	c, err := NewWithEvict[key, int](2, func(k key, value int) {
+		evicted[k] = value
+	})
+	require.NoError(t, err)
+
+	c.Add(key{"a", 1}, 1)
+	c.Add(key{"a", 2}, 2)
+	c.Add(key{"a", 2}, 20)
+	require.Empty(t, evicted)
+
+	c.Add(key{"b", 1}, 3)
+	require.Equal(t, map[key]int{{"a", 1}: 1}, evicted)
+
+	require.True(t, c.Remove(key{"a", 2}))
+	require.False(t, c.Remove(key{"a", 2}))
+	require.Equal(t, 20, evicted[key{"a", 2}])
+
+	c.Add(key{"c", 1}, 4)
+	c.RemoveOldest()
+	require.Equal(t, 3, evicted[key{"b", 1}])
+
+	c.Purge()
+	require.Equal(t, 4, evicted[key{"c", 1}])
+	require.Len(t, evicted, 4)
+	require.Zero(t, c.Len())
+}
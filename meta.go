@@ -0,0 +1,28 @@
+package golru
+
+import "time"
+
+// EvictMetaCallback works like EvictCallback and also gets the metadata of the element, see AddWithMeta
+type EvictMetaCallback func(key string, value interface{}, meta interface{}, reason EvictReason)
+
+// WithOnEvictMeta sets the callback called like the one of WithOnEvict, but together with the metadata of the
+// element. Both callbacks can be set, then the one of WithOnEvict is called first
+func WithOnEvictMeta(fn EvictMetaCallback) CacheOption {
+	return func(cache *cache) {
+		cache.onEvictMeta = fn
+	}
+}
+
+// AddWithMeta works like AddE and attaches the metadata to the element, for example the map of the strings or the
+// struct describing the provenance of the value, so the value doesn't need to be wrapped to carry it. The metadata
+// stays with the element when its value is replaced, and is returned by GetEntry and passed to the callback of
+// WithOnEvictMeta as it is, so it must not be changed after it is added. Like GetEntry, it doesn't go through the
+// hooks and the middleware
+func (c *cache) AddWithMeta(key string, value interface{}, meta interface{}) error {
+	return c.addDetailed(key, value, time.Time{}, meta)
+}
+
+// evictCallbacks reports whether any eviction callback is set
+func (c *cache) evictCallbacks() bool {
+	return c.onEvict != nil || c.onEvictMeta != nil
+}
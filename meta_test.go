@@ -0,0 +1,54 @@
+package golru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type provenance struct {
+	Source string
+}
+
+func TestAddWithMeta(t *testing.T) {
+	var evicted []interface{}
+	var plain []string
+	c, err := NewCache(2, WithOnEvictMeta(func(key string, value interface{}, meta interface{}, reason EvictReason) {
+		evicted = append(evicted, meta)
+	}), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		plain = append(plain, key)
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithMeta("user", 1, map[string]string{"source": "db"}))
+	require.NoError(t, c.AddWithMeta("order", 2, provenance{Source: "api"}))
+	require.ErrorIs(t, c.AddWithMeta("user", 3, nil), ErrKeyExists)
+
+	entry, ok := c.GetEntry("user")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"source": "db"}, entry.Meta)
+	require.True(t, c.ChangeValue("user", 4))
+	entry, _ = c.GetEntry("user")
+	require.Equal(t, 4, entry.Value)
+	require.Equal(t, map[string]string{"source": "db"}, entry.Meta)
+
+	c.Add("plain", 5)
+	entry, _ = c.GetEntry("plain")
+	require.Nil(t, entry.Meta)
+	require.Equal(t, []interface{}{provenance{Source: "api"}}, evicted)
+	require.Equal(t, []string{"order"}, plain)
+
+	clone := c.Clone(nil)
+	entry, _ = clone.GetEntry("user")
+	require.Equal(t, map[string]string{"source": "db"}, entry.Meta)
+}
+
+func TestAddWithMetaPooled(t *testing.T) {
+	c, err := NewCache(1, WithItemPool())
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddWithMeta("a", 1, "meta"))
+	c.Add("b", 2)
+	entry, _ := c.GetEntry("b")
+	require.Nil(t, entry.Meta)
+}
@@ -1,10 +1,10 @@
 package golru
 
 import (
-	"container/list"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strconv"
 	"strings"
@@ -17,7 +17,12 @@ const (
 )
 
 var (
-	ErrZeroTTL = errors.New("ttl should be greater than 0")
+	ErrZeroTTL     = errors.New("ttl should be greater than 0")
+	ErrKeyNotFound = errors.New("key doesn't exist in the cache")
+	ErrKeyExists   = errors.New("key already exists in the cache")
+	ErrExpired     = errors.New("lifetime of the element has come to an end")
+	ErrCacheClosed = errors.New("cache is closed")
+	ErrRejected    = errors.New("element was rejected by the cache")
 )
 
 // todo: переделать на свою очередь
@@ -26,118 +31,151 @@ var (
 // When a new element is added, it is placed at the top of the list, and if capacity is reached, the last element,
 // which is also the most unpopular in the cache, is deleted
 func (c *cache) Add(key string, value interface{}) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, err := c.do(OpAdd, key, value)
 
-	if _, ok := c.validate(key); ok {
-		return false
-	}
-
-	if c.chain.Len() == int(c.capacity) {
-		c.removeLast()
-	}
+	return err == nil
+}
 
-	newItem := &item{
-		key:          key,
-		value:        value,
-		creationTime: time.Now(),
-	}
-	newElement := c.chain.PushFront(newItem)
-	c.items[newItem.key] = newElement
+// AddE works like Add, but returns ErrKeyExists instead of false if current key already exists in the cache
+func (c *cache) AddE(key string, value interface{}) error {
+	_, err := c.do(OpAdd, key, value)
 
-	return true
+	return err
 }
 
 // Get func returns a value with true if such element exist with current key, else returns nil and false. If an element
 // exists, it is moved to the top of the list in the cache
 func (c *cache) Get(key string) (interface{}, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	value, err := c.do(OpGet, key, nil)
 
-	element, ok := c.validate(key)
-	if !ok {
+	return value, err == nil
+}
+
+// GetE works like Get, but explains a miss: ErrKeyNotFound is returned if there is no such key, and ErrExpired if
+// the element exists, but its lifetime has come to an end. The expired element is deleted from the cache
+func (c *cache) GetE(key string) (interface{}, error) {
+	return c.do(OpGet, key, nil)
+}
+
+// AddReturningEvicted works like Add and returns the key and the value of the element evicted because of the
+// capacity to make room for the new one, so the caller can handle it without the eviction callback, which still gets
+// it too. Evicted is false if there was room in the cache or the key already exists
+func (c *cache) AddReturningEvicted(key string, value interface{}) (evictedKey string, evictedValue interface{}, evicted bool) {
+	result, err := c.do(OpAddReturningEvicted, key, value)
+	victim, ok := result.(Entry)
+	if err != nil || !ok {
+		return "", nil, false
+	}
+
+	return victim.Key, victim.Value, true
+}
+
+// GetOrAdd returns the existing value of the key with true, or adds the given value and returns it with false,
+// like LoadOrStore of sync.Map. The check and the insertion happen under a single lock, so of the concurrent callers
+// with the same key only one adds its value, and the others get it. The found value counts as a hit and is moved to
+// the top of the list, the added one counts as a miss
+func (c *cache) GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool) {
+	actual, err := c.do(OpGetOrAdd, key, value)
+	if err == ErrKeyExists {
+		return actual, true
+	}
+	if err != nil {
 		return nil, false
 	}
 
-	value := element.Value.(*item).value
-	c.chain.MoveToFront(element)
+	return actual, false
+}
 
-	return value, true
+// Swap replaces the value of the existing key like ChangeValue and returns the previous value with true. Unlike
+// Swap of sync.Map, it doesn't add the absent key and returns false for it, as well as for the leased element
+func (c *cache) Swap(key string, newValue interface{}) (old interface{}, existed bool) {
+	old, err := c.do(OpSwap, key, newValue)
+	if err != nil {
+		return nil, false
+	}
+
+	return old, true
 }
 
 // Remove returns false if current key doesn't exist, and true if removing from cache was successful
 func (c *cache) Remove(key string) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, err := c.do(OpRemove, key, nil)
 
-	element, ok := c.validate(key)
-	if !ok {
-		return false
-	}
+	return err == nil
+}
 
-	delete(c.items, key)
-	c.chain.Remove(element)
+// RemoveE works like Remove, but returns ErrKeyNotFound if there is no such key, ErrExpired if the removed
+// element had already expired, and ErrLeased if the element is leased and can't be removed
+func (c *cache) RemoveE(key string) error {
+	_, err := c.do(OpRemove, key, nil)
 
-	return true
+	return err
 }
 
 // ChangeValue allows you to change the value of a key that already exists in the cache. If there is no such key in
-// the cache, the function returns false. If the value has changed, the element is sent to the top of the cache list
+// the cache or the element is leased, the function returns false. If the value has changed, the element is sent to
+// the top of the cache list
 func (c *cache) ChangeValue(key string, newValue interface{}) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, err := c.do(OpChangeValue, key, newValue)
 
-	element, ok := c.validate(key)
-	if !ok {
-		return false
-	}
-
-	element.Value.(*item).value = newValue
-	element.Value.(*item).creationTime = time.Now()
-	c.chain.MoveToFront(element)
-	c.items[element.Value.(*item).key] = element
-
-	return true
+	return err == nil
 }
 
-// Clear completely clears the cache
+// Clear completely clears the cache. Every element is passed to the eviction callback with the EvictedByClear
+// reason, and the hash table is allocated anew, so the memory taken by a large cache is returned
 func (c *cache) Clear() {
-	for c.chain.Len() > 0 {
-		c.removeLast()
-	}
+	_, _ = c.do(OpClear, "", nil)
 }
 
 // Len allows you to find out the fullness of the cache
 func (c *cache) Len() int {
+	c.lock()
+	defer c.unlock()
+
 	return c.chain.Len()
 }
 
-// ChangeCapacity allows you to dynamically change the cache capacity. The new value must not be less than one. If
-// the new capacity is less than the previous one, then the last elements in the list are deleted up to the desired
-// parameter value
-func (c *cache) ChangeCapacity(newCap uint32) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	switch {
-	case newCap <= 0:
-		return
-	case newCap >= c.capacity:
-		c.capacity = newCap
-		return
-	default:
-		c.capacity = newCap
-		for c.Len() > int(newCap) {
-			c.removeLast()
+// ChangeCapacity allows you to dynamically change the cache capacity. Zero capacity makes the cache unbounded. If
+// the new capacity is less than the previous one, then the last elements in the list are evicted up to the desired
+// parameter value. Returns the number of evicted elements
+func (c *cache) ChangeCapacity(newCap uint32) int {
+	c.lock()
+	defer c.unlock()
+
+	return c.resize(newCap)
+}
+
+// resize sets the new capacity and evicts the last elements which don't fit into it
+func (c *cache) resize(newCap uint32) int {
+	c.capacity = newCap
+
+	return c.trim(EvictedByCapacity)
+}
+
+// trim evicts the last elements which don't fit into the capacity with the given reason
+func (c *cache) trim(reason EvictReason) int {
+	if c.capacity == 0 {
+		return 0
+	}
+
+	evicted := 0
+	for c.chain.Len() > int(c.capacity) {
+		victim := c.tail()
+		if victim == nil {
+			break
 		}
+		c.evict(victim, reason)
+		evicted++
 	}
+
+	return evicted
 }
 
 // Keys returns a slice of the keys that exist in the cache by simply traversing all the keys. Works faster than
 // a function with reflection
 func (c *cache) Keys() []string {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lock()
+	defer c.unlock()
 
 	keys := make([]string, 0, len(c.items))
 	for key := range c.items {
@@ -150,8 +188,8 @@ func (c *cache) Keys() []string {
 // ReflectKeys returns a slice of keys existing in the cache using reflection. It works 3-4 times slower than the Keys
 // function, but is left for variability
 func (c *cache) ReflectKeys() []string {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lock()
+	defer c.unlock()
 
 	keysValues := reflect.ValueOf(c.items).MapKeys()
 	keys := make([]string, 0, len(c.items))
@@ -164,27 +202,33 @@ func (c *cache) ReflectKeys() []string {
 
 // Values returns a slice of all existing element values in the cache
 func (c *cache) Values() []interface{} {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
+	c.lock()
 	values := make([]interface{}, 0, len(c.items))
-	for _, value := range c.items {
-		values = append(values, value)
+	for _, element := range c.items {
+		values = append(values, element.value)
+	}
+	c.unlock()
+
+	if c.transforms() {
+		for i, value := range values {
+			values[i] = c.plain(value)
+		}
 	}
 
 	return values
 }
 
-// Expire starts checking the cache for the existence of expired data. Returns error if ttl is zero
+// Expire starts checking the cache for the existence of expired data. Returns error if neither ttl nor idle timeout
+// is set. The check runs every ttl, minimal adaptive ttl or idle timeout, whichever is shorter
 func (c *cache) Expire(ctx context.Context) error {
-	if c.ttl == 0 {
+	if !c.expiring() {
 		return ErrZeroTTL
 	}
 
 	c.inspect()
 
-	ticker := time.NewTicker(toNanosecond(float64(c.ttl)) * time.Nanosecond)
-	go func() {
+	ticker := time.NewTicker(c.janitorInterval())
+	c.background(ctx, "expire", func(ctx context.Context) {
 		for {
 			select {
 			case <-ticker.C:
@@ -194,47 +238,386 @@ func (c *cache) Expire(ctx context.Context) error {
 				return
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
 // inspect crawls the linked list and deletes data whose lifetime has come to an end
 func (c *cache) inspect() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lock()
+	defer c.unlock()
+
+	now := time.Now()
+	var removed int
+	c.deferCascades(func() {
+		if c.buckets != nil {
+			removed = c.expireBuckets(now)
+		} else {
+			removed = c.sweep(now)
+		}
+	})
+
+	c.log(slog.LevelDebug, "golru: janitor sweep", "removed", removed, "len", c.chain.Len(),
+		"duration", time.Since(now))
+}
 
+// sweep evicts the expired elements traversing the whole list and returns their number
+func (c *cache) sweep(now time.Time) int {
 	current := c.chain.Front()
+	removed := 0
 
 	for current != nil {
-		val := current.Value.(*item)
-		if time.Now().Sub(val.creationTime).Seconds() > float64(c.ttl) {
-			removed := current
-			current = current.Next()
+		if c.expired(current, now) {
+			expired := current
+			current = c.chain.Next(current)
 
-			delete(c.items, val.key)
-			c.chain.Remove(removed)
+			c.evict(expired, EvictedByTTL)
+			removed++
 
 			continue
 		}
 
-		current = current.Next()
+		current = c.chain.Next(current)
 	}
+
+	return removed
 }
 
 // validate checks the existence of an element by the key, and if it does not exist, returns false, instead of an element
-func (c *cache) validate(key string) (element *list.Element, ok bool) {
+func (c *cache) validate(key string) (element *item, ok bool) {
 	if element, ok = c.items[key]; !ok {
 		return nil, false
 	}
 	return element, true
 }
 
-// removeLast deletes the last element in the list
-func (c *cache) removeLast() {
-	currentElement := c.chain.Back()
-	last := c.chain.Remove(currentElement).(*item)
-	delete(c.items, last.key)
+// add puts a new element to the top of the list. An expired element with the same key is replaced
+func (c *cache) add(key string, value interface{}) error {
+	_, _, err := c.addEvicting(key, value)
+
+	return err
+}
+
+// addEvicting works like add and returns the snapshot of the element evicted to make room for the new one
+func (c *cache) addEvicting(key string, value interface{}) (victim Entry, evicted bool, err error) {
+	return c.addAs(key, value, c.tenantOf(key))
+}
+
+// addAs works like addEvicting and attributes the new element to the tenant if the tenancy is enabled
+func (c *cache) addAs(key string, value interface{}, tenant string) (victim Entry, evicted bool, err error) {
+	if c.closed {
+		return Entry{}, false, ErrCacheClosed
+	}
+	if c.tombstoned(key) {
+		return Entry{}, false, ErrTombstoned
+	}
+	if element, ok := c.validate(key); ok {
+		if !c.expired(element, time.Now()) {
+			return Entry{}, false, ErrKeyExists
+		}
+		c.evict(element, EvictedByTTL)
+	}
+
+	newItem := c.newItem(key, value)
+	if c.tenancy != nil {
+		newItem.tenant = c.tenancy.lookup(tenant)
+		if victim, evicted, err = c.admit(newItem.tenant, c.valueBytes(newItem)); err != nil {
+			c.release(newItem)
+			return Entry{}, false, err
+		}
+	}
+
+	if err = c.admitPrefixes(key); err != nil {
+		c.release(newItem)
+		return Entry{}, false, err
+	}
+
+	if c.nursery != nil {
+		if element := c.segmentVictim(); element != nil {
+			victim, evicted = c.snapshot(element), true
+			c.evict(element, EvictedByCapacity)
+		}
+	} else if c.capacity != 0 && c.chain.Len() >= int(c.capacity) {
+		if element := c.victim(); element != nil {
+			victim, evicted = c.snapshot(element), true
+			c.evict(element, EvictedByCapacity)
+		}
+	}
+
+	newItem.creationTime = time.Now()
+	newItem.bornTime = newItem.creationTime
+	c.stamp(newItem)
+	c.internKey(newItem)
+	c.link(newItem)
+	c.enroll(newItem)
+	c.stats.Adds++
+	if newItem.tenant != nil {
+		newItem.tenant.stats.Adds++
+	}
+	c.signalTrim()
+
+	return victim, evicted, nil
+}
+
+// getOrAdd returns the live element of the key with ErrKeyExists, or adds the new one. An expired element is replaced
+func (c *cache) getOrAdd(key string, value interface{}) (interface{}, error) {
+	c.observeRead(key)
+	if element, ok := c.validate(key); ok && !c.expired(element, time.Now()) {
+		found, err := c.access(element, true)
+		if err != nil {
+			return nil, err
+		}
+		return found, ErrKeyExists
+	}
+
+	c.countAccess(false)
+	if err := c.add(key, value); err != nil {
+		return nil, err
+	}
+	c.countCompressed(value)
+
+	return value, nil
+}
+
+// swap replaces the value of the existing element and returns the previous one
+func (c *cache) swap(key string, newValue interface{}) (interface{}, error) {
+	element, ok := c.validate(key)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	old := element.value
+	if err := c.changeValue(key, newValue); err != nil {
+		return nil, err
+	}
+	c.countCompressed(newValue)
+
+	return old, nil
+}
+
+// clear removes all the elements and replaces the hash table and the list by the new ones
+func (c *cache) clear() {
+	for current := c.chain.Front(); current != nil; {
+		next := c.chain.Next(current)
+		c.queueEviction(current, EvictedByClear)
+		c.release(current)
+		current = next
+	}
+
+	c.items = make(map[string]*item)
+	c.chain = newChain()
+	c.hand = nil
+	c.inflation = 0
+	c.resetQueues()
+	c.resetIndexes()
+	c.resetTree()
+	c.resetPrefixes()
+	c.resetCallers()
+	c.resetTenants()
+	c.dependents = nil
+	c.scan = newScanTable(c.scan.seed)
+	if c.buckets != nil {
+		c.buckets = newTTLBuckets(c.buckets.granularity)
+	}
+}
+
+// changeValue replaces the value of the existing element and moves it to the top of the list
+func (c *cache) changeValue(key string, newValue interface{}) error {
+	element, ok := c.validate(key)
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if c.pinned(element, time.Now()) {
+		return ErrLeased
+	}
+
+	c.replaceValue(element, newValue)
+	element.creationTime = time.Now()
+	c.rebucket(element)
+	c.touch(element)
+
+	return nil
+}
+
+// get returns a value of the element and moves it to the top of the list. An expired element is deleted
+func (c *cache) get(key string) (interface{}, error) {
+	element, ok := c.validate(key)
+
+	return c.read(key, element, ok)
+}
+
+// read works like access and passes the read of the key to the curve, the hot key detection and the usage of the
+// tenants, whichever of them are enabled
+func (c *cache) read(key string, element *item, ok bool) (interface{}, error) {
+	c.observeRead(key)
+	value, err := c.access(element, ok)
+	if c.tenancy != nil {
+		c.countTenantRead(key, element, err == nil)
+	}
+
+	return value, err
+}
+
+// countsReads reports whether the reads are observed by anything needing the key as a string
+func (c *cache) countsReads() bool {
+	return c.curve != nil || c.hot != nil || c.tenancy != nil
+}
+
+// access returns a value of the found element and moves it to the top of the list. An expired element is deleted
+func (c *cache) access(element *item, ok bool) (interface{}, error) {
+	if !ok {
+		c.countAccess(false)
+		return nil, ErrKeyNotFound
+	}
+
+	now := time.Now()
+	if c.expired(element, now) {
+		c.countAccess(false)
+		c.evict(element, EvictedByTTL)
+		return nil, ErrExpired
+	}
+
+	c.countAccess(true)
+	if c.expiring() {
+		c.stats.AccessTTL.observe(c.expiration(element).Sub(now))
+	}
+	element.hits++
+	element.lastAccess = now
+	if c.young(element) {
+		c.promote(element)
+	}
+	if c.adaptive != nil || c.idle != 0 {
+		c.rebucket(element)
+	}
+	c.touch(element)
+
+	return element.value, nil
+}
+
+// remove deletes the element by the key
+func (c *cache) remove(key string) error {
+	element, ok := c.validate(key)
+	if !ok {
+		return ErrKeyNotFound
+	}
+
+	now := time.Now()
+	if c.pinned(element, now) {
+		return ErrLeased
+	}
+	if c.expired(element, now) {
+		c.evict(element, EvictedByTTL)
+		return ErrExpired
+	}
+
+	c.removeElement(element)
+	c.queueRemoval(element.key, element.value)
+	c.entomb(element.key)
+	c.release(element)
+
+	return nil
+}
+
+// expired checks whether the lifetime of the element has come to an end at the moment now. The leased element
+// doesn't expire until it exceeds the max lifetime
+func (c *cache) expired(val *item, now time.Time) bool {
+	return c.outlived(val, now) && !c.pinned(val, now) || c.overaged(val, now)
+}
+
+// overaged checks whether the element has exceeded the max lifetime of the cache
+func (c *cache) overaged(val *item, now time.Time) bool {
+	return c.maxAge != 0 && now.Sub(val.bornTime) > c.maxAge
+}
+
+// outlived checks whether the element has outlived the ttl of the cache or its own deadline
+func (c *cache) outlived(val *item, now time.Time) bool {
+	if !val.deadline.IsZero() && now.After(val.deadline) {
+		return true
+	}
+	if c.idle != 0 && now.Sub(lastUse(val)) > c.idle {
+		return true
+	}
+	if c.adaptive != nil {
+		return now.Sub(val.creationTime) > c.adaptive.lifetime(val.hits)
+	}
+
+	return c.ttl != 0 && now.Sub(val.creationTime).Seconds() > float64(c.ttl)
+}
+
+// expiring reports whether the elements of the cache expire by the ttl or the idle timeout of the cache
+func (c *cache) expiring() bool {
+	return c.ttl != 0 || c.adaptive != nil || c.idle != 0 || c.maxAge != 0
+}
+
+// janitorInterval returns the period of the checks started by Expire
+func (c *cache) janitorInterval() time.Duration {
+	interval := c.lifetime
+	if c.adaptive != nil {
+		interval = c.adaptive.Min
+	}
+	if c.idle != 0 && (interval == 0 || c.idle < interval) {
+		interval = c.idle
+	}
+	if c.maxAge != 0 && (interval == 0 || c.maxAge < interval) {
+		interval = c.maxAge
+	}
+
+	return interval
+}
+
+// lastUse returns the moment of the last access or write of the element
+func lastUse(val *item) time.Time {
+	if val.lastAccess.After(val.creationTime) {
+		return val.lastAccess
+	}
+
+	return val.creationTime
+}
+
+// removeElement deletes the element from both the list and the hash table
+func (c *cache) removeElement(element *item) {
+	c.unindex(element)
+	c.uproot(element)
+	c.unbucket(element)
+	c.account(element, -1)
+	c.countPrefixes(element, -1)
+	c.unattribute(element)
+	c.dequeue(element)
+	c.unhand(element)
+	delete(c.items, element.key)
+	c.scan.remove(element)
+	c.chain.Remove(element)
+	c.undepend(element)
+	c.cascade(element.key)
+}
+
+// evict deletes the element from the cache and passes it to the eviction callback
+func (c *cache) evict(element *item, reason EvictReason) {
+	c.removeElement(element)
+
+	age := time.Since(element.creationTime)
+	c.stats.EvictionAge.observe(age)
+
+	switch reason {
+	case EvictedByCapacity:
+		c.stats.Evictions++
+		if element.tenant != nil {
+			element.tenant.stats.Evictions++
+		}
+		if c.youngAge != 0 && age < c.youngAge {
+			c.youngEvictions++
+		}
+	case EvictedByPressure:
+		c.stats.Evictions++
+	case EvictedByTTL:
+		c.stats.Expirations++
+	}
+
+	c.log(slog.LevelDebug, "golru: element evicted", "key", c.redact(element.key), "reason", reason, "age", age)
+
+	c.queueEviction(element, reason)
+	c.release(element)
 }
 
 // toNanosecond is a converter for ttl to time.Duration
@@ -0,0 +1,104 @@
+package golru
+
+import (
+	"errors"
+	"time"
+)
+
+const defaultNurseryHits = 2
+
+var ErrNurseryCapacity = errors.New("nursery capacity should be less than capacity")
+
+// NurseryConfig describes the segment where the new elements live until they prove themselves. Capacity is the part
+// of the capacity of the cache given to it. An element is promoted to the main segment after Hits successful reads
+// (2 by default) or after surviving Age in the cache, if it is set
+type NurseryConfig struct {
+	Capacity uint32
+	Hits     uint64
+	Age      time.Duration
+}
+
+// nursery keeps the young elements in the order of their addition
+type nursery struct {
+	cfg   NurseryConfig
+	young queue
+}
+
+// WithNursery splits the cache into two segments. The added elements go into the nursery, and when it is full, its
+// oldest elements are evicted first, so a bulk load of the elements read only once doesn't wash out the seasoned
+// ones. The promoted elements live in the main segment, which takes the rest of the capacity and is evicted in the
+// LRU order, and it is evicted only if the whole cache is full. The elements copied by Clone and FilterInto go
+// straight to the main segment. With WithTenancy, the nursery takes precedence over the fair choice of the victim
+func WithNursery(cfg NurseryConfig) CacheOption {
+	return func(cache *cache) {
+		if cfg.Capacity == 0 {
+			cache.nursery = nil
+			return
+		}
+		if cfg.Hits == 0 {
+			cfg.Hits = defaultNurseryHits
+		}
+		cache.nursery = &nursery{cfg: cfg}
+	}
+}
+
+// enroll puts the new element to the nursery
+func (c *cache) enroll(element *item) {
+	if c.nursery != nil {
+		c.nursery.young.pushFront(element)
+	}
+}
+
+// young reports whether the element is in the nursery
+func (c *cache) young(element *item) bool {
+	return c.nursery != nil && element.queue == &c.nursery.young
+}
+
+// promote moves the element read successfully to the main segment once it has enough hits
+func (c *cache) promote(element *item) {
+	if element.hits >= c.nursery.cfg.Hits {
+		c.nursery.young.remove(element)
+		c.stats.Promotions++
+	}
+}
+
+// promoteAged moves the elements which have survived the age of the nursery to the main segment
+func (c *cache) promoteAged(now time.Time) {
+	n := c.nursery
+	if n.cfg.Age == 0 {
+		return
+	}
+
+	for n.young.tail != nil && now.Sub(n.young.tail.bornTime) >= n.cfg.Age {
+		n.young.remove(n.young.tail)
+		c.stats.Promotions++
+	}
+}
+
+// segmentVictim returns the element evicted to make room for the new one with the nursery: its oldest unpinned
+// element if the nursery is full, or the last unpinned element of the main segment if the whole cache is full. Returns
+// nil if nothing has to be evicted
+func (c *cache) segmentVictim() *item {
+	now := time.Now()
+	c.promoteAged(now)
+
+	n := c.nursery
+	if n.young.len >= int(n.cfg.Capacity) {
+		for current := n.young.tail; current != nil; current = current.qprev {
+			if current.leases == nil || !c.pinned(current, now) {
+				return current
+			}
+		}
+	}
+	if c.capacity == 0 || c.chain.Len() < int(c.capacity) {
+		return nil
+	}
+
+	for current := c.chain.Back(); current != nil; current = c.chain.Prev(current) {
+		if !c.young(current) && (current.leases == nil || !c.pinned(current, now)) {
+			return current
+		}
+	}
+
+	return c.tail()
+}
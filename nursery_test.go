@@ -0,0 +1,98 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNurseryCapacity(t *testing.T) {
+	_, err := NewCache(4, WithNursery(NurseryConfig{Capacity: 4}))
+	require.ErrorIs(t, err, ErrNurseryCapacity)
+}
+
+func TestNurseryProtectsSeasoned(t *testing.T) {
+	c, err := NewCache(6, WithNursery(NurseryConfig{Capacity: 2}))
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		key := "seasoned" + strconv.Itoa(i)
+		c.Add(key, i)
+		c.Get(key)
+		c.Get(key)
+	}
+	require.Equal(t, uint64(4), c.Stats().Promotions)
+
+	for i := 0; i < 100; i++ {
+		c.Add("bulk"+strconv.Itoa(i), i)
+	}
+	require.Equal(t, 6, c.Len())
+	for i := 0; i < 4; i++ {
+		_, ok := c.GetEntry("seasoned" + strconv.Itoa(i))
+		require.True(t, ok)
+	}
+	_, ok := c.GetEntry("bulk99")
+	require.True(t, ok)
+	_, ok = c.GetEntry("bulk97")
+	require.False(t, ok)
+}
+
+func TestNurseryPromotionByHits(t *testing.T) {
+	c, err := NewCache(3, WithNursery(NurseryConfig{Capacity: 2, Hits: 1}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("first", 1)
+	c.Add("second", 2)
+	require.Equal(t, 2, tc.nursery.young.len)
+
+	c.Get("first")
+	require.Equal(t, 1, tc.nursery.young.len)
+	require.False(t, tc.young(tc.items["first"]))
+
+	c.Add("third", 3)
+	c.Add("fourth", 4)
+	_, ok := c.GetEntry("second")
+	require.False(t, ok)
+
+	c.Get("third")
+	c.Add("fifth", 5)
+	_, ok = c.GetEntry("first")
+	require.False(t, ok)
+	require.Equal(t, 3, c.Len())
+}
+
+func TestNurseryPromotionByAge(t *testing.T) {
+	c, err := NewCache(4, WithNursery(NurseryConfig{Capacity: 2, Age: 20 * time.Millisecond}))
+	require.NoError(t, err)
+
+	c.Add("old", 1)
+	time.Sleep(30 * time.Millisecond)
+	c.Add("first", 2)
+	c.Add("second", 3)
+	c.Add("third", 4)
+
+	_, ok := c.GetEntry("old")
+	require.True(t, ok)
+	_, ok = c.GetEntry("first")
+	require.False(t, ok)
+	require.Equal(t, uint64(1), c.Stats().Promotions)
+}
+
+func TestNurseryRemoveAndClear(t *testing.T) {
+	c, err := NewCache(4, WithNursery(NurseryConfig{Capacity: 2}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("first", 1)
+	c.Add("second", 2)
+	require.True(t, c.Remove("first"))
+	require.Equal(t, 1, tc.nursery.young.len)
+	require.Equal(t, tc.items["second"], tc.nursery.young.tail)
+
+	c.Clear()
+	require.Zero(t, tc.nursery.young.len)
+	require.Nil(t, tc.nursery.young.head)
+}
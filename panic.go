@@ -0,0 +1,50 @@
+package golru
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const (
+	callbackOnEvict    = "on_evict"
+	callbackBeforeHook = "before_hook"
+	callbackAfterHook  = "after_hook"
+	callbackClose      = "close"
+	callbackOnHot      = "on_hot"
+	callbackAudit      = "audit"
+	callbackLoad       = "load"
+)
+
+// PanicHandler receives the value recovered from the panic of a user callback, the name of the callback (on_evict,
+// before_hook, after_hook, close, on_hot, audit or load) and the key it was called with. The panic of the loader of
+// GetMulti is reported with the first key of its batch
+type PanicHandler func(callback string, key string, recovered interface{})
+
+// WithPanicHandler sets the function called when a user callback panics. The panics of the callbacks are always
+// recovered, so they can't break the janitor goroutine or leave the eviction unfinished, and the handler allows you
+// to report them. They are also logged at the error level if the logger is set
+func WithPanicHandler(h PanicHandler) CacheOption {
+	return func(cache *cache) {
+		cache.onPanic = h
+	}
+}
+
+// safely calls the user callback recovering its panic
+func (c *cache) safely(callback string, key string, fn func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			c.reportPanic(callback, key, recovered)
+		}
+	}()
+
+	fn()
+}
+
+// reportPanic logs the panic recovered from the user callback and passes it to the panic handler
+func (c *cache) reportPanic(callback string, key string, recovered interface{}) {
+	c.log(slog.LevelError, "golru: callback panicked", "callback", callback, "key", c.redact(key),
+		"panic", fmt.Sprint(recovered))
+	if c.onPanic != nil {
+		c.onPanic(callback, key, recovered)
+	}
+}
@@ -0,0 +1,61 @@
+package golru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicOnEvict(t *testing.T) {
+	type recovered struct {
+		callback string
+		key      string
+		value    interface{}
+	}
+
+	var panics []recovered
+	c, err := NewCache(1, WithTTL(0.05),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			panic("broken callback")
+		}),
+		WithPanicHandler(func(callback string, key string, value interface{}) {
+			panics = append(panics, recovered{callback: callback, key: key, value: value})
+		}))
+	require.NoError(t, err)
+
+	c.Add("first", 1)
+	require.True(t, c.Add("second", 2))
+	require.Equal(t, 1, c.Len())
+	require.Equal(t, []recovered{{callback: "on_evict", key: "first", value: "broken callback"}}, panics)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Expire(ctx))
+
+	require.Eventually(t, func() bool {
+		return c.Stats().Expirations == 1
+	}, time.Second, 10*time.Millisecond)
+
+	c.Add("third", 3)
+	time.Sleep(150 * time.Millisecond)
+	require.Zero(t, c.Len())
+}
+
+func TestPanicHooks(t *testing.T) {
+	var callbacks []string
+	c, err := NewCache(1,
+		WithHooks(func(op Op, key string) {
+			panic("before")
+		}, func(op Op, key string, hit bool, d time.Duration) {
+			panic("after")
+		}),
+		WithPanicHandler(func(callback string, key string, value interface{}) {
+			callbacks = append(callbacks, callback)
+		}))
+	require.NoError(t, err)
+
+	require.True(t, c.Add("test", 42))
+	require.Equal(t, []string{"before_hook", "after_hook"}, callbacks)
+}
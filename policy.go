@@ -0,0 +1,91 @@
+package golru
+
+import "time"
+
+// EvictionPolicy chooses the element evicted when the cache is full
+type EvictionPolicy int
+
+const (
+	// PolicyLRU evicts the least recently used element. Every access moves the element to the front of the list
+	PolicyLRU EvictionPolicy = iota
+	// PolicySIEVE evicts the elements in the order of their addition, but skips the ones visited since the hand has
+	// passed them last time. An access only marks the element as visited and never changes the list, and the hand
+	// moving from the oldest elements to the newest ones clears the marks as it goes
+	PolicySIEVE
+	// PolicyS3FIFO keeps the new elements in the small queue taking a tenth of the capacity, and moves them to the
+	// main queue only if they are read before they reach its tail. The rest are evicted, but their keys are remembered
+	// as ghosts, and the ghost added again goes straight to the main queue. The main queue reinserts the elements read
+	// since the last pass, so the elements read only once leave the cache fast, and no access changes the list
+	PolicyS3FIFO
+	// PolicyHyperbolic evicts the element with the lowest rate of the accesses over its life in the cache, out of the
+	// random sample of the size set by WithEvictionSamples. The new element is kept until it has the time to prove
+	// itself, and the element popular long ago loses to the one popular now, so it fits the workloads where neither
+	// recency nor frequency alone is right
+	PolicyHyperbolic
+	// PolicyGDSF is GreedyDual-Size-Frequency: it evicts the element with the lowest priority out of the random sample,
+	// where the priority grows with the uses of the element and with its retrieval cost set by WithRetrievalCost, and
+	// falls with its size. The priority of the evicted element becomes the base of the priorities given after that, so
+	// the elements not used for long lose to the ones used recently
+	PolicyGDSF
+)
+
+// WithEvictionPolicy sets the policy choosing the evicted elements, PolicyLRU by default. With the policies other than
+// PolicyLRU the list is kept in the order of the addition, so Entries, ColdestKeys and the victims of WithTenancy and
+// WithNursery follow that order
+func WithEvictionPolicy(policy EvictionPolicy) CacheOption {
+	return func(cache *cache) {
+		cache.policy = policy
+	}
+}
+
+// touch marks the element as used according to the policy
+func (c *cache) touch(element *item) {
+	switch c.policy {
+	case PolicyLRU:
+		c.chain.MoveToFront(element)
+		c.regroup(element)
+	case PolicySIEVE:
+		element.visited = true
+	case PolicyS3FIFO:
+		c.bump(element, min(maxS3Freq, c.freqLimit), time.Now())
+	case PolicyHyperbolic:
+		c.bump(element, c.freqLimit, time.Now())
+	case PolicyGDSF:
+		c.bump(element, c.freqLimit, time.Now())
+		c.prioritize(element)
+	}
+}
+
+// sieveVictim moves the hand to the first unvisited element which isn't leased and returns it, or nil if all of them
+// are leased. Two rounds are enough, as the first one clears all the marks
+func (c *cache) sieveVictim() *item {
+	now := time.Now()
+	hand := c.hand
+	for i := 0; i <= 2*c.chain.Len(); i++ {
+		if hand == nil {
+			if hand = c.chain.Back(); hand == nil {
+				return nil
+			}
+		}
+		if hand.visited {
+			hand.visited = false
+			hand = c.chain.Prev(hand)
+			continue
+		}
+		if hand.leases != nil && c.pinned(hand, now) {
+			hand = c.chain.Prev(hand)
+			continue
+		}
+		c.hand = hand
+		return hand
+	}
+
+	return nil
+}
+
+// unhand moves the hand off the element leaving the list
+func (c *cache) unhand(element *item) {
+	if c.hand == element {
+		c.hand = c.chain.Prev(element)
+	}
+}
@@ -0,0 +1,70 @@
+package golru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func keysOf(entries []Entry) []string {
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+
+	return keys
+}
+
+func TestSIEVE(t *testing.T) {
+	c, err := NewCache(3, WithEvictionPolicy(PolicySIEVE))
+	require.NoError(t, err)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, []string{"c", "b", "a"}, keysOf(c.Entries()))
+
+	c.Add("d", 4)
+	require.Equal(t, []string{"d", "c", "a"}, keysOf(c.Entries()))
+
+	c.Add("e", 5)
+	require.Equal(t, []string{"e", "d", "a"}, keysOf(c.Entries()))
+
+	c.Add("f", 6)
+	require.Equal(t, []string{"f", "e", "a"}, keysOf(c.Entries()))
+}
+
+func TestSIEVESkipsLeased(t *testing.T) {
+	c, err := NewCache(2, WithEvictionPolicy(PolicySIEVE))
+	require.NoError(t, err)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	_, err = c.AcquireLease("a", time.Hour)
+	require.NoError(t, err)
+
+	c.Add("c", 3)
+	require.Equal(t, []string{"c", "a"}, keysOf(c.Entries()))
+}
+
+func TestSIEVEHandFollowsRemoval(t *testing.T) {
+	c, err := NewCache(3, WithEvictionPolicy(PolicySIEVE))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+	c.Get("a")
+	c.Add("d", 4)
+	require.Equal(t, "c", tc.hand.key)
+
+	require.True(t, c.Remove("c"))
+	require.Equal(t, "d", tc.hand.key)
+
+	c.Clear()
+	require.Nil(t, tc.hand)
+}
@@ -0,0 +1,41 @@
+package golru
+
+import "sync"
+
+// itemPool keeps the items released by the caches created with WithItemPool
+var itemPool = sync.Pool{
+	New: func() interface{} {
+		return &item{}
+	},
+}
+
+// WithItemPool makes the cache reuse the items of removed and evicted elements for the new ones, which reduces the
+// garbage produced by caches with a high churn. The key and value are cleared before the item is returned to the pool
+func WithItemPool() CacheOption {
+	return func(cache *cache) {
+		cache.pooled = true
+	}
+}
+
+// newItem returns an item for the new element, taken from the pool if it is enabled
+func (c *cache) newItem(key string, value interface{}) *item {
+	if !c.pooled {
+		return &item{key: key, value: value}
+	}
+
+	it := itemPool.Get().(*item)
+	it.key = key
+	it.value = value
+
+	return it
+}
+
+// release returns the item which has left the cache to the pool if it is enabled
+func (c *cache) release(it *item) {
+	if !c.pooled {
+		return
+	}
+
+	*it = item{}
+	itemPool.Put(it)
+}
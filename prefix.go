@@ -0,0 +1,77 @@
+package golru
+
+import "strings"
+
+// prefixLimit counts the elements with the keys starting with the prefix and keeps them in the list of the group
+type prefixLimit struct {
+	prefix   string
+	max      int
+	entries  int
+	elements group
+}
+
+// prefixLinks returns the accessor of the links of the elements in the list of the i-th prefix limit
+func prefixLinks(i int) func(it *item) *groupLinks {
+	return func(it *item) *groupLinks {
+		return &it.prefixLinks[i]
+	}
+}
+
+// WithPrefixLimit limits the number of the elements with the keys starting with the prefix, for example at most 1000
+// of "session:", so a single family of the keys can't fill the whole cache. Adding the element over the limit evicts
+// the least recently used element with the prefix first, and the element is rejected with ErrRejected if all of them
+// are leased. The option can be given several times, and the key matching several prefixes is counted in each of
+// them. The non-positive max is ignored
+func WithPrefixLimit(prefix string, max int) CacheOption {
+	return func(cache *cache) {
+		if max > 0 {
+			limit := &prefixLimit{prefix: prefix, max: max, elements: group{links: prefixLinks(len(cache.prefixes))}}
+			cache.prefixes = append(cache.prefixes, limit)
+		}
+	}
+}
+
+// admitPrefixes makes room for the new key within the limits of its prefixes
+func (c *cache) admitPrefixes(key string) error {
+	for _, limit := range c.prefixes {
+		if !strings.HasPrefix(key, limit.prefix) {
+			continue
+		}
+		for limit.entries >= limit.max {
+			element := c.groupTail(&limit.elements)
+			if element == nil {
+				return ErrRejected
+			}
+			c.evict(element, EvictedByCapacity)
+		}
+	}
+
+	return nil
+}
+
+// countPrefixes adds the element to the limits of its prefixes and puts it to the front of their lists, or removes it
+// if the sign is negative
+func (c *cache) countPrefixes(element *item, sign int) {
+	for _, limit := range c.prefixes {
+		if !strings.HasPrefix(element.key, limit.prefix) {
+			continue
+		}
+		limit.entries += sign
+		if sign < 0 {
+			limit.elements.remove(element)
+			continue
+		}
+		if element.prefixLinks == nil {
+			element.prefixLinks = make([]groupLinks, len(c.prefixes))
+		}
+		limit.elements.pushFront(element)
+	}
+}
+
+// resetPrefixes sets the counters of the limits to zero and empties their lists after the cache is cleared
+func (c *cache) resetPrefixes() {
+	for _, limit := range c.prefixes {
+		limit.entries = 0
+		limit.elements.front, limit.elements.back = nil, nil
+	}
+}
@@ -0,0 +1,72 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixLimit(t *testing.T) {
+	var evicted []string
+	c, err := NewCache(10, WithPrefixLimit("session:", 2), WithPrefixLimit("session:admin:", 1),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			evicted = append(evicted, key)
+		}))
+	require.NoError(t, err)
+
+	c.Add("session:1", 1)
+	c.Add("user:1", 1)
+	c.Add("session:2", 2)
+	c.Get("session:1")
+	c.Add("session:3", 3)
+	require.Equal(t, []string{"session:2"}, evicted)
+	require.Equal(t, []string{"session:3", "session:1", "user:1"}, keysOf(c.Entries()))
+
+	c.Add("session:admin:1", 1)
+	require.Equal(t, []string{"session:2", "session:1"}, evicted)
+	c.Add("session:admin:2", 2)
+	require.Equal(t, []string{"session:2", "session:1", "session:3", "session:admin:1"}, evicted)
+	require.Equal(t, []string{"session:admin:2", "user:1"}, keysOf(c.Entries()))
+
+	_, err = c.AcquireLease("session:admin:2", time.Minute)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.AddE("session:admin:3", 3), ErrRejected)
+
+	c.Clear()
+	for i := 0; i < 5; i++ {
+		c.Add("user:"+strconv.Itoa(i), i)
+	}
+	c.Add("session:a", 1)
+	c.Add("session:b", 2)
+	require.Equal(t, 7, c.Len())
+	clone := c.Clone(nil)
+	clone.Add("session:c", 3)
+	require.Equal(t, 7, clone.Len())
+	_, ok := clone.GetEntry("session:a")
+	require.False(t, ok)
+}
+
+func TestPrefixLimitList(t *testing.T) {
+	c, err := NewCache(0, WithPrefixLimit("session:", 100))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		c.Add("session:"+strconv.Itoa(i), i)
+		c.Add("user:"+strconv.Itoa(i), i)
+	}
+	c.Remove("session:0")
+	c.Get("session:5")
+	c.Remove("session:9")
+
+	tc := c.(*cache)
+	limit := tc.prefixes[0]
+	var listed []string
+	for current := limit.elements.front; current != nil; current = limit.elements.links(current).next {
+		listed = append(listed, current.key)
+	}
+	require.Equal(t, []string{"session:5", "session:8", "session:7", "session:6", "session:4", "session:3",
+		"session:2", "session:1"}, listed)
+	require.Equal(t, 8, limit.entries)
+}
@@ -0,0 +1,123 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	defaultPressureThreshold = 0.9
+	defaultPressureShed      = 0.1
+)
+
+var (
+	ErrNoMemoryLimit    = errors.New("memory limit is set neither by GOMEMLIMIT nor by config")
+	ErrPressureInterval = errors.New("memory check interval should be greater than 0")
+)
+
+var memoryMetricsSamples = []metrics.Sample{
+	{Name: "/memory/classes/total:bytes"},
+	{Name: "/memory/classes/heap/released:bytes"},
+}
+
+// PressureConfig describes the reaction of the cache to the memory usage of the process. Limit is the memory limit in
+// bytes, GOMEMLIMIT by default. When the usage exceeds Threshold of the limit (0.9 by default), the cache sheds Shed
+// of its elements (0.1 by default) from the tail on every check made each Interval, and regrows by the same share
+// after the pressure is gone. Usage allows you to replace the source of the memory usage, by default it is the memory
+// mapped by the Go runtime and not released to the system, as it is counted against GOMEMLIMIT
+type PressureConfig struct {
+	Interval  time.Duration
+	Limit     uint64
+	Threshold float64
+	Shed      float64
+	Usage     func() uint64
+}
+
+// WatchMemory starts the periodic checks of the memory usage. Returns error if there is no memory limit. Under the
+// pressure the capacity is lowered, and the last elements are evicted with the EvictedByPressure reason. Then the
+// capacity is restored step by step up to its value at the moment of the start. Checks stop when the context is done
+func (c *cache) WatchMemory(ctx context.Context, cfg PressureConfig) error {
+	if cfg.Interval <= 0 {
+		return ErrPressureInterval
+	}
+	if cfg.Limit == 0 {
+		limit := debug.SetMemoryLimit(-1)
+		if limit == math.MaxInt64 {
+			return ErrNoMemoryLimit
+		}
+		cfg.Limit = uint64(limit)
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultPressureThreshold
+	}
+	if cfg.Shed <= 0 || cfg.Shed >= 1 {
+		cfg.Shed = defaultPressureShed
+	}
+	if cfg.Usage == nil {
+		cfg.Usage = memoryUsage
+	}
+
+	c.lock()
+	base := c.capacity
+	c.unlock()
+
+	ticker := time.NewTicker(cfg.Interval)
+	c.background(ctx, "pressure", func(ctx context.Context) {
+		for {
+			select {
+			case <-ticker.C:
+				c.relieve(cfg, base, float64(cfg.Usage()) > float64(cfg.Limit)*cfg.Threshold)
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// relieve sheds the tail of the cache under the pressure, or regrows the capacity up to base without it
+func (c *cache) relieve(cfg PressureConfig, base uint32, pressure bool) {
+	c.lock()
+	defer c.unlock()
+
+	if pressure {
+		length := c.chain.Len()
+		newCap := uint32(float64(length) * (1 - cfg.Shed))
+		if newCap == 0 {
+			newCap = 1
+		}
+		if c.capacity == 0 || newCap < c.capacity {
+			c.capacity = newCap
+			evicted := c.trim(EvictedByPressure)
+			c.log(slog.LevelWarn, "golru: memory pressure, shedding the tail", "evicted", evicted,
+				"capacity", newCap)
+		}
+		return
+	}
+
+	if c.capacity == base {
+		return
+	}
+
+	newCap := c.capacity + uint32(math.Ceil(float64(c.capacity)*cfg.Shed))
+	if base == 0 || newCap > base {
+		newCap = base
+	}
+	c.capacity = newCap
+}
+
+// memoryUsage returns the memory mapped by the Go runtime minus the memory released back to the system
+func memoryUsage() uint64 {
+	samples := make([]metrics.Sample, len(memoryMetricsSamples))
+	copy(samples, memoryMetricsSamples)
+	metrics.Read(samples)
+
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
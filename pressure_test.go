@@ -0,0 +1,64 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchMemoryNoLimit(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.ErrorIs(t, c.WatchMemory(ctx, PressureConfig{}), ErrPressureInterval)
+	require.ErrorIs(t, c.WatchMemory(ctx, PressureConfig{Interval: time.Second}), ErrNoMemoryLimit)
+}
+
+func TestWatchMemoryShedAndRegrow(t *testing.T) {
+	var reasons []EvictReason
+	c, err := NewCache(10, WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		reasons = append(reasons, reason)
+	}))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	for i := 0; i < 10; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+
+	var usage uint64 = 100
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = c.WatchMemory(ctx, PressureConfig{
+		Interval: 10 * time.Millisecond,
+		Limit:    100,
+		Shed:     0.5,
+		Usage:    func() uint64 { return atomic.LoadUint64(&usage) },
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		tc.mu.Lock()
+		defer tc.mu.Unlock()
+		return tc.chain.Len() < 10
+	}, time.Second, 5*time.Millisecond)
+	atomic.StoreUint64(&usage, 0)
+
+	require.Eventually(t, func() bool {
+		tc.mu.Lock()
+		defer tc.mu.Unlock()
+		return tc.capacity == 10
+	}, time.Second, 5*time.Millisecond)
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	require.Equal(t, EvictedByPressure, reasons[0])
+}
@@ -0,0 +1,62 @@
+package golru
+
+// queue is the list of the elements linked through the queue links of the items, apart from the main list of the
+// cache. The head is the newest element and the tail is the oldest one. An element is in a single queue at most
+type queue struct {
+	head, tail *item
+	len        int
+}
+
+// pushFront puts the element to the head of the queue
+func (q *queue) pushFront(element *item) {
+	element.queue = q
+	element.qprev = nil
+	element.qnext = q.head
+	if q.head != nil {
+		q.head.qprev = element
+	}
+	q.head = element
+	if q.tail == nil {
+		q.tail = element
+	}
+	q.len++
+}
+
+// remove takes the element out of the queue
+func (q *queue) remove(element *item) {
+	if element.qprev != nil {
+		element.qprev.qnext = element.qnext
+	} else {
+		q.head = element.qnext
+	}
+	if element.qnext != nil {
+		element.qnext.qprev = element.qprev
+	} else {
+		q.tail = element.qprev
+	}
+	element.queue = nil
+	element.qprev, element.qnext = nil, nil
+	q.len--
+}
+
+// reset empties the queue
+func (q *queue) reset() {
+	q.head, q.tail, q.len = nil, nil, 0
+}
+
+// dequeue takes the element leaving the cache out of its queue
+func (c *cache) dequeue(element *item) {
+	if element.queue != nil {
+		element.queue.remove(element)
+	}
+}
+
+// resetQueues empties the queues after the cache is cleared
+func (c *cache) resetQueues() {
+	if c.nursery != nil {
+		c.nursery.young.reset()
+	}
+	if c.s3 != nil {
+		c.s3.reset()
+	}
+}
@@ -0,0 +1,39 @@
+package golru
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// WithKeyRedactor sets the function applied to the keys leaving the process: the output of Dump, the logged events
+// and the records of the trace recorder, so the keys holding personal data can be hashed or masked. The redactor
+// should map the same key to the same string, otherwise the events of the key can't be correlated and the sampled
+// trace is no longer usable for the simulation. The keys returned by the methods and passed to the callbacks are kept
+// as they are
+func WithKeyRedactor(fn func(key string) string) CacheOption {
+	return func(cache *cache) {
+		cache.redactor = fn
+	}
+}
+
+// HashKeys returns the redactor replacing the key by the hex of its HMAC-SHA256 with the secret truncated to 16
+// bytes. The same key gets the same hash, so the redacted keys stay correlatable, while the secret prevents guessing
+// the keys by hashing the candidates
+func HashKeys(secret []byte) func(key string) string {
+	return func(key string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(key))
+
+		return hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+}
+
+// redact returns the key as it is shown outside of the process
+func (c *cache) redact(key string) string {
+	if c.redactor == nil {
+		return key
+	}
+
+	return c.redactor(key)
+}
@@ -0,0 +1,44 @@
+package golru
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRedactor(t *testing.T) {
+	var logs, trace, dump bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	rec := NewTraceRecorder(&trace, TraceCSV, 1)
+	mask := func(key string) string {
+		return key[:2] + strings.Repeat("*", len(key)-2)
+	}
+
+	c, err := NewCache(1, WithLogger(logger), WithTraceRecorder(rec), WithKeyRedactor(mask))
+	require.NoError(t, err)
+
+	c.Add("alice@example.com", 1)
+	c.Add("bob@example.com", 2)
+	require.NoError(t, c.Dump(&dump))
+	require.NoError(t, rec.Flush())
+
+	require.Contains(t, logs.String(), "key=al***************")
+	require.Contains(t, dump.String(), `key="bo*************"`)
+	require.Contains(t, trace.String(), ",add,bo*************,1")
+	for _, out := range []string{logs.String(), dump.String(), trace.String()} {
+		require.NotContains(t, out, "example.com")
+	}
+	require.Equal(t, []string{"bob@example.com"}, c.Keys())
+}
+
+func TestHashKeys(t *testing.T) {
+	hash := HashKeys([]byte("secret"))
+
+	require.Len(t, hash("alice"), 32)
+	require.Equal(t, hash("alice"), hash("alice"))
+	require.NotEqual(t, hash("alice"), hash("bob"))
+	require.NotEqual(t, hash("alice"), HashKeys([]byte("other"))("alice"))
+}
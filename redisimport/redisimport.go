@@ -0,0 +1,196 @@
+// Package redisimport copies the keyspace of a Redis instance into a golru cache, keeping the remaining lifetime of
+// every key. It doesn't depend on a Redis client: Client is the small part of the client used by the import, and the
+// adapter of any client library is a few lines, for example for github.com/redis/go-redis:
+//
+//	type goRedis struct{ rdb *redis.Client }
+//
+//	func (g goRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+//		return g.rdb.Scan(ctx, cursor, match, count).Result()
+//	}
+//
+//	func (g goRedis) Get(ctx context.Context, key string) (string, bool, error) {
+//		value, err := g.rdb.Get(ctx, key).Result()
+//		if err == redis.Nil {
+//			return "", false, nil
+//		}
+//		return value, err == nil, err
+//	}
+//
+//	func (g goRedis) PTTL(ctx context.Context, key string) (time.Duration, error) {
+//		return g.rdb.PTTL(ctx, key).Result()
+//	}
+package redisimport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// Client is the part of the Redis client used by the import. Scan runs SCAN with the cursor, the pattern and the
+// count hint. Get runs GET and reports false if there is no such key, the error of the key holding the value other
+// than the string must keep the WRONGTYPE prefix of the reply of Redis, as the errors of the clients do. PTTL runs
+// PTTL, so it returns -1 in the units of the duration for the key without the expiration and -2 for the missing key,
+// as Redis does
+type Client interface {
+	Scan(ctx context.Context, cursor uint64, match string, count int64) (keys []string, next uint64, err error)
+	Get(ctx context.Context, key string) (value string, found bool, err error)
+	PTTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Cache is the part of the cache the keys are imported to, golru.Cacher satisfies it
+type Cache interface {
+	AddE(key string, value interface{}) error
+	AddWithDeadline(key string, value interface{}, deadline time.Time) error
+}
+
+// Config describes the import. Match is the pattern of SCAN, all the keys by default, and Count is its count hint, 100
+// by default. Rate limits the number of the keys read per second, so the import doesn't overload the instance, zero
+// means no limit. Decode turns the string value of the key into the value of the cache, the string is added as it is
+// without it. Prefix is prepended to the keys in the cache
+type Config struct {
+	Match  string
+	Count  int64
+	Rate   float64
+	Decode func(key, value string) (interface{}, error)
+	Prefix string
+}
+
+// Result counts the keys of the import. Scanned is the number of the keys returned by SCAN, Imported is the number of
+// the keys added to the cache, and Skipped is the number of the keys which disappeared or expired during the import,
+// or were already in the cache. WrongType is the number of the keys skipped because they hold the lists, the hashes
+// or the other values GET can't read
+type Result struct {
+	Scanned   int
+	Imported  int
+	Skipped   int
+	WrongType int
+}
+
+// Import scans the keys of the instance and adds their values to the cache. The key with the expiration is added with
+// the deadline of its remaining lifetime, so it expires in the cache at the same moment as in Redis. SCAN may return
+// the same key more than once, but it is added only once. The keys of the types other than the string are skipped
+// and counted in WrongType. Returns the counts of the keys processed so far together
+// with the first error of the client, of the decoder, of the cache, or of the context
+func Import(ctx context.Context, client Client, cache Cache, cfg Config) (Result, error) {
+	if cfg.Match == "" {
+		cfg.Match = "*"
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = 100
+	}
+
+	var result Result
+	pace := newPacer(cfg.Rate)
+	defer pace.stop()
+
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, cfg.Match, cfg.Count)
+		if err != nil {
+			return result, err
+		}
+		result.Scanned += len(keys)
+
+		for _, key := range keys {
+			if err = pace.wait(ctx); err != nil {
+				return result, err
+			}
+			imported, err := importKey(ctx, client, cache, cfg, key)
+			if isWrongType(err) {
+				result.WrongType++
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			if imported {
+				result.Imported++
+			} else {
+				result.Skipped++
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return result, nil
+		}
+	}
+}
+
+// importKey adds the single key to the cache, reporting whether it was added
+func importKey(ctx context.Context, client Client, cache Cache, cfg Config, key string) (bool, error) {
+	ttl, err := client.PTTL(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if ttl == -2 || ttl == 0 {
+		return false, nil
+	}
+	deadline := time.Now().Add(ttl)
+
+	raw, found, err := client.Get(ctx, key)
+	if err != nil || !found {
+		return false, err
+	}
+
+	var value interface{} = raw
+	if cfg.Decode != nil {
+		if value, err = cfg.Decode(key, raw); err != nil {
+			return false, err
+		}
+	}
+
+	if ttl < 0 {
+		err = cache.AddE(cfg.Prefix+key, value)
+	} else {
+		err = cache.AddWithDeadline(cfg.Prefix+key, value, deadline)
+	}
+	if errors.Is(err, golru.ErrKeyExists) || errors.Is(err, golru.ErrExpired) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// isWrongType reports whether the error is the reply of Redis to GET of the key holding the value of another type
+func isWrongType(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "WRONGTYPE")
+}
+
+// pacer spaces the reads of the keys evenly to keep their rate
+type pacer struct {
+	ticker *time.Ticker
+}
+
+// newPacer returns the pacer of the rate per second, the one which never waits for the non-positive rate
+func newPacer(rate float64) *pacer {
+	if rate <= 0 {
+		return &pacer{}
+	}
+
+	return &pacer{ticker: time.NewTicker(time.Duration(float64(time.Second) / rate))}
+}
+
+// wait waits for the next read to be allowed
+func (p *pacer) wait(ctx context.Context) error {
+	if p.ticker == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-p.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop releases the ticker of the pacer
+func (p *pacer) stop() {
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
+}
@@ -0,0 +1,135 @@
+package redisimport
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis serves SCAN by the pages of the sorted keys, returning the last key of every page again on the next one
+type fakeRedis struct {
+	values map[string]string
+	lists  map[string]bool
+	ttls   map[string]time.Duration
+	page   int
+	scans  int
+}
+
+func (f *fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	f.scans++
+	keys := make([]string, 0, len(f.values))
+	for key := range f.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	start := int(cursor)
+	if start > 0 {
+		start--
+	}
+	end := start + f.page
+	if end >= len(keys) {
+		return keys[start:], 0, nil
+	}
+
+	return keys[start:end], uint64(end), nil
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) (string, bool, error) {
+	if f.lists[key] {
+		return "", false, errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	}
+	value, ok := f.values[key]
+	return value, ok, nil
+}
+
+func (f *fakeRedis) PTTL(ctx context.Context, key string) (time.Duration, error) {
+	if _, ok := f.values[key]; !ok {
+		return -2, nil
+	}
+	if ttl, ok := f.ttls[key]; ok {
+		return ttl, nil
+	}
+
+	return -1, nil
+}
+
+func TestImport(t *testing.T) {
+	redis := &fakeRedis{
+		values: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5"},
+		ttls:   map[string]time.Duration{"b": time.Hour, "c": 0},
+		page:   2,
+	}
+	cache, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	result, err := Import(context.Background(), redis, cache, Config{
+		Prefix: "redis:",
+		Decode: func(key, value string) (interface{}, error) {
+			return strconv.Atoi(value)
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, Result{Scanned: 8, Imported: 4, Skipped: 4}, result)
+	require.Equal(t, 4, redis.scans)
+
+	entry, ok := cache.GetEntry("redis:b")
+	require.True(t, ok)
+	require.Equal(t, 2, entry.Value)
+	require.WithinDuration(t, time.Now().Add(time.Hour), entry.Expiration, time.Second)
+	entry, _ = cache.GetEntry("redis:a")
+	require.True(t, entry.Expiration.IsZero())
+	_, ok = cache.GetEntry("redis:c")
+	require.False(t, ok)
+}
+
+func TestImportWrongType(t *testing.T) {
+	redis := &fakeRedis{
+		values: map[string]string{"a": "1", "b": "", "c": "3", "d": ""},
+		lists:  map[string]bool{"b": true, "d": true},
+		page:   10,
+	}
+	cache, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	result, err := Import(context.Background(), redis, cache, Config{})
+	require.NoError(t, err)
+	require.Equal(t, Result{Scanned: 4, Imported: 2, WrongType: 2}, result)
+	keys := cache.Keys()
+	sort.Strings(keys)
+	require.Equal(t, []string{"a", "c"}, keys)
+}
+
+func TestImportRateAndErrors(t *testing.T) {
+	redis := &fakeRedis{values: map[string]string{"a": "1", "b": "x", "c": "3"}, page: 10}
+	cache, err := golru.NewCache(10)
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, err := Import(context.Background(), redis, cache, Config{Rate: 100})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Imported)
+	require.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond)
+
+	bad := errors.New("bad value")
+	decode := func(key, value string) (interface{}, error) {
+		if value == "x" {
+			return nil, bad
+		}
+		return value, nil
+	}
+	result, err = Import(context.Background(), redis, cache, Config{Prefix: "2:", Decode: decode})
+	require.ErrorIs(t, err, bad)
+	require.Equal(t, Result{Scanned: 3, Imported: 1}, result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Import(ctx, redis, cache, Config{Rate: 10, Prefix: "3:"})
+	require.ErrorIs(t, err, context.Canceled)
+}
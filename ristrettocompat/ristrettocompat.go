@@ -0,0 +1,199 @@
+// Package ristrettocompat is the facade of golru with the surface of Cache of github.com/dgraph-io/ristretto, so a
+// migration between the two libraries can be compared in production behind the single Cacher interface:
+//
+//	var cache ristrettocompat.Cacher
+//	if useGolru {
+//		cache, err = ristrettocompat.NewCache(&ristrettocompat.Config{MaxCost: 1 << 30})
+//	} else {
+//		cache, err = ristretto.NewCache(&ristretto.Config{NumCounters: 1e7, MaxCost: 1 << 30, BufferItems: 64})
+//	}
+//
+// The total cost of the elements is limited by MaxCost, and the least recently used elements are evicted to make room
+// for the new one instead of the admission by the frequency of ristretto. Unlike ristretto, Set is applied before it
+// returns, so Wait has nothing to wait for, and the element larger than MaxCost is the only one Set drops
+package ristrettocompat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+var ErrMaxCost = errors.New("MaxCost can't be zero")
+
+// Cacher is the surface of Cache of ristretto shared by the facade and the original, so the code using it doesn't
+// depend on the library behind it
+type Cacher interface {
+	Get(key interface{}) (interface{}, bool)
+	Set(key, value interface{}, cost int64) bool
+	SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool
+	Del(key interface{})
+	Wait()
+	Clear()
+	Close()
+}
+
+// Item is the element passed to OnEvict
+type Item struct {
+	Key   interface{}
+	Value interface{}
+	Cost  int64
+}
+
+// Config describes the cache like Config of ristretto. NumCounters and BufferItems are accepted for the compatibility
+// and ignored, as the facade neither counts the frequencies nor buffers the writes. MaxCost limits the total cost of
+// the elements, and Cost gives the cost of the value added with zero cost. OnEvict is called for the elements evicted
+// by the cost, the lifetime or Clear, but not for the deleted ones. Options configure the underlying golru cache
+type Config struct {
+	NumCounters int64
+	MaxCost     int64
+	BufferItems int64
+	Cost        func(value interface{}) int64
+	OnEvict     func(item *Item)
+	Options     []golru.CacheOption
+}
+
+// Cache is the facade of golru with the surface of Cache of ristretto
+type Cache struct {
+	cache   golru.Cacher
+	maxCost int64
+	cost    func(value interface{}) int64
+}
+
+// costed is the value stored in golru with the original key and the cost of the element
+type costed struct {
+	key   interface{}
+	value interface{}
+	cost  int64
+}
+
+// NewCache creates the cache by the config. The cost is limited by the quota of the single tenant all the elements
+// belong to, so the options must not set WithTenancy or WithWeigher
+func NewCache(cfg *Config) (*Cache, error) {
+	if cfg.MaxCost <= 0 {
+		return nil, ErrMaxCost
+	}
+
+	opts := append(cfg.Options[:len(cfg.Options):len(cfg.Options)],
+		golru.WithWeigher(func(_ string, value interface{}) uint64 {
+			c, _ := value.(costed)
+			return uint64(c.cost)
+		}),
+		golru.WithTenancy(golru.TenancyConfig{
+			Tenant:  func(string) string { return "" },
+			Default: golru.TenantQuota{MaxCost: uint64(cfg.MaxCost)},
+		}),
+	)
+	if onEvict := cfg.OnEvict; onEvict != nil {
+		opts = append(opts, golru.WithOnEvict(func(_ string, value interface{}, reason golru.EvictReason) {
+			if c, ok := value.(costed); ok && reason != golru.EvictedByReplace {
+				onEvict(&Item{Key: c.key, Value: c.value, Cost: c.cost})
+			}
+		}))
+	}
+
+	cache, err := golru.NewCache(0, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{cache: cache, maxCost: cfg.MaxCost, cost: cfg.Cost}, nil
+}
+
+// Get returns the value of the key and moves it to the top of the list
+func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	value, ok := c.cache.Get(keyOf(key))
+	if !ok {
+		return nil, false
+	}
+	stored, _ := value.(costed)
+
+	return stored.value, true
+}
+
+// GetTTL returns the remaining lifetime of the key, zero if it never expires
+func (c *Cache) GetTTL(key interface{}) (time.Duration, bool) {
+	entry, ok := c.cache.GetEntry(keyOf(key))
+	if !ok {
+		return 0, false
+	}
+	if entry.Expiration.IsZero() {
+		return 0, true
+	}
+
+	return time.Until(entry.Expiration), true
+}
+
+// Set puts the value with the cost replacing the existing one of the key, see SetWithTTL
+func (c *Cache) Set(key, value interface{}, cost int64) bool {
+	return c.SetWithTTL(key, value, cost, 0)
+}
+
+// SetWithTTL puts the value with the cost and the lifetime replacing the existing one of the key, evicting the least
+// recently used elements until the total cost fits into MaxCost. Zero cost is replaced by the one given by Cost of the
+// config, and zero ttl means the element never expires. Returns false if the ttl is negative or the cost exceeds
+// MaxCost, then the cache doesn't change
+func (c *Cache) SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool {
+	if ttl < 0 {
+		return false
+	}
+	if cost == 0 && c.cost != nil {
+		cost = c.cost(value)
+	}
+	if cost < 0 {
+		cost = 0
+	}
+	if cost > c.maxCost {
+		return false
+	}
+
+	k, stored := keyOf(key), costed{key: key, value: value, cost: cost}
+	for {
+		var err error
+		if ttl == 0 {
+			err = c.cache.AddE(k, stored)
+		} else {
+			err = c.cache.AddWithDeadline(k, stored, time.Now().Add(ttl))
+		}
+		if !errors.Is(err, golru.ErrKeyExists) {
+			return err == nil
+		}
+		if err = c.cache.RemoveE(k); errors.Is(err, golru.ErrLeased) {
+			return false
+		}
+	}
+}
+
+// Del removes the key
+func (c *Cache) Del(key interface{}) {
+	c.cache.Remove(keyOf(key))
+}
+
+// Wait returns at once, as Set is applied before it returns
+func (c *Cache) Wait() {}
+
+// Clear removes all the elements passing them to OnEvict
+func (c *Cache) Clear() {
+	c.cache.Clear()
+}
+
+// Close closes the cache, after that Get misses and Set drops every element
+func (c *Cache) Close() {
+	_ = c.cache.Close()
+}
+
+// keyOf returns the key of golru for the key of ristretto. Like the hashing of ristretto, the byte slices are the
+// same keys as the strings with their bytes, and the integers of all the types with the same value are the same keys.
+// The other keys are prefixed by the zero byte, so they don't collide with the strings of the same digits
+func keyOf(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	default:
+		return "\x00" + fmt.Sprint(k)
+	}
+}
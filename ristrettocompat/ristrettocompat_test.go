@@ -0,0 +1,102 @@
+package ristrettocompat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ Cacher = (*Cache)(nil)
+
+func TestNewCacheMaxCost(t *testing.T) {
+	_, err := NewCache(&Config{})
+	require.ErrorIs(t, err, ErrMaxCost)
+}
+
+func TestSetGetDel(t *testing.T) {
+	c, err := NewCache(&Config{NumCounters: 100, MaxCost: 10, BufferItems: 64})
+	require.NoError(t, err)
+
+	require.True(t, c.Set("key", "first", 1))
+	require.True(t, c.Set("key", "second", 1))
+	c.Wait()
+	value, ok := c.Get("key")
+	require.True(t, ok)
+	require.Equal(t, "second", value)
+
+	require.True(t, c.Set([]byte("bytes"), 1, 1))
+	value, ok = c.Get("bytes")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	require.True(t, c.Set(uint64(7), "seven", 1))
+	value, ok = c.Get(7)
+	require.True(t, ok)
+	require.Equal(t, "seven", value)
+	_, ok = c.Get("7")
+	require.False(t, ok)
+
+	c.Del("key")
+	_, ok = c.Get("key")
+	require.False(t, ok)
+}
+
+func TestCostEvicts(t *testing.T) {
+	var evicted []interface{}
+	c, err := NewCache(&Config{
+		MaxCost: 10,
+		Cost:    func(value interface{}) int64 { return int64(len(value.(string))) },
+		OnEvict: func(item *Item) { evicted = append(evicted, item.Key) },
+	})
+	require.NoError(t, err)
+
+	require.True(t, c.Set("first", "aaaa", 0))
+	require.True(t, c.Set("second", "bbbb", 0))
+	_, ok := c.Get("first")
+	require.True(t, ok)
+	require.True(t, c.Set("third", "cccc", 0))
+	require.Equal(t, []interface{}{"second"}, evicted)
+
+	require.False(t, c.Set("huge", "x", 11))
+	_, ok = c.Get("first")
+	require.True(t, ok)
+
+	c.Del("first")
+	c.Clear()
+	require.Equal(t, []interface{}{"second", "third"}, evicted)
+}
+
+func TestSetWithTTL(t *testing.T) {
+	c, err := NewCache(&Config{MaxCost: 10})
+	require.NoError(t, err)
+
+	require.False(t, c.SetWithTTL("key", 1, 1, -time.Second))
+	require.True(t, c.SetWithTTL("key", 1, 1, time.Hour))
+	ttl, ok := c.GetTTL("key")
+	require.True(t, ok)
+	require.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	require.True(t, c.Set("forever", 1, 1))
+	ttl, ok = c.GetTTL("forever")
+	require.True(t, ok)
+	require.Zero(t, ttl)
+
+	require.True(t, c.SetWithTTL("key", 1, 1, 20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Get("key")
+	require.False(t, ok)
+	_, ok = c.GetTTL("missing")
+	require.False(t, ok)
+}
+
+func TestClose(t *testing.T) {
+	c, err := NewCache(&Config{MaxCost: 10})
+	require.NoError(t, err)
+
+	require.True(t, c.Set("key", 1, 1))
+	c.Close()
+	_, ok := c.Get("key")
+	require.False(t, ok)
+	require.False(t, c.Set("other", 1, 1))
+}
@@ -0,0 +1,149 @@
+package golru
+
+import (
+	"errors"
+	"time"
+)
+
+const maxS3Freq = 3
+
+var ErrNurseryPolicy = errors.New("nursery can't be used with the S3-FIFO policy")
+
+// s3fifo keeps the queues of PolicyS3FIFO: the small one for the new elements, the main one for the elements read
+// while they were in the small queue, and the ghost keys of the elements evicted from the small queue
+type s3fifo struct {
+	small, main queue
+	ghost       ghost
+}
+
+// ghost is the bounded FIFO of the keys without the values. The keys taken out of it stay in the order until they are
+// popped, the sequence numbers tell them from the keys added again later
+type ghost struct {
+	seqs  map[string]uint64
+	order []ghostKey
+	head  int
+	next  uint64
+}
+
+// ghostKey is the key in the order of the ghost with the sequence number it was added with
+type ghostKey struct {
+	key string
+	seq uint64
+}
+
+// newS3FIFO creates the empty queues
+func newS3FIFO() *s3fifo {
+	return &s3fifo{ghost: ghost{seqs: make(map[string]uint64)}}
+}
+
+// reset empties the queues and the ghost
+func (s *s3fifo) reset() {
+	s.small.reset()
+	s.main.reset()
+	s.ghost = ghost{seqs: make(map[string]uint64)}
+}
+
+// add remembers the key dropping the oldest ones above the limit
+func (g *ghost) add(key string, limit int) {
+	g.next++
+	g.seqs[key] = g.next
+	g.order = append(g.order, ghostKey{key: key, seq: g.next})
+
+	for len(g.seqs) > limit || len(g.order)-g.head > 2*limit {
+		oldest := g.order[g.head]
+		g.order[g.head] = ghostKey{}
+		g.head++
+		if g.seqs[oldest.key] == oldest.seq {
+			delete(g.seqs, oldest.key)
+		}
+	}
+	if g.head > len(g.order)/2 {
+		g.order = append(g.order[:0], g.order[g.head:]...)
+		g.head = 0
+	}
+}
+
+// take reports whether the key is remembered and forgets it
+func (g *ghost) take(key string) bool {
+	if _, ok := g.seqs[key]; !ok {
+		return false
+	}
+	delete(g.seqs, key)
+
+	return true
+}
+
+// enqueue puts the new element to the main queue if its key is a ghost, or to the small queue otherwise
+func (c *cache) enqueue(element *item) {
+	if c.s3 == nil {
+		return
+	}
+
+	if c.s3.ghost.take(element.key) {
+		c.s3.main.pushFront(element)
+		return
+	}
+	c.s3.small.pushFront(element)
+}
+
+// s3Limits returns the target length of the small queue, a tenth of the capacity, and the limit of the main queue and
+// of the ghost, the rest of it. The unbounded cache takes them from its current length
+func (c *cache) s3Limits() (small, main int) {
+	total := int(c.capacity)
+	if total == 0 {
+		total = c.chain.Len()
+	}
+	small = total / 10
+	if small == 0 {
+		small = 1
+	}
+	main = total - small
+	if main <= 0 {
+		main = 1
+	}
+
+	return small, main
+}
+
+// s3Victim returns the next element evicted by S3-FIFO, or nil if all of them are leased. The tail of the small queue
+// moves to the main one if it was read, and is evicted leaving its ghost otherwise. The tail of the main queue is
+// reinserted while its frequency is above zero, losing one on every pass. The leased elements are reinserted too
+func (c *cache) s3Victim() *item {
+	now := time.Now()
+	s := c.s3
+	small, main := c.s3Limits()
+	for i := 0; i <= (maxS3Freq+2)*c.chain.Len(); i++ {
+		if s.small.tail != nil && (s.small.len > small || s.main.tail == nil) {
+			element := s.small.tail
+			pinned := element.leases != nil && c.pinned(element, now)
+			if !pinned && c.frequency(element) == 0 {
+				s.ghost.add(element.key, main)
+				return element
+			}
+			s.small.remove(element)
+			if pinned {
+				s.small.pushFront(element)
+				continue
+			}
+			element.freq = 0
+			s.main.pushFront(element)
+			continue
+		}
+
+		element := s.main.tail
+		if element == nil {
+			return nil
+		}
+		pinned := element.leases != nil && c.pinned(element, now)
+		if !pinned && c.frequency(element) == 0 {
+			return element
+		}
+		if f := c.frequency(element); f > 0 {
+			element.freq = f - 1
+		}
+		s.main.remove(element)
+		s.main.pushFront(element)
+	}
+
+	return nil
+}
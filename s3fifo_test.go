@@ -0,0 +1,92 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3FIFOKeepsReadElements(t *testing.T) {
+	c, err := NewCache(10, WithEvictionPolicy(PolicyS3FIFO))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		key := "hot" + strconv.Itoa(i)
+		c.Add(key, i)
+		_, ok := c.Get(key)
+		require.True(t, ok)
+	}
+	for i := 0; i < 100; i++ {
+		c.Add("scan"+strconv.Itoa(i), i)
+	}
+
+	require.Equal(t, 10, c.Len())
+	for i := 0; i < 5; i++ {
+		_, ok := c.GetEntry("hot" + strconv.Itoa(i))
+		require.True(t, ok)
+	}
+	_, ok := c.GetEntry("scan99")
+	require.True(t, ok)
+}
+
+func TestS3FIFOGhost(t *testing.T) {
+	c, err := NewCache(20, WithEvictionPolicy(PolicyS3FIFO))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	for i := 0; i < 21; i++ {
+		c.Add(strconv.Itoa(i), i)
+	}
+	_, ok := c.GetEntry("0")
+	require.False(t, ok)
+	require.NotZero(t, tc.s3.ghost.seqs["0"])
+
+	c.Add("0", 0)
+	require.Equal(t, &tc.s3.main, tc.items["0"].queue)
+	_, ok = tc.s3.ghost.seqs["0"]
+	require.False(t, ok)
+}
+
+func TestS3FIFOGhostLimit(t *testing.T) {
+	g := ghost{seqs: make(map[string]uint64)}
+	for i := 0; i < 100; i++ {
+		g.add(strconv.Itoa(i), 10)
+	}
+	require.Len(t, g.seqs, 10)
+	require.False(t, g.take("89"))
+	require.True(t, g.take("90"))
+
+	for i := 0; i < 100; i++ {
+		g.add("again", 10)
+		require.True(t, g.take("again"))
+	}
+	require.LessOrEqual(t, len(g.order)-g.head, 20)
+}
+
+func TestS3FIFOQueues(t *testing.T) {
+	c, err := NewCache(10, WithEvictionPolicy(PolicyS3FIFO))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	for i := 0; i < 30; i++ {
+		key := strconv.Itoa(i)
+		c.Add(key, i)
+		if i%3 == 0 {
+			c.Get(key)
+		}
+		if i%7 == 0 {
+			c.Remove(key)
+		}
+		require.Equal(t, c.Len(), tc.s3.small.len+tc.s3.main.len)
+	}
+
+	c.Clear()
+	require.Zero(t, tc.s3.small.len+tc.s3.main.len)
+	require.Empty(t, tc.s3.ghost.seqs)
+}
+
+func TestS3FIFOWithNursery(t *testing.T) {
+	_, err := NewCache(10, WithEvictionPolicy(PolicyS3FIFO), WithNursery(NurseryConfig{Capacity: 2}))
+	require.ErrorIs(t, err, ErrNurseryPolicy)
+}
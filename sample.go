@@ -0,0 +1,71 @@
+package golru
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const defaultEvictionSamples = 64
+
+// WithEvictionSamples sets the number of the elements sampled at random to choose the victim of the sampling policies,
+// like PolicyHyperbolic, 64 by default. The larger sample is closer to the exact policy, but costs more on every
+// eviction
+func WithEvictionSamples(n int) CacheOption {
+	return func(cache *cache) {
+		cache.samples = n
+	}
+}
+
+// sampledVictim returns the element with the lowest priority out of the random sample, the expired elements go first.
+// The leased elements are skipped, and if all the sampled ones are leased, the last unpinned element of the list is
+// returned
+func (c *cache) sampledVictim(priority func(element *item, now time.Time) float64) *item {
+	size := c.chain.Len()
+	if size == 0 {
+		return nil
+	}
+
+	n := c.samples
+	if n <= 0 {
+		n = defaultEvictionSamples
+	}
+
+	now := time.Now()
+	var victim *item
+	lowest := math.Inf(1)
+	for i := 0; i < n; i++ {
+		element := c.chain.At(rand.Intn(size))
+		if element.leases != nil && c.pinned(element, now) {
+			continue
+		}
+		if c.expired(element, now) {
+			return element
+		}
+		if p := priority(element, now); victim == nil || p < lowest {
+			victim, lowest = element, p
+		}
+	}
+	if victim != nil {
+		return victim
+	}
+
+	for current := c.chain.Back(); current != nil; current = c.chain.Prev(current) {
+		if current.leases == nil || !c.pinned(current, now) {
+			return current
+		}
+	}
+
+	return nil
+}
+
+// hyperbolic returns the priority of the element by PolicyHyperbolic: its frequency counter plus one for the addition
+// per second of its life in the cache
+func (c *cache) hyperbolic(element *item, now time.Time) float64 {
+	age := now.Sub(element.bornTime).Seconds()
+	if age <= 0 {
+		return math.Inf(1)
+	}
+
+	return float64(c.frequency(element)+1) / age
+}
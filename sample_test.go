@@ -0,0 +1,59 @@
+package golru
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperbolicPriority(t *testing.T) {
+	c := &cache{}
+	now := time.Now()
+	require.Equal(t, math.Inf(1), c.hyperbolic(&item{bornTime: now}, now))
+	require.Equal(t, 2.0, c.hyperbolic(&item{bornTime: now.Add(-time.Second), freq: 1}, now))
+
+	old := &item{bornTime: now.Add(-time.Hour), freq: 100}
+	recent := &item{bornTime: now.Add(-time.Minute), freq: 10}
+	require.Less(t, c.hyperbolic(old, now), c.hyperbolic(recent, now))
+}
+
+func TestHyperbolicEvictsLowestRate(t *testing.T) {
+	c, err := NewCache(4, WithEvictionPolicy(PolicyHyperbolic), WithEvictionSamples(100))
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		c.Add(strconv.Itoa(i), i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		if i != 2 {
+			c.Get(strconv.Itoa(i))
+			c.Get(strconv.Itoa(i))
+		}
+	}
+
+	c.Add("new", 4)
+	_, ok := c.GetEntry("2")
+	require.False(t, ok)
+	require.Equal(t, 4, c.Len())
+}
+
+func TestSampledVictimSkipsLeased(t *testing.T) {
+	c, err := NewCache(2, WithEvictionPolicy(PolicyHyperbolic))
+	require.NoError(t, err)
+
+	c.Add("a", 1)
+	time.Sleep(time.Millisecond)
+	c.Add("b", 2)
+	_, err = c.AcquireLease("a", time.Hour)
+	require.NoError(t, err)
+
+	c.Add("c", 3)
+	_, ok := c.GetEntry("a")
+	require.True(t, ok)
+	_, ok = c.GetEntry("b")
+	require.False(t, ok)
+}
@@ -0,0 +1,206 @@
+package golru
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor is the opaque position of the key listing returned by KeysPage. The zero cursor starts the listing, and
+// the zero cursor returned back means that the listing is over
+type Cursor string
+
+// minScanSlots is the initial number of the slots of the scan table
+const minScanSlots = 16
+
+// KeysPage returns up to limit keys following the cursor and the cursor of the next page. Like SCAN of Redis, the
+// keys are listed in the order of their hashes, which doesn't depend on the size of the table keeping them, so every
+// key which stays in the cache during the whole listing is returned exactly once, and the cursor is just the
+// position of the last returned key in that order. The lock is held only while a single page is collected, and the
+// page takes the time of its own keys and of the empty slots between them rather than of the whole cache. Keys added
+// or removed between the calls may or may not be returned
+func (c *cache) KeysPage(cursor Cursor, limit int) ([]string, Cursor) {
+	if limit <= 0 {
+		return nil, cursor
+	}
+
+	after, afterKey, started := cursor.position()
+
+	c.lock()
+	defer c.unlock()
+
+	t := c.scan
+	keys := make([]string, 0, min(limit, len(c.items)))
+	var last *item
+	slot := uint64(0)
+	if started {
+		slot = after >> t.shift
+	}
+	for ; slot < uint64(len(t.slots)) && len(keys) < limit; slot++ {
+		for it := t.slots[slot]; it != nil && len(keys) < limit; it = it.snext {
+			if started && !follows(it, after, afterKey) {
+				continue
+			}
+			keys = append(keys, it.key)
+			last = it
+		}
+	}
+	if len(keys) < limit {
+		return keys, ""
+	}
+
+	return keys, newCursor(last.position, last.key)
+}
+
+// newCursor returns the cursor pointing after the key at the position
+func newCursor(position uint64, key string) Cursor {
+	return Cursor(strconv.FormatUint(position, 16) + ":" + key)
+}
+
+// position returns the position and the key after which the listing continues and false for the starting or the
+// malformed cursor
+func (c Cursor) position() (uint64, string, bool) {
+	hex, key, ok := strings.Cut(string(c), ":")
+	if !ok {
+		return 0, "", false
+	}
+	position, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return position, key, true
+}
+
+// scanTable keeps the elements in the slots by their hashes for KeysPage. The position of the element is the bit
+// reversal of the hash of its key, the slot is the top bits of the position, and every slot is sorted by the
+// positions and the keys, so walking the slots in order lists the elements in the order of their positions whatever
+// the number of the slots is. The table doubles when the elements outnumber the slots and never shrinks until Clear
+type scanTable struct {
+	seed  maphash.Seed
+	slots []*item
+	shift uint
+}
+
+// newScanTable returns the empty table hashing the keys with the seed
+func newScanTable(seed maphash.Seed) *scanTable {
+	return &scanTable{seed: seed, slots: make([]*item, minScanSlots), shift: 64 - uint(bits.Len(minScanSlots-1))}
+}
+
+// insert puts the element to its slot, growing the table first if the cache holds more than size elements
+func (t *scanTable) insert(it *item, size int) {
+	if size > len(t.slots) {
+		t.grow()
+	}
+
+	it.position = bits.Reverse64(maphash.String(t.seed, it.key))
+	link := &t.slots[it.position>>t.shift]
+	for *link != nil && follows(it, (*link).position, (*link).key) {
+		link = &(*link).snext
+	}
+	it.snext, *link = *link, it
+}
+
+// remove deletes the element from its slot
+func (t *scanTable) remove(it *item) {
+	link := &t.slots[it.position>>t.shift]
+	for *link != nil && *link != it {
+		link = &(*link).snext
+	}
+	if *link == it {
+		*link = it.snext
+	}
+	it.snext = nil
+}
+
+// grow doubles the number of the slots. Every slot splits into two adjacent ones keeping its order
+func (t *scanTable) grow() {
+	slots := make([]*item, len(t.slots)*2)
+	shift := t.shift - 1
+	for i, head := range t.slots {
+		tails := [2]**item{&slots[2*i], &slots[2*i+1]}
+		for it := head; it != nil; {
+			next := it.snext
+			half := it.position >> shift & 1
+			it.snext = nil
+			*tails[half] = it
+			tails[half] = &it.snext
+			it = next
+		}
+	}
+	t.slots, t.shift = slots, shift
+}
+
+// follows reports whether the element goes after the given position and key in the order of the listing
+func follows(it *item, position uint64, key string) bool {
+	if it.position != position {
+		return it.position > position
+	}
+
+	return it.key > key
+}
+
+// RandomKey returns the key of a live element chosen uniformly at random, or false if there are no live elements.
+// Like GetEntry, it doesn't count as an access
+func (c *cache) RandomKey() (string, bool) {
+	c.lock()
+	entries := c.sample(1)
+	c.unlock()
+	if len(entries) == 0 {
+		return "", false
+	}
+
+	return entries[0].Key, true
+}
+
+// SampleEntries returns the snapshots of up to n distinct live elements chosen uniformly at random, in random
+// order. The elements are picked by index, so a small sample doesn't scan the whole cache, and the sampled
+// elements are not counted as accessed
+func (c *cache) SampleEntries(n int) []Entry {
+	c.lock()
+	entries := c.sample(n)
+	c.unlock()
+
+	return c.reveal(entries)
+}
+
+// sample takes the snapshots of the elements picked by SampleEntries under the lock
+func (c *cache) sample(n int) []Entry {
+	size := c.chain.Len()
+	if n <= 0 || size == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]Entry, 0, min(n, size))
+	pick := func(i int) {
+		if element := c.chain.At(i); !c.expired(element, now) {
+			entries = append(entries, c.snapshot(element))
+		}
+	}
+
+	if n*2 >= size {
+		for _, i := range rand.Perm(size) {
+			if len(entries) == n {
+				break
+			}
+			pick(i)
+		}
+		return entries
+	}
+
+	seen := make(map[int]struct{}, n)
+	for len(entries) < n && len(seen) < size {
+		i := rand.Intn(size)
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		pick(i)
+	}
+
+	return entries
+}
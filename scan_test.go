@@ -0,0 +1,157 @@
+package golru
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysPage(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	expected := make([]string, 0, 25)
+	for i := 0; i < 25; i++ {
+		key := strconv.Itoa(i)
+		c.Add(key, i)
+		expected = append(expected, key)
+	}
+	c.Add("", "empty")
+	expected = append(expected, "")
+	sort.Strings(expected)
+
+	var (
+		keys   []string
+		cursor Cursor
+		pages  int
+	)
+	for {
+		var page []string
+		page, cursor = c.KeysPage(cursor, 10)
+		require.LessOrEqual(t, len(page), 10)
+		keys = append(keys, page...)
+		pages++
+		if cursor == "" {
+			break
+		}
+		c.Add("new"+strconv.Itoa(pages), pages)
+	}
+
+	require.Equal(t, 3, pages)
+	require.Subset(t, keys, expected)
+	require.Len(t, keys, len(keySet(keys)))
+}
+
+// keySet returns the distinct keys
+func keySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+
+	return set
+}
+
+func TestKeysPageGrowth(t *testing.T) {
+	c, err := NewCache(0)
+	require.NoError(t, err)
+
+	expected := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		key := strconv.Itoa(100 + i)
+		c.Add(key, i)
+		expected = append(expected, key)
+	}
+
+	keys, cursor := c.KeysPage("", 5)
+	var removed string
+	for _, key := range expected {
+		if _, seen := keySet(keys)[key]; !seen {
+			removed = key
+			break
+		}
+	}
+	require.True(t, c.Remove(removed))
+	for i := 0; cursor != ""; i++ {
+		for j := 0; i < 3 && j < 50; j++ {
+			c.Add("new"+strconv.Itoa(i*50+j), j)
+		}
+		var page []string
+		page, cursor = c.KeysPage(cursor, 5)
+		keys = append(keys, page...)
+	}
+
+	require.Greater(t, len(c.(*cache).scan.slots), minScanSlots)
+	require.Len(t, keys, len(keySet(keys)))
+	for _, key := range expected {
+		_, seen := keySet(keys)[key]
+		require.Equal(t, key != removed, seen, key)
+	}
+}
+
+func TestKeysPageEmpty(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	keys, cursor := c.KeysPage("", 10)
+	require.Empty(t, keys)
+	require.Equal(t, Cursor(""), cursor)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	keys, cursor = c.KeysPage("", 2)
+	require.ElementsMatch(t, []string{"a", "b"}, keys)
+	keys, cursor = c.KeysPage(cursor, 2)
+	require.Empty(t, keys)
+	require.Equal(t, Cursor(""), cursor)
+}
+
+func TestRandomKey(t *testing.T) {
+	c, err := NewCache(10)
+	require.NoError(t, err)
+
+	_, ok := c.RandomKey()
+	require.False(t, ok)
+
+	for i := 0; i < 10; i++ {
+		c.Add(strconv.Itoa(i), i)
+	}
+	c.Remove("3")
+	c.Remove("0")
+
+	seen := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		key, ok := c.RandomKey()
+		require.True(t, ok)
+		seen[key]++
+	}
+	require.Len(t, seen, 8)
+	require.NotContains(t, seen, "3")
+	for _, count := range seen {
+		require.Greater(t, count, 150)
+	}
+	require.Zero(t, c.Stats().Hits)
+}
+
+func TestSampleEntries(t *testing.T) {
+	c, err := NewCache(100)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		c.Add(strconv.Itoa(i), i)
+	}
+
+	for _, n := range []int{5, 70, 200} {
+		entries := c.SampleEntries(n)
+		require.Len(t, entries, min(n, 100))
+		keys := make(map[string]struct{})
+		for _, entry := range entries {
+			keys[entry.Key] = struct{}{}
+			require.Equal(t, entry.Key, strconv.Itoa(entry.Value.(int)))
+		}
+		require.Len(t, keys, len(entries))
+	}
+	require.Empty(t, c.SampleEntries(0))
+}
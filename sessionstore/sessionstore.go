@@ -0,0 +1,234 @@
+// Package sessionstore keeps the HTTP sessions in golru, so a small service doesn't need Redis for them. The store has
+// the method set of Store of github.com/gorilla/sessions with its own Session of the same shape, so the handlers
+// written for it change only the import:
+//
+//	store, _ := sessionstore.NewStore(sessionstore.Config{MaxSessions: 10000, IdleTimeout: 30 * time.Minute})
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		session, _ := store.Get(r, "session")
+//		session.Values["user"] = "alice"
+//		_ = session.Save(r, w)
+//	}
+//
+// The cookie keeps only the random identifier of the session, and the values are kept in the cache encoded by gob,
+// so the types other than the basic ones are registered by gob.Register, as for the stores of gorilla. The expiration
+// is sliding: the session expires after IdleTimeout without the requests reading or saving it. Unlike gorilla, every
+// Get decodes the session anew, so the handlers of a request share it by passing it around
+package sessionstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+var ErrSessionTooLarge = errors.New("session exceeds the size limit of the store")
+
+// Options are the attributes of the session cookie, as Options of gorilla. MaxAge is the lifetime of the cookie in
+// seconds, zero makes it the session cookie, and the negative one deletes the session on Save
+type Options struct {
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// Config describes the store. MaxSessions is the capacity of the cache, the least recently used sessions are evicted
+// beyond it, and zero means no limit. IdleTimeout is the sliding expiration of the sessions, and MaxLifetime is the
+// hard limit of their lifetime regardless of the activity, zero means no limit for both. MaxSessionBytes limits the
+// encoded values of a session, Save fails with ErrSessionTooLarge beyond it. Cookie is the default of the options of
+// the new sessions, the HttpOnly cookie of the root path with SameSite=Lax if it is nil. CacheOptions configure the
+// underlying cache
+type Config struct {
+	MaxSessions     uint32
+	IdleTimeout     time.Duration
+	MaxLifetime     time.Duration
+	MaxSessionBytes int
+	Cookie          *Options
+	CacheOptions    []golru.CacheOption
+}
+
+// Store keeps the sessions in the cache
+type Store struct {
+	cache    golru.Cacher
+	maxBytes int
+	cookie   Options
+}
+
+// Session is the session of a client, as Session of gorilla
+type Session struct {
+	ID      string
+	Values  map[interface{}]interface{}
+	Options *Options
+	IsNew   bool
+	store   *Store
+	name    string
+}
+
+// NewStore creates the store by the config
+func NewStore(cfg Config) (*Store, error) {
+	opts := cfg.CacheOptions[:len(cfg.CacheOptions):len(cfg.CacheOptions)]
+	if cfg.IdleTimeout > 0 {
+		opts = append(opts, golru.WithIdleTimeout(cfg.IdleTimeout))
+	}
+	if cfg.MaxLifetime > 0 {
+		opts = append(opts, golru.WithMaxLifetime(cfg.MaxLifetime))
+	}
+	cache, err := golru.NewCache(cfg.MaxSessions, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cookie := Options{Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	if cfg.Cookie != nil {
+		cookie = *cfg.Cookie
+	}
+
+	return &Store{cache: cache, maxBytes: cfg.MaxSessionBytes, cookie: cookie}, nil
+}
+
+// Cache returns the underlying cache, for example to run Expire removing the idle sessions in the background
+func (s *Store) Cache() golru.Cacher {
+	return s.cache
+}
+
+// Get returns the session of the request by the cookie with the name, reading it renews its idle timeout. The request
+// without the cookie, or with the one of the session which has expired, gets a new session. The session which can't be
+// decoded is returned as a new one with the error
+func (s *Store) Get(r *http.Request, name string) (*Session, error) {
+	return s.New(r, name)
+}
+
+// New returns the session of the request like Get, it is the method of Store of gorilla called by Get
+func (s *Store) New(r *http.Request, name string) (*Session, error) {
+	session := s.newSession(name)
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	value, ok := s.cache.Get(cookie.Value)
+	if !ok {
+		return session, nil
+	}
+	data, _ := value.([]byte)
+	values := make(map[interface{}]interface{})
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return session, err
+	}
+	session.ID, session.Values, session.IsNew = cookie.Value, values, false
+
+	return session, nil
+}
+
+// Save stores the values of the session and sets its cookie. The session with the negative MaxAge is deleted, and its
+// cookie is expired. The new session gets its identifier on the first Save
+func (s *Store) Save(_ *http.Request, w http.ResponseWriter, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			s.cache.Remove(session.ID)
+		}
+		http.SetCookie(w, s.cookieOf(session, ""))
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+	if s.maxBytes > 0 && buf.Len() > s.maxBytes {
+		return ErrSessionTooLarge
+	}
+
+	if session.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+	}
+	if err := s.put(session.ID, buf.Bytes()); err != nil {
+		return err
+	}
+	http.SetCookie(w, s.cookieOf(session, session.ID))
+
+	return nil
+}
+
+// Name returns the name of the session cookie
+func (session *Session) Name() string {
+	return session.name
+}
+
+// Store returns the store of the session
+func (session *Session) Store() *Store {
+	return session.store
+}
+
+// Save saves the session to its store, see Save of Store
+func (session *Session) Save(r *http.Request, w http.ResponseWriter) error {
+	return session.store.Save(r, w, session)
+}
+
+// newSession returns the empty new session with the default options
+func (s *Store) newSession(name string) *Session {
+	opts := s.cookie
+
+	return &Session{Values: make(map[interface{}]interface{}), Options: &opts, IsNew: true, store: s, name: name}
+}
+
+// put stores the encoded values of the session replacing the previous ones, which renews its idle timeout. Returns
+// ErrLeased if the session is leased in the underlying cache
+func (s *Store) put(id string, data []byte) error {
+	if s.cache.ChangeValue(id, data) {
+		return nil
+	}
+	if err := s.cache.AddE(id, data); !errors.Is(err, golru.ErrKeyExists) {
+		return err
+	}
+	if !s.cache.ChangeValue(id, data) {
+		return golru.ErrLeased
+	}
+
+	return nil
+}
+
+// cookieOf returns the cookie of the session with the value
+func (s *Store) cookieOf(session *Session, value string) *http.Cookie {
+	opts := session.Options
+	cookie := &http.Cookie{
+		Name:     session.name,
+		Value:    value,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		MaxAge:   opts.MaxAge,
+		Secure:   opts.Secure,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	}
+	if opts.MaxAge > 0 {
+		cookie.Expires = time.Now().Add(time.Duration(opts.MaxAge) * time.Second)
+	} else if opts.MaxAge < 0 {
+		cookie.Expires = time.Unix(1, 0)
+	}
+
+	return cookie
+}
+
+// newID returns the random identifier of the session
+func newID() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,135 @@
+package sessionstore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip saves the session and returns the request carrying the cookie set by the response
+func roundTrip(t *testing.T, session *Session) (*http.Request, *http.Cookie) {
+	w := httptest.NewRecorder()
+	require.NoError(t, session.Save(httptest.NewRequest(http.MethodGet, "/", nil), w))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+
+	return r, cookies[0]
+}
+
+func TestSaveGet(t *testing.T) {
+	store, err := NewStore(Config{MaxSessions: 10})
+	require.NoError(t, err)
+
+	session, err := store.Get(httptest.NewRequest(http.MethodGet, "/", nil), "session")
+	require.NoError(t, err)
+	require.True(t, session.IsNew)
+	require.Equal(t, "session", session.Name())
+	require.Same(t, store, session.Store())
+	session.Values["user"] = "alice"
+	session.Values[1] = 2
+
+	r, cookie := roundTrip(t, session)
+	require.Equal(t, "session", cookie.Name)
+	require.Equal(t, session.ID, cookie.Value)
+	require.True(t, cookie.HttpOnly)
+	require.Equal(t, "/", cookie.Path)
+
+	loaded, err := store.Get(r, "session")
+	require.NoError(t, err)
+	require.False(t, loaded.IsNew)
+	require.Equal(t, session.ID, loaded.ID)
+	require.Equal(t, "alice", loaded.Values["user"])
+	require.Equal(t, 2, loaded.Values[1])
+
+	loaded.Values["user"] = "bob"
+	r, _ = roundTrip(t, loaded)
+	loaded, err = store.Get(r, "session")
+	require.NoError(t, err)
+	require.Equal(t, "bob", loaded.Values["user"])
+	require.Equal(t, session.ID, loaded.ID)
+}
+
+func TestUnknownCookie(t *testing.T) {
+	store, err := NewStore(Config{})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "forged"})
+	session, err := store.Get(r, "session")
+	require.NoError(t, err)
+	require.True(t, session.IsNew)
+	require.Empty(t, session.ID)
+
+	_, cookie := roundTrip(t, session)
+	require.NotEqual(t, "forged", cookie.Value)
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	store, err := NewStore(Config{IdleTimeout: 60 * time.Millisecond})
+	require.NoError(t, err)
+
+	session, err := store.New(httptest.NewRequest(http.MethodGet, "/", nil), "session")
+	require.NoError(t, err)
+	r, _ := roundTrip(t, session)
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		loaded, err := store.Get(r, "session")
+		require.NoError(t, err)
+		require.False(t, loaded.IsNew)
+	}
+
+	time.Sleep(90 * time.Millisecond)
+	loaded, err := store.Get(r, "session")
+	require.NoError(t, err)
+	require.True(t, loaded.IsNew)
+}
+
+func TestSizeLimits(t *testing.T) {
+	store, err := NewStore(Config{MaxSessions: 1, MaxSessionBytes: 100})
+	require.NoError(t, err)
+
+	session, err := store.New(httptest.NewRequest(http.MethodGet, "/", nil), "session")
+	require.NoError(t, err)
+	session.Values["data"] = strings.Repeat("x", 200)
+	err = session.Save(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	require.ErrorIs(t, err, ErrSessionTooLarge)
+
+	session.Values["data"] = "x"
+	first, _ := roundTrip(t, session)
+	other, err := store.New(httptest.NewRequest(http.MethodGet, "/", nil), "session")
+	require.NoError(t, err)
+	roundTrip(t, other)
+
+	loaded, err := store.Get(first, "session")
+	require.NoError(t, err)
+	require.True(t, loaded.IsNew)
+	require.Equal(t, 1, store.Cache().Len())
+}
+
+func TestDelete(t *testing.T) {
+	store, err := NewStore(Config{})
+	require.NoError(t, err)
+
+	session, err := store.New(httptest.NewRequest(http.MethodGet, "/", nil), "session")
+	require.NoError(t, err)
+	r, _ := roundTrip(t, session)
+
+	loaded, err := store.Get(r, "session")
+	require.NoError(t, err)
+	loaded.Options.MaxAge = -1
+	_, cookie := roundTrip(t, loaded)
+	require.Empty(t, cookie.Value)
+	require.Negative(t, cookie.MaxAge)
+
+	loaded, err = store.Get(r, "session")
+	require.NoError(t, err)
+	require.True(t, loaded.IsNew)
+}
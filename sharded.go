@@ -0,0 +1,585 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"time"
+)
+
+var (
+	ErrShardCount    = errors.New("number of shards can not be less than 1")
+	ErrShardCapacity = errors.New("capacity of the sharded cache can not be less than the number of shards")
+	ErrShardLimit    = errors.New("limits of the sharded cache can not be less than the number of shards")
+)
+
+// Hasher maps the key to the hash used to choose the shard of the key
+type Hasher func(key string) uint64
+
+// ShardedCache distributes the keys between several independent caches, each one with its own mutex, so the
+// operations with different shards don't wait for each other. The order of the elements and the capacity are
+// maintained by every shard separately
+type ShardedCache struct {
+	shards     []*cache
+	hasher     Hasher
+	normalizer func(string) string
+}
+
+var (
+	_ Editor    = (*ShardedCache)(nil)
+	_ Informer  = (*ShardedCache)(nil)
+	_ Inspector = (*ShardedCache)(nil)
+)
+
+// NewShardedCache creates a cache of the given number of shards with the total capacity n divided between them, the
+// first n%shards shards get one element more, so the capacities of the shards sum up to n exactly. Options are
+// applied to every shard. Zero capacity makes all the shards unbounded, and the capacity less than the number of
+// shards is rejected with ErrShardCapacity, as one of the shards would get none. The other limits set by the options,
+// the soft capacity, the nursery capacity, the prefix limits and the quotas of the tenants and the callers, are
+// divided between the shards the same way, and the rate of the callers is divided evenly, as the keys of a prefix, a
+// tenant or a caller are spread over all the shards. The limit less than the number of shards is rejected with
+// ErrShardLimit. The keys are distributed by maphash with a random seed, unless another hasher is set by WithHasher
+func NewShardedCache(shards uint32, n uint32, opts ...CacheOption) (*ShardedCache, error) {
+	if shards == 0 {
+		return nil, ErrShardCount
+	}
+	if n != 0 && n < shards {
+		return nil, ErrShardCapacity
+	}
+
+	probe := &cache{}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	if !probe.divisible(uint64(shards)) {
+		return nil, ErrShardLimit
+	}
+
+	s := &ShardedCache{shards: make([]*cache, 0, shards)}
+	for i := uint32(0); i < shards; i++ {
+		c, err := NewCache(uint32(share(uint64(n), shards, i)), append(opts[:len(opts):len(opts)], shareLimits(shards, i))...)
+		if err != nil {
+			return nil, err
+		}
+		s.shards = append(s.shards, c.(*cache))
+	}
+
+	s.hasher = s.shards[0].hasher
+	s.normalizer = s.shards[0].normalizer
+	if s.hasher == nil {
+		seed := maphash.MakeSeed()
+		s.hasher = func(key string) uint64 {
+			return maphash.String(seed, key)
+		}
+	}
+
+	return s, nil
+}
+
+// share returns the part of the limit taken by the shard i, the first limit%shards shards get one more
+func share(limit uint64, shards, i uint32) uint64 {
+	part := limit / uint64(shards)
+	if uint64(i) < limit%uint64(shards) {
+		part++
+	}
+
+	return part
+}
+
+// divisible reports whether every limit set by the options is either zero or leaves a nonzero part to every shard
+func (c *cache) divisible(shards uint64) bool {
+	fits := func(limit uint64) bool {
+		return limit == 0 || limit >= shards
+	}
+
+	if !fits(uint64(c.softCapacity)) || c.nursery != nil && !fits(uint64(c.nursery.cfg.Capacity)) {
+		return false
+	}
+	for _, limit := range c.prefixes {
+		if !fits(uint64(limit.max)) {
+			return false
+		}
+	}
+	if c.tenancy != nil {
+		tenantFits := func(quota TenantQuota) bool {
+			return fits(uint64(quota.MaxEntries)) && fits(quota.MaxCost)
+		}
+		if !tenantFits(c.tenancy.cfg.Default) {
+			return false
+		}
+		for _, quota := range c.tenancy.cfg.Quotas {
+			if !tenantFits(quota) {
+				return false
+			}
+		}
+	}
+	if c.callers != nil {
+		callerFits := func(quota CallerQuota) bool {
+			return fits(uint64(quota.MaxEntries)) && fits(uint64(quota.Burst))
+		}
+		if !callerFits(c.callers.cfg.Default) {
+			return false
+		}
+		for _, quota := range c.callers.cfg.Quotas {
+			if !callerFits(quota) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// shareLimits replaces the limits set by the preceding options with the part of them taken by the shard i. The
+// quotas are copied, as the maps of them are shared by all the shards
+func shareLimits(shards, i uint32) CacheOption {
+	return func(cache *cache) {
+		cache.softCapacity = uint32(share(uint64(cache.softCapacity), shards, i))
+		if cache.nursery != nil {
+			cache.nursery.cfg.Capacity = uint32(share(uint64(cache.nursery.cfg.Capacity), shards, i))
+		}
+		for _, limit := range cache.prefixes {
+			limit.max = int(share(uint64(limit.max), shards, i))
+		}
+		if cache.tenancy != nil {
+			shareTenantQuota := func(quota TenantQuota) TenantQuota {
+				return TenantQuota{
+					MaxEntries: int(share(uint64(quota.MaxEntries), shards, i)),
+					MaxCost:    share(quota.MaxCost, shards, i),
+				}
+			}
+			cfg := &cache.tenancy.cfg
+			quotas := make(map[string]TenantQuota, len(cfg.Quotas))
+			for name, quota := range cfg.Quotas {
+				quotas[name] = shareTenantQuota(quota)
+			}
+			cfg.Quotas, cfg.Default = quotas, shareTenantQuota(cfg.Default)
+		}
+		if cache.callers != nil {
+			shareCallerQuota := func(quota CallerQuota) CallerQuota {
+				return CallerQuota{
+					MaxEntries: int(share(uint64(quota.MaxEntries), shards, i)),
+					Rate:       quota.Rate / float64(shards),
+					Burst:      int(share(uint64(quota.Burst), shards, i)),
+				}
+			}
+			cfg := &cache.callers.cfg
+			quotas := make(map[string]CallerQuota, len(cfg.Quotas))
+			for name, quota := range cfg.Quotas {
+				quotas[name] = shareCallerQuota(quota)
+			}
+			cfg.Quotas, cfg.Default = quotas, shareCallerQuota(cfg.Default)
+		}
+	}
+}
+
+// WithHasher sets the function used by NewShardedCache to choose the shard of the key. It allows you to distribute
+// keys with similar prefixes evenly or to align the shards with the upstream partitioning. A single cache ignores it
+func WithHasher(h Hasher) CacheOption {
+	return func(cache *cache) {
+		cache.hasher = h
+	}
+}
+
+// Add puts the element to the shard of the key, see Add of the cache
+func (s *ShardedCache) Add(key string, value interface{}) bool {
+	return s.shard(key).Add(key, value)
+}
+
+// AddE puts the element to the shard of the key, see AddE of the cache
+func (s *ShardedCache) AddE(key string, value interface{}) error {
+	return s.shard(key).AddE(key, value)
+}
+
+// AddReturningEvicted puts the element to the shard of the key and returns the element evicted from that shard, see
+// AddReturningEvicted of the cache
+func (s *ShardedCache) AddReturningEvicted(key string, value interface{}) (string, interface{}, bool) {
+	return s.shard(key).AddReturningEvicted(key, value)
+}
+
+// Get returns the value from the shard of the key, see Get of the cache
+func (s *ShardedCache) Get(key string) (interface{}, bool) {
+	return s.shard(key).Get(key)
+}
+
+// GetE returns the value from the shard of the key, see GetE of the cache
+func (s *ShardedCache) GetE(key string) (interface{}, error) {
+	return s.shard(key).GetE(key)
+}
+
+// GetOrAdd returns the existing value or adds the given one in the shard of the key, see GetOrAdd of the cache
+func (s *ShardedCache) GetOrAdd(key string, value interface{}) (interface{}, bool) {
+	return s.shard(key).GetOrAdd(key, value)
+}
+
+// Remove deletes the element from the shard of the key, see Remove of the cache
+func (s *ShardedCache) Remove(key string) bool {
+	return s.shard(key).Remove(key)
+}
+
+// RemoveE deletes the element from the shard of the key, see RemoveE of the cache
+func (s *ShardedCache) RemoveE(key string) error {
+	return s.shard(key).RemoveE(key)
+}
+
+// GetContext returns the value from the shard of the key, see GetContext of the cache
+func (s *ShardedCache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	return s.scopedShard(ctx, key).GetContext(ctx, key)
+}
+
+// AddContext puts the element to the shard of the key, see AddContext of the cache
+func (s *ShardedCache) AddContext(ctx context.Context, key string, value interface{}) error {
+	return s.scopedShard(ctx, key).AddContext(ctx, key, value)
+}
+
+// RemoveContext deletes the element from the shard of the key, see RemoveContext of the cache
+func (s *ShardedCache) RemoveContext(ctx context.Context, key string) error {
+	return s.scopedShard(ctx, key).RemoveContext(ctx, key)
+}
+
+// ChangeValueContext changes the value in the shard of the key, see ChangeValueContext of the cache
+func (s *ShardedCache) ChangeValueContext(ctx context.Context, key string, value interface{}) error {
+	return s.scopedShard(ctx, key).ChangeValueContext(ctx, key, value)
+}
+
+// AddBytes puts the element to the shard of the key given as a byte slice
+func (s *ShardedCache) AddBytes(key []byte, value interface{}) bool {
+	return s.shard(string(key)).AddBytes(key, value)
+}
+
+// GetBytes returns the value from the shard of the key given as a byte slice. Unlike the single cache, the key is
+// converted to a string for the hasher
+func (s *ShardedCache) GetBytes(key []byte) (interface{}, bool) {
+	return s.shard(string(key)).GetBytes(key)
+}
+
+// RemoveBytes deletes the element from the shard of the key given as a byte slice
+func (s *ShardedCache) RemoveBytes(key []byte) bool {
+	return s.shard(string(key)).RemoveBytes(key)
+}
+
+// ChangeValue changes the value in the shard of the key, see ChangeValue of the cache
+func (s *ShardedCache) ChangeValue(key string, newValue interface{}) bool {
+	return s.shard(key).ChangeValue(key, newValue)
+}
+
+// Clear completely clears all the shards
+func (s *ShardedCache) Clear() {
+	for _, c := range s.shards {
+		c.Clear()
+	}
+}
+
+// Len returns the total number of elements in all the shards
+func (s *ShardedCache) Len() int {
+	length := 0
+	for _, c := range s.shards {
+		length += c.Len()
+	}
+
+	return length
+}
+
+// Cap returns the total capacity of all the shards, zero if they are unbounded
+func (s *ShardedCache) Cap() int {
+	capacity := 0
+	for _, c := range s.shards {
+		capacity += c.Cap()
+	}
+
+	return capacity
+}
+
+// Config returns the configuration of the shards with the total capacity and the number of shards
+func (s *ShardedCache) Config() Config {
+	cfg := s.shards[0].Config()
+	cfg.Capacity = uint32(s.Cap())
+	cfg.SoftCapacity = 0
+	for _, c := range s.shards {
+		cfg.SoftCapacity += c.Config().SoftCapacity
+	}
+	cfg.Shards = len(s.shards)
+
+	return cfg
+}
+
+// Keys returns the keys of all the shards
+func (s *ShardedCache) Keys() []string {
+	keys := make([]string, 0, s.Len())
+	for _, c := range s.shards {
+		keys = append(keys, c.Keys()...)
+	}
+
+	return keys
+}
+
+// ReflectKeys returns the keys of all the shards using reflection
+func (s *ShardedCache) ReflectKeys() []string {
+	keys := make([]string, 0, s.Len())
+	for _, c := range s.shards {
+		keys = append(keys, c.ReflectKeys()...)
+	}
+
+	return keys
+}
+
+// Values returns the values of all the shards
+func (s *ShardedCache) Values() []interface{} {
+	values := make([]interface{}, 0, s.Len())
+	for _, c := range s.shards {
+		values = append(values, c.Values()...)
+	}
+
+	return values
+}
+
+// GetEntry returns the snapshot of the element from the shard of the key, see GetEntry of the cache
+func (s *ShardedCache) GetEntry(key string) (Entry, bool) {
+	return s.shard(key).GetEntry(key)
+}
+
+// Entries returns the snapshots of the elements of all the shards. The order is kept only inside every shard
+func (s *ShardedCache) Entries() []Entry {
+	entries := make([]Entry, 0, s.Len())
+	for _, c := range s.shards {
+		entries = append(entries, c.Entries()...)
+	}
+
+	return entries
+}
+
+// TopKeys returns the snapshots of up to n elements with the largest number of hits among all the shards
+func (s *ShardedCache) TopKeys(n int) []Entry {
+	return rank(s.Entries(), n, false)
+}
+
+// FrequentKeys returns the keys of up to n elements with the largest number of hits among all the shards
+func (s *ShardedCache) FrequentKeys(n int) []string {
+	entries := s.TopKeys(n)
+	keys := make([]string, len(entries))
+	for i := range entries {
+		keys[i] = entries[i].Key
+	}
+
+	return keys
+}
+
+// ColdestKeys returns the snapshots of up to n elements with the smallest number of hits among all the shards
+func (s *ShardedCache) ColdestKeys(n int) []Entry {
+	return rank(s.Entries(), n, true)
+}
+
+// String returns the concise description of the sharded cache
+func (s *ShardedCache) String() string {
+	return fmt.Sprintf("golru.ShardedCache{shards: %d, len: %d}", len(s.shards), s.Len())
+}
+
+// Dump writes the elements of every shard after the description of the shard, see Dump of the cache
+func (s *ShardedCache) Dump(w io.Writer, opts ...DumpOption) error {
+	if _, err := fmt.Fprintln(w, s.String()); err != nil {
+		return err
+	}
+
+	for i, c := range s.shards {
+		if _, err := fmt.Fprintf(w, "shard %d: ", i); err != nil {
+			return err
+		}
+		if err := c.Dump(w, opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns the sum of the counters of all the shards
+func (s *ShardedCache) Stats() Stats {
+	var total Stats
+	for _, c := range s.shards {
+		total.add(c.Stats())
+	}
+
+	return total
+}
+
+// ResetStats sets the counters of all the shards to zero
+func (s *ShardedCache) ResetStats() {
+	for _, c := range s.shards {
+		c.ResetStats()
+	}
+}
+
+// WindowHitRatio returns the hit ratio of all the shards over the last period d, see WindowHitRatio of the cache
+func (s *ShardedCache) WindowHitRatio(d time.Duration) float64 {
+	var total Stats
+	for _, c := range s.shards {
+		c.lock()
+		if c.window != nil {
+			hits, misses := c.window.count(time.Now(), d)
+			total.Hits += hits
+			total.Misses += misses
+		}
+		c.unlock()
+	}
+
+	return total.HitRatio()
+}
+
+// GetMulti returns the values of the keys found in their shards and loads the missing ones of all the shards in a
+// single batch, see GetMulti of the cache. The keys of every shard are looked up under a single lock acquisition of
+// the shard
+func (s *ShardedCache) GetMulti(ctx context.Context, keys []string, load BatchLoadFunc) (map[string]interface{}, error) {
+	groups := make([]keyGroup, len(s.shards))
+	for i, c := range s.shards {
+		groups[i].cache = c
+	}
+	for _, key := range keys {
+		i := s.index(s.shards[0].scoped(ctx, key))
+		groups[i].keys = append(groups[i].keys, key)
+	}
+
+	return getMulti(ctx, groups, load, func(key string) *cache {
+		return s.scopedShard(ctx, key)
+	})
+}
+
+// HotKeys returns the keys hot in the current windows of all the shards from the most read one. Every key is counted
+// by its own shard only
+func (s *ShardedCache) HotKeys() []HotKey {
+	var keys []HotKey
+	for _, c := range s.shards {
+		keys = append(keys, c.HotKeys()...)
+	}
+	sortHotKeys(keys)
+
+	return keys
+}
+
+// EstimatedBytes returns the approximate memory taken by all the shards
+func (s *ShardedCache) EstimatedBytes() uint64 {
+	var total uint64
+	for _, c := range s.shards {
+		total += c.EstimatedBytes()
+	}
+
+	return total
+}
+
+// ShardStats describes the state of a single shard
+type ShardStats struct {
+	Len int
+	Stats
+}
+
+// ShardStats returns the number of elements and the counters of every shard in the order of the shards
+func (s *ShardedCache) ShardStats() []ShardStats {
+	stats := make([]ShardStats, 0, len(s.shards))
+	for _, c := range s.shards {
+		c.lock()
+		stats = append(stats, ShardStats{Len: c.chain.Len(), Stats: c.stats})
+		c.unlock()
+	}
+
+	return stats
+}
+
+// Skew returns the ratio of the number of elements in the largest shard to the average one. 1 means that the keys
+// are distributed evenly, and the value close to the number of shards means that almost all keys are in one shard.
+// An empty cache has zero skew
+func (s *ShardedCache) Skew() float64 {
+	return skew(s.ShardStats(), func(stats ShardStats) uint64 { return uint64(stats.Len) })
+}
+
+// HitSkew works like Skew, but compares the number of hits, which helps to detect hot shards
+func (s *ShardedCache) HitSkew() float64 {
+	return skew(s.ShardStats(), func(stats ShardStats) uint64 { return stats.Hits })
+}
+
+// skew returns the ratio of the maximum value of the metric to the average one
+func skew(stats []ShardStats, metric func(ShardStats) uint64) float64 {
+	var total, max uint64
+	for _, shard := range stats {
+		value := metric(shard)
+		total += value
+		if value > max {
+			max = value
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return float64(max) * float64(len(stats)) / float64(total)
+}
+
+// Expire starts checking all the shards for the existence of expired data. Returns error if ttl is zero
+func (s *ShardedCache) Expire(ctx context.Context) error {
+	for _, c := range s.shards {
+		if err := c.Expire(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops the background goroutines of all the shards and waits for their eviction callbacks, see Shutdown of
+// the cache
+func (s *ShardedCache) Shutdown(ctx context.Context) error {
+	for _, c := range s.shards {
+		if err := c.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close invalidates all the shards, see Close of the cache
+func (s *ShardedCache) Close() error {
+	var err error
+	for _, c := range s.shards {
+		if closeErr := c.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// ExportCSV writes the live elements of every shard one shard after another, see ExportCSV of the cache
+func (s *ShardedCache) ExportCSV(w io.Writer, codec Codec) error {
+	var entries []Entry
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		entries = append(entries, s.shards[i].Entries()...)
+	}
+
+	return exportCSV(w, entries, codec)
+}
+
+// ImportCSV adds the elements from CSV to the shards of their keys, see ImportCSV of the cache
+func (s *ShardedCache) ImportCSV(r io.Reader, codec Codec) (int, error) {
+	return importCSV(r, codec, s.shard)
+}
+
+// shard returns the shard of the key
+func (s *ShardedCache) shard(key string) *cache {
+	return s.shards[s.index(key)]
+}
+
+// scopedShard returns the shard of the key within the scope of the context, so the scoped elements are found by their
+// stored keys too
+func (s *ShardedCache) scopedShard(ctx context.Context, key string) *cache {
+	return s.shard(s.shards[0].scoped(ctx, key))
+}
+
+// index returns the number of the shard of the key
+func (s *ShardedCache) index(key string) int {
+	if s.normalizer != nil {
+		key = s.normalizer(key)
+	}
+
+	return int(s.hasher(key) % uint64(len(s.shards)))
+}
@@ -0,0 +1,128 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedInit(t *testing.T) {
+	s, err := NewShardedCache(0, 10)
+	require.ErrorIs(t, err, ErrShardCount)
+	require.Nil(t, s)
+
+	s, err = NewShardedCache(4, 10)
+	require.NoError(t, err)
+	require.Len(t, s.shards, 4)
+	for i, capacity := range []uint32{3, 3, 2, 2} {
+		require.Equal(t, capacity, s.shards[i].capacity)
+	}
+
+	s, err = NewShardedCache(4, 3)
+	require.ErrorIs(t, err, ErrShardCapacity)
+	require.Nil(t, s)
+
+	s, err = NewShardedCache(4, 0)
+	require.NoError(t, err)
+	require.Zero(t, s.Cap())
+}
+
+func TestShardedLimits(t *testing.T) {
+	quotas := map[string]TenantQuota{"acme": {MaxEntries: 8}}
+	s, err := NewShardedCache(4, 10, WithSoftCapacity(5, 0), WithNursery(NurseryConfig{Capacity: 4}),
+		WithPrefixLimit("user:", 6), WithTenancy(TenancyConfig{Quotas: quotas, Default: TenantQuota{MaxCost: 100}}),
+		WithCallerQuotas(CallerQuotas{Default: CallerQuota{MaxEntries: 4, Rate: 8, Burst: 4}}))
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), s.Config().SoftCapacity)
+	for i, soft := range []uint32{2, 1, 1, 1} {
+		shard := s.shards[i]
+		require.Equal(t, soft, shard.softCapacity)
+		require.Equal(t, uint32(1), shard.nursery.cfg.Capacity)
+		require.Equal(t, []int{2, 2, 1, 1}[i], shard.prefixes[0].max)
+		require.Equal(t, 2, shard.tenancy.cfg.Quotas["acme"].MaxEntries)
+		require.Equal(t, uint64(25), shard.tenancy.cfg.Default.MaxCost)
+		require.Equal(t, CallerQuota{MaxEntries: 1, Rate: 2, Burst: 1}, shard.callers.cfg.Default)
+	}
+	require.Equal(t, 8, quotas["acme"].MaxEntries)
+
+	for _, opt := range []CacheOption{
+		WithSoftCapacity(3, 0),
+		WithPrefixLimit("user:", 2),
+		WithTenancy(TenancyConfig{Default: TenantQuota{MaxEntries: 3}}),
+		WithCallerQuotas(CallerQuotas{Quotas: map[string]CallerQuota{"plugin": {Burst: 1}}}),
+	} {
+		_, err = NewShardedCache(4, 10, opt)
+		require.ErrorIs(t, err, ErrShardLimit)
+	}
+}
+
+func TestShardedOperations(t *testing.T) {
+	s, err := NewShardedCache(4, 100)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		require.True(t, s.Add("test"+strconv.Itoa(i), i))
+	}
+	require.False(t, s.Add("test1", 1))
+	require.Equal(t, 20, s.Len())
+	require.Len(t, s.Keys(), 20)
+	require.Len(t, s.Values(), 20)
+
+	value, ok := s.Get("test5")
+	require.True(t, ok)
+	require.Equal(t, 5, value)
+	require.True(t, s.ChangeValue("test5", 50))
+	value, ok = s.GetBytes([]byte("test5"))
+	require.True(t, ok)
+	require.Equal(t, 50, value)
+
+	require.True(t, s.Remove("test5"))
+	_, err = s.GetE("test5")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.Equal(t, uint64(2), s.Stats().Hits)
+
+	s.Clear()
+	require.Equal(t, 0, s.Len())
+}
+
+func TestShardedHasher(t *testing.T) {
+	s, err := NewShardedCache(4, 100, WithHasher(func(key string) uint64 {
+		n, _ := strconv.Atoi(key)
+		return uint64(n)
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		s.Add(strconv.Itoa(i), i)
+	}
+	for i, shard := range s.shards {
+		require.ElementsMatch(t, []string{strconv.Itoa(i), strconv.Itoa(i + 4)}, shard.Keys())
+	}
+}
+
+func TestShardedStats(t *testing.T) {
+	s, err := NewShardedCache(2, 100, WithHasher(func(key string) uint64 {
+		if key == "odd" {
+			return 1
+		}
+		return 0
+	}))
+	require.NoError(t, err)
+	require.Zero(t, s.Skew())
+
+	s.Add("even1", 1)
+	s.Add("even2", 2)
+	s.Add("even3", 3)
+	s.Add("odd", 4)
+	s.Get("odd")
+
+	stats := s.ShardStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, 3, stats[0].Len)
+	require.Equal(t, 1, stats[1].Len)
+	require.Equal(t, uint64(1), stats[1].Hits)
+	require.Equal(t, 1.5, s.Skew())
+	require.Equal(t, 2.0, s.HitSkew())
+	require.Equal(t, []string{"odd"}, s.FrequentKeys(1))
+}
@@ -0,0 +1,110 @@
+package golru
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks the background goroutines of the cache, like the ones of Expire, Trim or AutoTune, so they can be
+// stopped all at once. The context is done when the cache is shut down. Active counts the running goroutines by their
+// kind for Health
+type lifecycle struct {
+	ctx     context.Context
+	stop    context.CancelFunc
+	running sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// newLifecycle returns the lifecycle of a new cache
+func newLifecycle() *lifecycle {
+	ctx, stop := context.WithCancel(context.Background())
+
+	return &lifecycle{ctx: ctx, stop: stop, active: make(map[string]int)}
+}
+
+// background runs the loop of the given kind in a new goroutine. The context passed to the loop is done either when
+// the given one is done or when the cache is shut down
+func (c *cache) background(ctx context.Context, kind string, loop func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	unlink := context.AfterFunc(c.life.ctx, cancel)
+
+	c.life.running.Add(1)
+	c.life.track(kind, 1)
+	go func() {
+		defer c.life.running.Done()
+		defer c.life.track(kind, -1)
+		defer unlink()
+		defer cancel()
+
+		loop(ctx)
+	}()
+}
+
+// track changes the number of the running goroutines of the kind
+func (l *lifecycle) track(kind string, delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active[kind] += delta
+	if l.active[kind] == 0 {
+		delete(l.active, kind)
+	}
+}
+
+// snapshot returns the number of the running goroutines by their kind
+func (l *lifecycle) snapshot() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	active := make(map[string]int, len(l.active))
+	for kind, n := range l.active {
+		active[kind] = n
+	}
+
+	return active
+}
+
+// Shutdown stops all the background goroutines of the cache, like the ones of Expire, Trim, AutoTune, WatchMemory,
+// WatchGC and SchedulePurge, waits for them to exit and then waits for the eviction callbacks queued so far, as Drain
+// does. Returns the error of the context if it is done earlier. The cache itself stays usable, but the background work
+// started after Shutdown stops at once
+func (c *cache) Shutdown(ctx context.Context) error {
+	c.life.stop()
+
+	done := make(chan struct{})
+	go func() {
+		c.life.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return c.Drain(ctx)
+}
+
+// Close invalidates the cache. The elements are passed to the eviction callback with the EvictedByClear reason and
+// released, the background goroutines are stopped and waited for. After that the operations with the keys fail with
+// ErrCacheClosed, or return false, and the cache stays empty. Returns ErrCacheClosed if the cache is already closed.
+// Close doesn't wait for the eviction callbacks run by the workers, use Shutdown before it to wait for them with the
+// limit of time
+func (c *cache) Close() error {
+	c.lock()
+	if c.closed {
+		c.unlock()
+		return ErrCacheClosed
+	}
+	c.closed = true
+	c.clear()
+	c.unlock()
+
+	c.life.stop()
+	c.life.running.Wait()
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	var evicted int32
+	release := make(chan struct{})
+	c, err := NewCache(1, WithTTL(1), WithEvictWorkers(1, 4),
+		WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+			<-release
+			atomic.AddInt32(&evicted, 1)
+		}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Expire(context.Background()))
+	require.NoError(t, c.SchedulePurge(context.Background(), "* * * * *", func(Entry) bool { return false }))
+	for i := 0; i < 3; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, c.Shutdown(context.Background()))
+	require.Equal(t, int32(2), atomic.LoadInt32(&evicted))
+
+	value, ok := c.Get("test2")
+	require.True(t, ok)
+	require.Equal(t, 2, value)
+}
+
+func TestShutdownStopsLaterWork(t *testing.T) {
+	c, err := NewCache(10, WithTTL(1))
+	require.NoError(t, err)
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	require.NoError(t, c.Expire(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, c.Shutdown(ctx))
+}
+
+func TestShardedShutdown(t *testing.T) {
+	s, err := NewShardedCache(4, 40, WithTTL(1))
+	require.NoError(t, err)
+	require.NoError(t, s.Expire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+	for _, c := range s.shards {
+		require.Error(t, c.life.ctx.Err())
+	}
+}
+
+func TestClose(t *testing.T) {
+	var reasons []EvictReason
+	c, err := NewCache(10, WithTTL(1), WithOnEvict(func(key string, value interface{}, reason EvictReason) {
+		reasons = append(reasons, reason)
+	}))
+	require.NoError(t, err)
+	require.NoError(t, c.Expire(context.Background()))
+	c.Add("first", 1)
+	c.Add("second", 2)
+
+	require.NoError(t, c.Close())
+	require.Equal(t, []EvictReason{EvictedByClear, EvictedByClear}, reasons)
+	require.Error(t, c.(*cache).life.ctx.Err())
+	require.ErrorIs(t, c.Close(), ErrCacheClosed)
+
+	require.Equal(t, 0, c.Len())
+	require.False(t, c.Add("third", 3))
+	require.ErrorIs(t, c.AddE("third", 3), ErrCacheClosed)
+	_, err = c.GetE("first")
+	require.ErrorIs(t, err, ErrCacheClosed)
+	require.ErrorIs(t, c.RemoveE("first"), ErrCacheClosed)
+	require.ErrorIs(t, c.AddWithDeadline("third", 3, time.Now().Add(time.Hour)), ErrCacheClosed)
+	_, err = c.Update("first", func(interface{}, bool) (interface{}, error) { return 1, nil })
+	require.ErrorIs(t, err, ErrCacheClosed)
+	require.Empty(t, c.Keys())
+}
+
+func TestShardedClose(t *testing.T) {
+	s, err := NewShardedCache(4, 40)
+	require.NoError(t, err)
+	s.Add("test", 1)
+
+	require.NoError(t, s.Close())
+	require.ErrorIs(t, s.AddE("test", 1), ErrCacheClosed)
+	require.ErrorIs(t, s.Close(), ErrCacheClosed)
+}
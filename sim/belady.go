@@ -0,0 +1,156 @@
+package sim
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// never is the next use of the key which isn't read again
+const never = math.MaxInt
+
+// positioned is the policy which needs to know the position of the record in the trace before it is replayed
+type positioned interface {
+	seek(i int)
+}
+
+// beladyEntry is the key kept by Belady with the position of its next read
+type beladyEntry struct {
+	key     string
+	created time.Time
+	next    int
+	index   int
+}
+
+// beladyHeap orders the keys from the one read the latest
+type beladyHeap []*beladyEntry
+
+func (h beladyHeap) Len() int { return len(h) }
+
+func (h beladyHeap) Less(i, j int) bool { return h[i].next > h[j].next }
+
+func (h beladyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *beladyHeap) Push(x interface{}) {
+	e := x.(*beladyEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *beladyHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return e
+}
+
+// beladyPolicy evicts the key read the latest in the future
+type beladyPolicy struct {
+	capacity int
+	ttl      time.Duration
+	next     []int
+	pos      int
+	items    map[string]*beladyEntry
+	order    beladyHeap
+}
+
+// Belady returns the clairvoyant optimal policy for the trace: it knows when every key is read next, evicts the key
+// read the latest, and doesn't add the key read later than all the cached ones. No policy gets more hits with the same
+// capacity, so its hit ratio is the ceiling the real policies are measured against. The policies it creates must be
+// replayed with the same trace
+func Belady(trace []golru.TraceRecord) Factory {
+	var once sync.Once
+	var next []int
+
+	return func(capacity int, ttl time.Duration) Policy {
+		once.Do(func() {
+			next = nextReads(trace)
+		})
+		return &beladyPolicy{capacity: capacity, ttl: ttl, next: next, items: make(map[string]*beladyEntry, capacity)}
+	}
+}
+
+// nextReads returns the position of the next read of the key of every record. The key added again or removed before
+// it is read isn't needed till then, so its next read is never
+func nextReads(trace []golru.TraceRecord) []int {
+	next := make([]int, len(trace))
+	reads := make(map[string]int)
+	for i := len(trace) - 1; i >= 0; i-- {
+		record := trace[i]
+		n, ok := reads[record.Key]
+		if !ok {
+			n = never
+		}
+		next[i] = n
+
+		switch record.Op {
+		case golru.OpGet, golru.OpGetOrAdd:
+			reads[record.Key] = i
+		case golru.OpAdd, golru.OpAddReturningEvicted, golru.OpChangeValue, golru.OpSwap, golru.OpUpdate, golru.OpRemove:
+			reads[record.Key] = never
+		}
+	}
+
+	return next
+}
+
+// seek sets the position of the replayed record
+func (p *beladyPolicy) seek(i int) {
+	p.pos = i
+}
+
+// Get reports whether the live key is in the cache and moves on to its next read
+func (p *beladyPolicy) Get(key string, now time.Time) bool {
+	e, ok := p.items[key]
+	if !ok {
+		return false
+	}
+	if expired(e.created, p.ttl, now) {
+		p.Remove(key)
+		return false
+	}
+
+	e.next = p.next[p.pos]
+	heap.Fix(&p.order, e.index)
+
+	return true
+}
+
+// Add puts the key to the cache evicting the key read the latest, unless the new key itself is read later
+func (p *beladyPolicy) Add(key string, now time.Time) {
+	next := p.next[p.pos]
+	if e, ok := p.items[key]; ok {
+		e.created, e.next = now, next
+		heap.Fix(&p.order, e.index)
+		return
+	}
+	if next == never {
+		return
+	}
+
+	if p.capacity != 0 && len(p.order) >= p.capacity {
+		if p.order[0].next <= next {
+			return
+		}
+		victim := heap.Pop(&p.order).(*beladyEntry)
+		delete(p.items, victim.key)
+	}
+	e := &beladyEntry{key: key, created: now, next: next}
+	heap.Push(&p.order, e)
+	p.items[key] = e
+}
+
+// Remove deletes the key from the cache
+func (p *beladyPolicy) Remove(key string) {
+	if e, ok := p.items[key]; ok {
+		heap.Remove(&p.order, e.index)
+		delete(p.items, key)
+	}
+}
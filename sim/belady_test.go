@@ -0,0 +1,56 @@
+package sim
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBelady(t *testing.T) {
+	trace := gets("a", "b", "a", "c", "a", "b")
+
+	hits, misses := Replay(Belady(trace)(2, 0), trace, true)
+	require.Equal(t, uint64(3), hits)
+	require.Equal(t, uint64(3), misses)
+}
+
+func TestBeladyIsOptimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.1, 1, 500)
+	keys := make([]string, 20000)
+	for i := range keys {
+		keys[i] = strconv.FormatUint(zipf.Uint64(), 10)
+	}
+	trace := gets(keys...)
+
+	results := Run(trace, Config{Capacities: []int{10, 50, 200}, Fill: true, Optimal: true})
+	best := make(map[int]float64)
+	for _, r := range results {
+		if r.Policy == "optimal" {
+			best[r.Capacity] = r.HitRatio()
+		}
+	}
+	require.Len(t, best, 3)
+	for _, r := range results {
+		require.LessOrEqual(t, r.HitRatio(), best[r.Capacity], r.Policy)
+	}
+	require.Less(t, best[10], best[50])
+}
+
+func TestNextReads(t *testing.T) {
+	start := time.Unix(0, 0)
+	trace := []golru.TraceRecord{
+		{Time: start, Op: golru.OpGet, Key: "a"},
+		{Time: start, Op: golru.OpAdd, Key: "a"},
+		{Time: start, Op: golru.OpGet, Key: "a"},
+		{Time: start, Op: golru.OpGet, Key: "b"},
+		{Time: start, Op: golru.OpRemove, Key: "b"},
+		{Time: start, Op: golru.OpGet, Key: "b"},
+	}
+
+	require.Equal(t, []int{never, 2, never, never, 5, never}, nextReads(trace))
+}
@@ -0,0 +1,205 @@
+package sim
+
+import (
+	"container/heap"
+	"container/list"
+	"time"
+)
+
+// Policy is the simulated cache. It keeps only the keys, and the time is taken from the trace, so the ttl works the
+// same way as in the recorded traffic regardless of the speed of the replay
+type Policy interface {
+	// Get reports whether the live key is in the cache at the moment now and marks it as used
+	Get(key string, now time.Time) bool
+	// Add puts the key to the cache at the moment now, evicting another one if the cache is full
+	Add(key string, now time.Time)
+	// Remove deletes the key from the cache
+	Remove(key string)
+}
+
+// Factory creates the policy with the given capacity and ttl, zero ttl means that the keys never expire
+type Factory func(capacity int, ttl time.Duration) Policy
+
+// entry is the key kept by the list based policies
+type entry struct {
+	key     string
+	created time.Time
+}
+
+// listPolicy keeps the keys in the list from the newest to the oldest one. Promote makes it LRU, otherwise it is
+// FIFO
+type listPolicy struct {
+	capacity int
+	ttl      time.Duration
+	promote  bool
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// LRU evicts the least recently used key, as golru does
+func LRU(capacity int, ttl time.Duration) Policy {
+	return newListPolicy(capacity, ttl, true)
+}
+
+// FIFO evicts the oldest added key regardless of its use
+func FIFO(capacity int, ttl time.Duration) Policy {
+	return newListPolicy(capacity, ttl, false)
+}
+
+// newListPolicy creates the list based policy
+func newListPolicy(capacity int, ttl time.Duration, promote bool) *listPolicy {
+	return &listPolicy{
+		capacity: capacity,
+		ttl:      ttl,
+		promote:  promote,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Get reports whether the live key is in the list and moves it to the front for LRU
+func (p *listPolicy) Get(key string, now time.Time) bool {
+	element, ok := p.items[key]
+	if !ok {
+		return false
+	}
+	if expired(element.Value.(*entry).created, p.ttl, now) {
+		p.Remove(key)
+		return false
+	}
+	if p.promote {
+		p.order.MoveToFront(element)
+	}
+
+	return true
+}
+
+// Add puts the key to the front of the list evicting the back one if the list is full. The existing key is renewed
+// and, for LRU, moved to the front
+func (p *listPolicy) Add(key string, now time.Time) {
+	if element, ok := p.items[key]; ok {
+		element.Value.(*entry).created = now
+		if p.promote {
+			p.order.MoveToFront(element)
+		}
+		return
+	}
+
+	if p.capacity != 0 && p.order.Len() >= p.capacity {
+		p.Remove(p.order.Back().Value.(*entry).key)
+	}
+	p.items[key] = p.order.PushFront(&entry{key: key, created: now})
+}
+
+// Remove deletes the key from the list
+func (p *listPolicy) Remove(key string) {
+	if element, ok := p.items[key]; ok {
+		p.order.Remove(element)
+		delete(p.items, key)
+	}
+}
+
+// lfuEntry is the key kept by LFU with the number of its uses
+type lfuEntry struct {
+	key     string
+	created time.Time
+	hits    uint64
+	used    uint64
+	index   int
+}
+
+// lfuHeap orders the keys from the least frequently used one, the least recently used one first among equals
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].hits != h[j].hits {
+		return h[i].hits < h[j].hits
+	}
+
+	return h[i].used < h[j].used
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return e
+}
+
+// lfuPolicy evicts the least frequently used key
+type lfuPolicy struct {
+	capacity int
+	ttl      time.Duration
+	clock    uint64
+	items    map[string]*lfuEntry
+	order    lfuHeap
+}
+
+// LFU evicts the key with the smallest number of hits, the least recently used one among equals
+func LFU(capacity int, ttl time.Duration) Policy {
+	return &lfuPolicy{capacity: capacity, ttl: ttl, items: make(map[string]*lfuEntry, capacity)}
+}
+
+// Get reports whether the live key is in the cache and counts the hit
+func (p *lfuPolicy) Get(key string, now time.Time) bool {
+	e, ok := p.items[key]
+	if !ok {
+		return false
+	}
+	if expired(e.created, p.ttl, now) {
+		p.Remove(key)
+		return false
+	}
+
+	p.clock++
+	e.hits++
+	e.used = p.clock
+	heap.Fix(&p.order, e.index)
+
+	return true
+}
+
+// Add puts the key to the cache evicting the least frequently used one if the cache is full
+func (p *lfuPolicy) Add(key string, now time.Time) {
+	p.clock++
+	if e, ok := p.items[key]; ok {
+		e.created, e.used = now, p.clock
+		heap.Fix(&p.order, e.index)
+		return
+	}
+
+	if p.capacity != 0 && len(p.order) >= p.capacity {
+		victim := heap.Pop(&p.order).(*lfuEntry)
+		delete(p.items, victim.key)
+	}
+	e := &lfuEntry{key: key, created: now, used: p.clock}
+	heap.Push(&p.order, e)
+	p.items[key] = e
+}
+
+// Remove deletes the key from the cache
+func (p *lfuPolicy) Remove(key string) {
+	if e, ok := p.items[key]; ok {
+		heap.Remove(&p.order, e.index)
+		delete(p.items, key)
+	}
+}
+
+// expired checks whether the key created at the moment has outlived the ttl
+func expired(created time.Time, ttl time.Duration, now time.Time) bool {
+	return ttl != 0 && now.Sub(created) > ttl
+}
@@ -0,0 +1,156 @@
+// Package sim replays the access traces recorded by golru.TraceRecorder against different eviction policies,
+// capacities and ttl settings and reports the hit ratio of every combination, so the settings of the cache can be
+// chosen by the real traffic
+package sim
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// Policies returns the policies simulated by default by their names
+func Policies() map[string]Factory {
+	return map[string]Factory{
+		"lru":  LRU,
+		"fifo": FIFO,
+		"lfu":  LFU,
+	}
+}
+
+// Config is the grid of the simulated settings, every policy is replayed with every capacity and every ttl. All the
+// default policies are used if Policies is empty, and the keys never expire if TTLs is empty. Fill adds the key missed
+// by Get, as the read-through cache does, which is needed for the traces without the adds. Optimal adds the results of
+// Belady under the name "optimal", the best hit ratio possible for the trace
+type Config struct {
+	Policies   map[string]Factory
+	Capacities []int
+	TTLs       []time.Duration
+	Fill       bool
+	Optimal    bool
+}
+
+// Result is the outcome of the replay of the trace with a single combination of the settings
+type Result struct {
+	Policy   string
+	Capacity int
+	TTL      time.Duration
+	Hits     uint64
+	Misses   uint64
+}
+
+// HitRatio returns the share of the reads which found the key
+func (r Result) HitRatio() float64 {
+	total := r.Hits + r.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(r.Hits) / float64(total)
+}
+
+// ReadTrace reads the whole trace in the given format
+func ReadTrace(r io.Reader, format golru.TraceFormat) ([]golru.TraceRecord, error) {
+	tr := golru.NewTraceReader(r, format)
+
+	var trace []golru.TraceRecord
+	for {
+		record, err := tr.Next()
+		if err == io.EOF {
+			return trace, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		trace = append(trace, record)
+	}
+}
+
+// Run replays the trace with every combination of the settings and returns the results ordered by the policy, the
+// capacity and the ttl
+func Run(trace []golru.TraceRecord, cfg Config) []Result {
+	policies := cfg.Policies
+	if len(policies) == 0 {
+		policies = Policies()
+	}
+	if cfg.Optimal {
+		withOptimal := make(map[string]Factory, len(policies)+1)
+		for name, factory := range policies {
+			withOptimal[name] = factory
+		}
+		withOptimal["optimal"] = Belady(trace)
+		policies = withOptimal
+	}
+	ttls := cfg.TTLs
+	if len(ttls) == 0 {
+		ttls = []time.Duration{0}
+	}
+
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]Result, 0, len(names)*len(cfg.Capacities)*len(ttls))
+	for _, name := range names {
+		for _, capacity := range cfg.Capacities {
+			for _, ttl := range ttls {
+				result := Result{Policy: name, Capacity: capacity, TTL: ttl}
+				result.Hits, result.Misses = Replay(policies[name](capacity, ttl), trace, cfg.Fill)
+				results = append(results, result)
+			}
+		}
+	}
+
+	return results
+}
+
+// Replay runs the trace against the policy and returns the number of hits and misses of the reads. Get and the Get
+// part of GetOrAdd are the reads, the operations adding or changing the value add the key, and Remove deletes it.
+// Fill adds the key missed by Get
+func Replay(p Policy, trace []golru.TraceRecord, fill bool) (hits, misses uint64) {
+	seeker, _ := p.(positioned)
+	for i, record := range trace {
+		if seeker != nil {
+			seeker.seek(i)
+		}
+		switch record.Op {
+		case golru.OpGet, golru.OpGetOrAdd:
+			if p.Get(record.Key, record.Time) {
+				hits++
+				continue
+			}
+			misses++
+			if fill || record.Op == golru.OpGetOrAdd {
+				p.Add(record.Key, record.Time)
+			}
+		case golru.OpAdd, golru.OpAddReturningEvicted, golru.OpChangeValue, golru.OpSwap, golru.OpUpdate:
+			p.Add(record.Key, record.Time)
+		case golru.OpRemove:
+			p.Remove(record.Key)
+		}
+	}
+
+	return hits, misses
+}
+
+// WriteReport writes the results as a table aligned by the columns
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "policy\tcapacity\tttl\thits\tmisses\thit ratio"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		_, err := fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%.4f\n", r.Policy, r.Capacity, r.TTL, r.Hits, r.Misses, r.HitRatio())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+// gets builds the trace of the reads of the keys one second apart
+func gets(keys ...string) []golru.TraceRecord {
+	start := time.Unix(0, 0)
+	trace := make([]golru.TraceRecord, len(keys))
+	for i, key := range keys {
+		trace[i] = golru.TraceRecord{Time: start.Add(time.Duration(i) * time.Second), Op: golru.OpGet, Key: key}
+	}
+
+	return trace
+}
+
+func TestReplayPolicies(t *testing.T) {
+	trace := gets("a", "b", "a", "c", "a", "b")
+
+	hits, misses := Replay(LRU(2, 0), trace, true)
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(4), misses)
+
+	hits, _ = Replay(FIFO(2, 0), trace, true)
+	require.Equal(t, uint64(1), hits)
+
+	hits, _ = Replay(LFU(2, 0), trace, true)
+	require.Equal(t, uint64(2), hits)
+
+	hits, _ = Replay(LRU(2, 0), trace, false)
+	require.Zero(t, hits)
+}
+
+func TestReplayTTL(t *testing.T) {
+	trace := gets("a", "a", "b", "c", "a")
+
+	hits, _ := Replay(LRU(10, 0), trace, true)
+	require.Equal(t, uint64(2), hits)
+
+	hits, _ = Replay(LRU(10, 2*time.Second), trace, true)
+	require.Equal(t, uint64(1), hits)
+}
+
+func TestReplayOps(t *testing.T) {
+	start := time.Unix(0, 0)
+	trace := []golru.TraceRecord{
+		{Time: start, Op: golru.OpAdd, Key: "a", Hit: true},
+		{Time: start, Op: golru.OpGet, Key: "a", Hit: true},
+		{Time: start, Op: golru.OpRemove, Key: "a", Hit: true},
+		{Time: start, Op: golru.OpGetOrAdd, Key: "a", Hit: true},
+		{Time: start, Op: golru.OpGet, Key: "a", Hit: true},
+	}
+
+	hits, misses := Replay(LRU(10, 0), trace, false)
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(1), misses)
+}
+
+func TestRunRecordedTrace(t *testing.T) {
+	var buf bytes.Buffer
+	rec := golru.NewTraceRecorder(&buf, golru.TraceBinary, 1)
+	c, err := golru.NewCache(100, golru.WithTraceRecorder(rec))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i % 50)
+		if !c.Add(key, i) {
+			c.Get(key)
+		}
+	}
+	require.NoError(t, rec.Flush())
+
+	trace, err := ReadTrace(&buf, golru.TraceBinary)
+	require.NoError(t, err)
+	require.Len(t, trace, 1000+950)
+
+	results := Run(trace, Config{Capacities: []int{10, 50}, TTLs: []time.Duration{0, time.Hour}})
+	require.Len(t, results, 3*2*2)
+	require.Equal(t, "fifo", results[0].Policy)
+	for _, r := range results {
+		if r.Capacity == 50 {
+			require.Equal(t, 1.0, r.HitRatio(), r.Policy)
+		}
+	}
+
+	var report bytes.Buffer
+	require.NoError(t, WriteReport(&report, results))
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Len(t, lines, len(results)+1)
+	require.True(t, strings.HasPrefix(lines[0], "policy"))
+}
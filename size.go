@@ -0,0 +1,54 @@
+package golru
+
+import "unsafe"
+
+// mapEntryBytes is the approximate space taken by a single entry of the hash table: the string header of the key,
+// the pointer to the item and the share of the bucket metadata
+const mapEntryBytes = 32
+
+// itemBytes is the size of the item structure itself, including the list links
+var itemBytes = uint64(unsafe.Sizeof(item{}))
+
+// Weigher returns the size of the value in bytes, it is used to estimate the memory taken by the cache
+type Weigher func(key string, value interface{}) uint64
+
+// WithWeigher sets the function estimating the size of the values. Without it only strings and byte slices are
+// counted, and other values are considered to take no memory beyond the item itself
+func WithWeigher(w Weigher) CacheOption {
+	return func(cache *cache) {
+		cache.weigher = w
+	}
+}
+
+// EstimatedBytes returns the approximate memory taken by the cache: the bytes of the keys, the overhead of the items
+// and the hash table, and the sizes of the values given by the weigher. The estimation traverses all the elements,
+// so it is meant for periodic reporting rather than for the hot path
+func (c *cache) EstimatedBytes() uint64 {
+	c.lock()
+	defer c.unlock()
+
+	var total uint64
+	for current := c.chain.Front(); current != nil; current = c.chain.Next(current) {
+		total += itemBytes + mapEntryBytes + uint64(len(current.key)) + c.valueBytes(current)
+	}
+
+	return total
+}
+
+// valueBytes returns the size of the value of the element
+func (c *cache) valueBytes(element *item) uint64 {
+	if c.weigher != nil {
+		return c.weigher(element.key, element.value)
+	}
+
+	switch value := element.value.(type) {
+	case string:
+		return uint64(len(value))
+	case []byte:
+		return uint64(cap(value))
+	case *compressed:
+		return uint64(cap(value.data))
+	default:
+		return 0
+	}
+}
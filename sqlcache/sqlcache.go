@@ -0,0 +1,323 @@
+// Package sqlcache caches the results of the queries of database/sql in golru. The results are keyed by the
+// normalized text of the query and its arguments, so the same query written with the different spacing shares its
+// results. Every statement may have its own ttl and the tags of the tables it touches, and the results are
+// invalidated by the statement, by the tag, or by Exec of the statement changing the tagged tables:
+//
+//	users := `SELECT id, name FROM users WHERE team = ?`
+//	rename := `UPDATE users SET name = ? WHERE id = ?`
+//	db := sqlcache.New(sqlDB, cache,
+//		sqlcache.WithStatement(users, sqlcache.Statement{TTL: time.Minute, Tags: []string{"users"}}),
+//		sqlcache.WithStatement(rename, sqlcache.Statement{Tags: []string{"users"}}))
+//
+//	names, err := sqlcache.Query(ctx, db, func(rows *sql.Rows) (string, error) {
+//		var id int
+//		var name string
+//		err := rows.Scan(&id, &name)
+//		return name, err
+//	}, users, 7)
+//
+//	_, err = db.Exec(ctx, rename, "alice", 1) // invalidates the cached users
+//
+// The scanned rows are cached, so the scan function runs only on the miss. The invalidation relies on
+// AddWithDependencies: every result depends on the keys of its statement and its tags, which are never stored
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/qiwik/golru"
+)
+
+// Querier is the part of *sql.DB used by the cache, *sql.Tx and *sql.Conn satisfy it too
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Cache is the part of the cache used to keep the results, golru.Cacher satisfies it
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	AddWithDependencies(key string, value interface{}, deps ...string) error
+	Remove(key string) bool
+	Invalidate(key string) int
+}
+
+// Statement describes a query. TTL is the lifetime of its results, the default one is used if it is zero, and the
+// negative one makes the results bypass the cache. Tags are the names of the tables or anything else the statement
+// reads or changes: the results of the query are invalidated by the tags, and Exec of the statement invalidates them
+type Statement struct {
+	TTL  time.Duration
+	Tags []string
+}
+
+// Option configures the cache of the queries
+type Option func(*DB)
+
+// WithTTL sets the default lifetime of the results. Without it the results live as long as the ttl of the cache lets
+// them
+func WithTTL(ttl time.Duration) Option {
+	return func(db *DB) {
+		db.ttl = ttl
+	}
+}
+
+// WithStatement describes the query, it is found by the normalized text, so the spacing doesn't matter
+func WithStatement(query string, st Statement) Option {
+	return func(db *DB) {
+		db.statements[normalize(query)] = st
+	}
+}
+
+// DB runs the queries caching their results
+type DB struct {
+	db         Querier
+	cache      Cache
+	ttl        time.Duration
+	statements map[string]Statement
+}
+
+// result is the cached result of the query, Expires is zero if it lives as long as the cache lets it
+type result struct {
+	rows    interface{}
+	expires time.Time
+}
+
+// New creates the cache of the queries of db keeping the results in the cache
+func New(db Querier, cache Cache, opts ...Option) *DB {
+	d := &DB{db: db, cache: cache, statements: make(map[string]Statement)}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Query returns the rows of the query scanned by the function, from the cache if the result is there. The scan
+// function is called for every row after Next, like the loop over sql.Rows does. The returned slice is a copy, but the
+// rows themselves are shared with the other callers, so they must not be changed
+func Query[T any](ctx context.Context, db *DB, scan func(rows *sql.Rows) (T, error), query string, args ...interface{}) ([]T, error) {
+	normalized := normalize(query)
+	st := db.statement(normalized)
+	ttl := st.TTL
+	if ttl == 0 {
+		ttl = db.ttl
+	}
+
+	key, err := resultKey(normalized, args)
+	if err != nil {
+		return nil, err
+	}
+	if ttl >= 0 {
+		if rows, ok := db.lookup(key).([]T); ok {
+			return append([]T(nil), rows...), nil
+		}
+	}
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scanned []T
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		scanned = append(scanned, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if ttl >= 0 {
+		stored := result{rows: scanned}
+		if ttl > 0 {
+			stored.expires = time.Now().Add(ttl)
+		}
+		db.cache.Remove(key)
+		_ = db.cache.AddWithDependencies(key, stored, db.dependencies(normalized, st)...)
+	}
+
+	return append([]T(nil), scanned...), nil
+}
+
+// QueryRow works like Query and returns the first row, or sql.ErrNoRows if there are none
+func QueryRow[T any](ctx context.Context, db *DB, scan func(rows *sql.Rows) (T, error), query string, args ...interface{}) (T, error) {
+	rows, err := Query(ctx, db, scan, query, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if len(rows) == 0 {
+		var zero T
+		return zero, sql.ErrNoRows
+	}
+
+	return rows[0], nil
+}
+
+// Exec runs the statement and, if it succeeds, invalidates the results of the queries sharing its tags
+func (db *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range db.statement(normalize(query)).Tags {
+		db.InvalidateTag(tag)
+	}
+
+	return res, nil
+}
+
+// Invalidate removes the result of the query with the arguments. Returns false if it wasn't cached
+func (db *DB) Invalidate(query string, args ...interface{}) bool {
+	key, err := resultKey(normalize(query), args)
+	if err != nil {
+		return false
+	}
+
+	return db.cache.Remove(key)
+}
+
+// InvalidateStatement removes the results of the query with all the arguments. Returns the number of the removed
+// results
+func (db *DB) InvalidateStatement(query string) int {
+	return db.cache.Invalidate(statementKey(normalize(query)))
+}
+
+// InvalidateTag removes the results of all the queries with the tag, see Statement. Returns the number of the removed
+// results
+func (db *DB) InvalidateTag(tag string) int {
+	return db.cache.Invalidate(tagKey(tag))
+}
+
+// statement returns the description of the normalized query
+func (db *DB) statement(normalized string) Statement {
+	return db.statements[normalized]
+}
+
+// lookup returns the cached rows of the key, removing the expired result
+func (db *DB) lookup(key string) interface{} {
+	value, ok := db.cache.Get(key)
+	if !ok {
+		return nil
+	}
+	stored, ok := value.(result)
+	if !ok {
+		return nil
+	}
+	if !stored.expires.IsZero() && !time.Now().Before(stored.expires) {
+		db.cache.Remove(key)
+		return nil
+	}
+
+	return stored.rows
+}
+
+// dependencies returns the keys the result of the statement depends on
+func (db *DB) dependencies(normalized string, st Statement) []string {
+	deps := make([]string, 0, len(st.Tags)+1)
+	deps = append(deps, statementKey(normalized))
+	for _, tag := range st.Tags {
+		deps = append(deps, tagKey(tag))
+	}
+
+	return deps
+}
+
+// resultKey returns the key of the result of the normalized query with the arguments. The arguments are keyed by the
+// values bound by database/sql, so the pointers are keyed by the values they point to, and driver.Valuer by its value
+func resultKey(normalized string, args []interface{}) (string, error) {
+	parts := make([]string, 0, len(args)+2)
+	parts = append(parts, "sql", normalized)
+	for _, arg := range args {
+		value, err := boundValue(arg)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%#v", value))
+	}
+
+	return golru.Key(parts...), nil
+}
+
+// boundValue returns the value of the argument as database/sql converts it for the driver. The named arguments keep
+// their names, and the values the default converter doesn't support, which the driver may still accept, are kept as
+// they are, but with the pointers dereferenced
+func boundValue(arg interface{}) (interface{}, error) {
+	if named, ok := arg.(sql.NamedArg); ok {
+		value, err := boundValue(named.Value)
+		if err != nil {
+			return nil, err
+		}
+		named.Value = value
+		return named, nil
+	}
+	if valuer, ok := arg.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	if value, err := driver.DefaultParameterConverter.ConvertValue(arg); err == nil {
+		return value, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// statementKey returns the key all the results of the normalized query depend on
+func statementKey(normalized string) string {
+	return golru.Key("sql-statement", normalized)
+}
+
+// tagKey returns the key all the results with the tag depend on
+func tagKey(tag string) string {
+	return golru.Key("sql-tag", tag)
+}
+
+// normalize collapses the runs of the whitespace out of the quoted literals and identifiers into single spaces and
+// trims the spaces and the semicolons at the ends of the query
+func normalize(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	var quote byte
+	space := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v':
+			space = true
+			continue
+		}
+		if space {
+			if b.Len() != 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+		}
+		b.WriteByte(ch)
+	}
+
+	return strings.TrimRight(b.String(), "; ")
+}
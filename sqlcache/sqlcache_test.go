@@ -0,0 +1,259 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qiwik/golru"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB is the table of the users served by the fake driver. It understands the selection of the names by the team
+// and the renaming of the user by the id, and counts the queries
+type fakeDB struct {
+	mu      sync.Mutex
+	names   map[int64]string
+	teams   map[int64]string
+	queries int
+}
+
+func (f *fakeDB) Open(string) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.names[args[1].(int64)] = args[0].(string)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.queries++
+	rows := &fakeRows{}
+	for id := int64(1); id <= int64(len(s.db.names)); id++ {
+		if s.db.teams[id] == args[0].(string) {
+			rows.names = append(rows.names, s.db.names[id])
+		}
+	}
+
+	return rows, nil
+}
+
+type fakeRows struct {
+	names []string
+	next  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.names) {
+		return io.EOF
+	}
+	dest[0] = r.names[r.next]
+	r.next++
+
+	return nil
+}
+
+var registerOnce sync.Once
+
+const (
+	selectNames = "SELECT name FROM users WHERE team = ?"
+	renameUser  = "UPDATE users SET name = ? WHERE id = ?"
+)
+
+// newDB returns the cache of the queries of the fake table
+func newDB(t *testing.T, opts ...Option) (*DB, *fakeDB) {
+	fake := &fakeDB{
+		names: map[int64]string{1: "alice", 2: "bob", 3: "carol"},
+		teams: map[int64]string{1: "red", 2: "red", 3: "blue"},
+	}
+	registerOnce.Do(func() { sql.Register("sqlcache-fake", &fakeDriver{}) })
+	drivers.Store(t.Name(), fake)
+
+	sqlDB, err := sql.Open("sqlcache-fake", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	c, err := golru.NewCache(100)
+	require.NoError(t, err)
+
+	return New(sqlDB, c, opts...), fake
+}
+
+// drivers keeps the fake tables by the names of the tests using them
+var drivers sync.Map
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fake, _ := drivers.Load(name)
+	return fake.(*fakeDB).Open(name)
+}
+
+func scanName(rows *sql.Rows) (string, error) {
+	var name string
+	err := rows.Scan(&name)
+	return name, err
+}
+
+func TestQueryCaches(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t)
+
+	names, err := Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, names)
+
+	names[0] = "changed"
+	names, err = Query(ctx, db, scanName, "SELECT  name\n\tFROM users WHERE team = ?;", "red")
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, names)
+	require.Equal(t, 1, fake.queries)
+
+	names, err = Query(ctx, db, scanName, selectNames, "blue")
+	require.NoError(t, err)
+	require.Equal(t, []string{"carol"}, names)
+	require.Equal(t, 2, fake.queries)
+}
+
+func TestQueryRow(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t)
+
+	name, err := QueryRow(ctx, db, scanName, selectNames, "blue")
+	require.NoError(t, err)
+	require.Equal(t, "carol", name)
+
+	_, err = QueryRow(ctx, db, scanName, selectNames, "green")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = QueryRow(ctx, db, scanName, selectNames, "green")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Equal(t, 2, fake.queries)
+}
+
+func TestStatementTTL(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t, WithTTL(time.Hour),
+		WithStatement(selectNames, Statement{TTL: 20 * time.Millisecond}))
+
+	_, err := Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	_, err = Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	require.Equal(t, 1, fake.queries)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	require.Equal(t, 2, fake.queries)
+}
+
+func TestBypass(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t, WithStatement(selectNames, Statement{TTL: -1}))
+
+	for i := 0; i < 2; i++ {
+		_, err := Query(ctx, db, scanName, selectNames, "red")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, fake.queries)
+}
+
+func TestInvalidate(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t)
+
+	_, err := Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	_, err = Query(ctx, db, scanName, selectNames, "blue")
+	require.NoError(t, err)
+
+	require.True(t, db.Invalidate(selectNames, "red"))
+	require.False(t, db.Invalidate(selectNames, "red"))
+	_, err = Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	require.Equal(t, 3, fake.queries)
+
+	require.Equal(t, 2, db.InvalidateStatement(selectNames))
+	_, err = Query(ctx, db, scanName, selectNames, "blue")
+	require.NoError(t, err)
+	require.Equal(t, 4, fake.queries)
+}
+
+func TestExecInvalidatesTags(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t,
+		WithStatement(selectNames, Statement{Tags: []string{"users"}}),
+		WithStatement(renameUser, Statement{Tags: []string{"users"}}))
+
+	_, err := Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, renameUser, "alicia", 1)
+	require.NoError(t, err)
+	names, err := Query(ctx, db, scanName, selectNames, "red")
+	require.NoError(t, err)
+	require.Equal(t, []string{"alicia", "bob"}, names)
+	require.Equal(t, 2, fake.queries)
+
+	require.Equal(t, 1, db.InvalidateTag("users"))
+	require.Zero(t, db.InvalidateTag("users"))
+}
+
+func TestNormalize(t *testing.T) {
+	require.Equal(t, "SELECT a FROM t WHERE b = '  x  '", normalize("  SELECT a\n FROM  t WHERE b = '  x  ' ;\n"))
+	require.Equal(t, `SELECT "a  b"`, normalize("SELECT\t\"a  b\""))
+	require.True(t, strings.HasPrefix(normalize(renameUser), "UPDATE users"))
+}
+
+func TestPointerArgs(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newDB(t)
+
+	team := "red"
+	names, err := Query(ctx, db, scanName, selectNames, &team)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, names)
+
+	team = "blue"
+	names, err = Query(ctx, db, scanName, selectNames, &team)
+	require.NoError(t, err)
+	require.Equal(t, []string{"carol"}, names)
+
+	names, err = Query(ctx, db, scanName, selectNames, "blue")
+	require.NoError(t, err)
+	require.Equal(t, []string{"carol"}, names)
+	require.Equal(t, 2, fake.queries)
+
+	require.True(t, db.Invalidate(selectNames, &team))
+}
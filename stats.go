@@ -0,0 +1,216 @@
+package golru
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// histogramBounds are the upper bounds of the histogram buckets. The last bucket of the histogram counts everything
+// above the largest bound
+var histogramBounds = [...]time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	24 * time.Hour,
+}
+
+// Histogram counts the observed durations in buckets from 1ms to 24h growing about tenfold, and one more bucket for
+// everything above
+type Histogram [len(histogramBounds) + 1]uint64
+
+// Stats is a snapshot of the cache counters accumulated since its creation. EvictionAge is the distribution of the age
+// of the elements at the moment of eviction by capacity, ttl or memory pressure, and AccessTTL is the distribution of
+// the remaining lifetime of the elements at the moment of the successful Get calls, if ttl is set. Locks are filled
+// only if the lock metrics are enabled. DroppedEvictions is the number of the evicted elements which didn't reach the
+// eviction callback because of the overflow policy. Promotions is the number of the elements moved from the nursery to
+// the main segment, and HotKeys is the number of the keys detected as hot. Compressed is the number of the values
+// compressed on the way in, and RawBytes and CompressedBytes are their sizes before and after the compression
+type Stats struct {
+	Hits             uint64
+	Misses           uint64
+	Adds             uint64
+	Evictions        uint64
+	Expirations      uint64
+	DroppedEvictions uint64
+	Promotions       uint64
+	HotKeys          uint64
+
+	Compressed      uint64
+	RawBytes        uint64
+	CompressedBytes uint64
+
+	EvictionAge Histogram
+	AccessTTL   Histogram
+
+	Locks LockStats
+}
+
+// Bounds returns the upper bounds of the histogram buckets except the last one, which has no bound
+func (h Histogram) Bounds() []time.Duration {
+	bounds := make([]time.Duration, len(histogramBounds))
+	copy(bounds, histogramBounds[:])
+
+	return bounds
+}
+
+// Count returns the total number of the observed durations
+func (h Histogram) Count() uint64 {
+	var count uint64
+	for _, n := range h {
+		count += n
+	}
+
+	return count
+}
+
+// Quantile returns the upper bound of the bucket containing the quantile q of the observed durations. Returns zero if
+// nothing was observed, and -1 if the quantile is above the largest bound
+func (h Histogram) Quantile(q float64) time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+
+	rank := uint64(q * float64(count))
+	var seen uint64
+	for i, n := range h[:len(histogramBounds)] {
+		seen += n
+		if seen > rank || seen == count {
+			return histogramBounds[i]
+		}
+	}
+
+	return -1
+}
+
+// observe counts the duration in its bucket
+func (h *Histogram) observe(d time.Duration) {
+	for i, bound := range histogramBounds {
+		if d <= bound {
+			h[i]++
+			return
+		}
+	}
+	h[len(histogramBounds)]++
+}
+
+// Delta returns the change of the counters since the previous snapshot, which allows periodic reporters to calculate
+// per-interval rates. If a counter is less than in the previous snapshot, the stats were reset in between, and its
+// current value is returned as is. The maximums of the lock stats are not deltas, they are kept as is
+func (s Stats) Delta(prev Stats) Stats {
+	delta := Stats{
+		Hits:             since(s.Hits, prev.Hits),
+		Misses:           since(s.Misses, prev.Misses),
+		Adds:             since(s.Adds, prev.Adds),
+		Evictions:        since(s.Evictions, prev.Evictions),
+		Expirations:      since(s.Expirations, prev.Expirations),
+		DroppedEvictions: since(s.DroppedEvictions, prev.DroppedEvictions),
+		Promotions:       since(s.Promotions, prev.Promotions),
+		HotKeys:          since(s.HotKeys, prev.HotKeys),
+		Compressed:       since(s.Compressed, prev.Compressed),
+		RawBytes:         since(s.RawBytes, prev.RawBytes),
+		CompressedBytes:  since(s.CompressedBytes, prev.CompressedBytes),
+		Locks: LockStats{
+			Acquisitions: since(s.Locks.Acquisitions, prev.Locks.Acquisitions),
+			Contended:    since(s.Locks.Contended, prev.Locks.Contended),
+			Wait:         time.Duration(since(uint64(s.Locks.Wait), uint64(prev.Locks.Wait))),
+			MaxWait:      s.Locks.MaxWait,
+			Hold:         time.Duration(since(uint64(s.Locks.Hold), uint64(prev.Locks.Hold))),
+			MaxHold:      s.Locks.MaxHold,
+		},
+	}
+	for i := range delta.EvictionAge {
+		delta.EvictionAge[i] = since(s.EvictionAge[i], prev.EvictionAge[i])
+		delta.AccessTTL[i] = since(s.AccessTTL[i], prev.AccessTTL[i])
+	}
+
+	return delta
+}
+
+// ResetStats sets all the counters of Stats to zero and forgets the reads observed by the miss ratio curve. The
+// statistics of the elements and the hit ratio window are kept
+func (c *cache) ResetStats() {
+	c.lock()
+	defer c.unlock()
+
+	c.stats = Stats{}
+	c.youngEvictions = 0
+	if c.curve != nil {
+		c.curve.reset()
+	}
+	c.resetTenantStats()
+	c.resetCallerStats()
+	if c.dispatcher != nil {
+		atomic.StoreUint64(&c.dispatcher.dropped, 0)
+	}
+}
+
+// since returns the increase of the counter, or its current value if it was reset after the previous value
+func since(current, prev uint64) uint64 {
+	if current < prev {
+		return current
+	}
+
+	return current - prev
+}
+
+// add sums the counters of other stats into these ones
+func (s *Stats) add(other Stats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.Adds += other.Adds
+	s.Evictions += other.Evictions
+	s.Expirations += other.Expirations
+	s.DroppedEvictions += other.DroppedEvictions
+	s.Promotions += other.Promotions
+	s.HotKeys += other.HotKeys
+	s.Compressed += other.Compressed
+	s.RawBytes += other.RawBytes
+	s.CompressedBytes += other.CompressedBytes
+	for i := range s.EvictionAge {
+		s.EvictionAge[i] += other.EvictionAge[i]
+		s.AccessTTL[i] += other.AccessTTL[i]
+	}
+	s.Locks.add(other.Locks)
+}
+
+// HitRatio returns the share of successful Get calls, or zero if there were no calls at all
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// countAccess counts the result of the Get call in the lifetime counters and in the window if it is enabled
+func (c *cache) countAccess(hit bool) {
+	if hit {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+
+	if c.window != nil {
+		c.window.record(time.Now(), hit)
+	}
+}
+
+// Stats returns the current values of the cache counters
+func (c *cache) Stats() Stats {
+	c.lock()
+	defer c.unlock()
+
+	stats := c.stats
+	if c.dispatcher != nil {
+		stats.DroppedEvictions = atomic.LoadUint64(&c.dispatcher.dropped)
+	}
+
+	return stats
+}
@@ -0,0 +1,264 @@
+package golru
+
+import (
+	"sort"
+)
+
+// TenantQuota limits the elements of a single tenant. MaxEntries is the number of the elements and MaxCost is their
+// total size given by the weigher, or by the length of the strings and byte slices without it. Zero means no limit
+type TenantQuota struct {
+	MaxEntries int
+	MaxCost    uint64
+}
+
+// TenancyConfig describes the tenants sharing the cache. Tenant extracts the tenant from the key, the first part of
+// the key made by Key or KeyBuilder by default. Quotas are the limits of the named tenants, and Default is the limit
+// of all the others
+type TenancyConfig struct {
+	Tenant  func(key string) string
+	Quotas  map[string]TenantQuota
+	Default TenantQuota
+}
+
+// TenantStats describes the usage of the cache by a single tenant. Evictions counts the elements of the tenant
+// evicted by its quota or by the capacity of the cache
+type TenantStats struct {
+	Tenant    string
+	Entries   int
+	Cost      uint64
+	Adds      uint64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// tenancy keeps the accounting of the tenants
+type tenancy struct {
+	cfg     TenancyConfig
+	tenants map[string]*tenant
+}
+
+// tenant is the accounting of a single tenant with the list of its elements
+type tenant struct {
+	quota    TenantQuota
+	stats    TenantStats
+	elements group
+}
+
+// tenantLinks returns the links of the element in the list of its tenant
+func tenantLinks(it *item) *groupLinks {
+	return &it.tenantLinks
+}
+
+// WithTenancy attributes every element to a tenant and limits the tenants by their quotas. A tenant reaching its quota
+// evicts its own least recently used elements, and when the whole cache is full, the victim is the least recently
+// used element of the tenant taking the largest share of its quota, or of the capacity if it has no limit of the
+// entries, so a single noisy tenant doesn't evict everyone else. The element not fitting into the quota at all is
+// rejected with ErrRejected
+func WithTenancy(cfg TenancyConfig) CacheOption {
+	return func(cache *cache) {
+		if cfg.Tenant == nil {
+			cfg.Tenant = func(key string) string {
+				return SplitKey(key)[0]
+			}
+		}
+		cache.tenancy = &tenancy{cfg: cfg, tenants: make(map[string]*tenant)}
+	}
+}
+
+// AddForTenant works like AddE, but attributes the element to the given tenant instead of the one extracted from the
+// key. Like GetEntry, it doesn't go through the hooks and the middleware. Without WithTenancy it is the same as AddE
+func (c *cache) AddForTenant(tenant, key string, value interface{}) error {
+	key, err := c.prepare(key)
+	if err != nil {
+		return err
+	}
+	attrs := c.attributes(value)
+	if c.transforms() {
+		if value, err = c.store(value); err != nil {
+			return err
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	c.stage(key, attrs)
+	if _, _, err = c.addAs(key, value, tenant); err != nil {
+		return err
+	}
+	c.countCompressed(value)
+
+	return nil
+}
+
+// TenantStats returns the usage of the cache by every tenant having the elements in the cache in the order of their
+// names, or nil without WithTenancy. The tenant is forgotten with its counters when its last element leaves the cache,
+// so the keys of the countless tenants can't grow the accounting without bound
+func (c *cache) TenantStats() []TenantStats {
+	c.lock()
+	defer c.unlock()
+
+	if c.tenancy == nil {
+		return nil
+	}
+
+	stats := make([]TenantStats, 0, len(c.tenancy.tenants))
+	for _, t := range c.tenancy.tenants {
+		stats = append(stats, t.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tenant < stats[j].Tenant })
+
+	return stats
+}
+
+// tenantOf returns the tenant extracted from the key, or an empty string without WithTenancy
+func (c *cache) tenantOf(key string) string {
+	if c.tenancy == nil {
+		return ""
+	}
+
+	return c.tenancy.cfg.Tenant(key)
+}
+
+// lookup returns the accounting of the tenant, or the new one if the tenant has no elements. The new tenant is
+// registered by its first element, see account
+func (t *tenancy) lookup(name string) *tenant {
+	if found, ok := t.tenants[name]; ok {
+		return found
+	}
+
+	quota, ok := t.cfg.Quotas[name]
+	if !ok {
+		quota = t.cfg.Default
+	}
+
+	return &tenant{quota: quota, stats: TenantStats{Tenant: name}, elements: group{links: tenantLinks}}
+}
+
+// admit makes room for the new element within the quota of its tenant evicting the least recently used elements of
+// the tenant, and returns the snapshot of the last evicted one. Returns ErrRejected if the element doesn't fit even
+// into the empty quota, or the rest of the elements of the tenant are leased
+func (c *cache) admit(t *tenant, cost uint64) (victim Entry, evicted bool, err error) {
+	if t.quota.MaxCost != 0 && cost > t.quota.MaxCost {
+		return Entry{}, false, ErrRejected
+	}
+
+	for (t.quota.MaxEntries != 0 && t.stats.Entries >= t.quota.MaxEntries) ||
+		(t.quota.MaxCost != 0 && t.stats.Cost+cost > t.quota.MaxCost) {
+		element := c.groupTail(&t.elements)
+		if element == nil {
+			return Entry{}, false, ErrRejected
+		}
+		victim, evicted = c.snapshot(element), true
+		c.evict(element, EvictedByCapacity)
+	}
+
+	return victim, evicted, nil
+}
+
+// victim returns the element evicted when the cache is full: the last unpinned element of the list, or of the tenant
+// taking the largest share of its quota with WithTenancy
+func (c *cache) victim() *item {
+	if c.tenancy == nil {
+		return c.tail()
+	}
+
+	var heaviest *tenant
+	var share float64
+	for _, t := range c.tenancy.tenants {
+		if t.stats.Entries == 0 {
+			continue
+		}
+		limit := t.quota.MaxEntries
+		if limit == 0 {
+			limit = int(c.capacity)
+		}
+		if s := float64(t.stats.Entries) / float64(limit); heaviest == nil || s > share {
+			heaviest, share = t, s
+		}
+	}
+	if heaviest != nil {
+		if element := c.groupTail(&heaviest.elements); element != nil {
+			return element
+		}
+	}
+
+	return c.tail()
+}
+
+// account adds the element to the usage and the list of its tenant, or removes it if the sign is negative. The first
+// element registers the tenant, and the last one leaving the cache removes it
+func (c *cache) account(it *item, sign int) {
+	t := it.tenant
+	if t == nil {
+		return
+	}
+
+	if sign > 0 {
+		if t.stats.Entries == 0 {
+			c.tenancy.tenants[t.stats.Tenant] = t
+		}
+		it.cost = c.valueBytes(it)
+		t.stats.Entries++
+		t.stats.Cost += it.cost
+		t.elements.pushFront(it)
+		return
+	}
+	t.elements.remove(it)
+	t.stats.Entries--
+	t.stats.Cost -= it.cost
+	if t.stats.Entries == 0 && c.tenancy.tenants[t.stats.Tenant] == t {
+		delete(c.tenancy.tenants, t.stats.Tenant)
+	}
+}
+
+// recost updates the usage of the tenant after the value of the element is replaced
+func (c *cache) recost(it *item) {
+	if it.tenant == nil {
+		return
+	}
+
+	it.tenant.stats.Cost -= it.cost
+	it.cost = c.valueBytes(it)
+	it.tenant.stats.Cost += it.cost
+}
+
+// countTenantRead counts the hit or the miss of the tenant of the element, or of the tenant extracted from the key if
+// the element isn't found. The miss of the tenant without the elements isn't counted, as it isn't tracked
+func (c *cache) countTenantRead(key string, element *item, hit bool) {
+	var t *tenant
+	if element != nil {
+		t = element.tenant
+	}
+	if t == nil {
+		if t = c.tenancy.tenants[c.tenancy.cfg.Tenant(key)]; t == nil {
+			return
+		}
+	}
+	if hit {
+		t.stats.Hits++
+	} else {
+		t.stats.Misses++
+	}
+}
+
+// resetTenants forgets all the tenants after the cache is cleared
+func (c *cache) resetTenants() {
+	if c.tenancy == nil {
+		return
+	}
+
+	c.tenancy.tenants = make(map[string]*tenant)
+}
+
+// resetTenantStats sets the counters of all the tenants to zero keeping their usage
+func (c *cache) resetTenantStats() {
+	if c.tenancy == nil {
+		return
+	}
+
+	for _, t := range c.tenancy.tenants {
+		t.stats = TenantStats{Tenant: t.stats.Tenant, Entries: t.stats.Entries, Cost: t.stats.Cost}
+	}
+}
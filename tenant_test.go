@@ -0,0 +1,125 @@
+package golru
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantQuota(t *testing.T) {
+	c, err := NewCache(100, WithTenancy(TenancyConfig{
+		Quotas:  map[string]TenantQuota{"small": {MaxEntries: 2}},
+		Default: TenantQuota{MaxCost: 10},
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.AddE(Key("small", strconv.Itoa(i)), i))
+	}
+	require.Equal(t, 2, c.Len())
+	_, ok := c.Get(Key("small", "4"))
+	require.True(t, ok)
+	_, ok = c.Get(Key("small", "0"))
+	require.False(t, ok)
+
+	require.NoError(t, c.AddE(Key("big", "1"), "12345"))
+	require.NoError(t, c.AddE(Key("big", "2"), "12345"))
+	require.NoError(t, c.AddE(Key("big", "3"), "1234"))
+	require.ErrorIs(t, c.AddE(Key("big", "4"), "12345678901"), ErrRejected)
+	_, ok = c.Get(Key("big", "1"))
+	require.False(t, ok)
+
+	stats := c.TenantStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, TenantStats{Tenant: "big", Entries: 2, Cost: 9, Adds: 3, Misses: 1, Evictions: 1}, stats[0])
+	require.Equal(t, TenantStats{Tenant: "small", Entries: 2, Adds: 5, Hits: 1, Misses: 1, Evictions: 3}, stats[1])
+
+	require.True(t, c.ChangeValue(Key("big", "2"), "1"))
+	require.Equal(t, uint64(5), c.TenantStats()[0].Cost)
+
+	c.ResetStats()
+	require.Equal(t, TenantStats{Tenant: "big", Entries: 2, Cost: 5}, c.TenantStats()[0])
+	c.Clear()
+	require.Empty(t, c.TenantStats())
+}
+
+func TestTenantForgotten(t *testing.T) {
+	c, err := NewCache(100, WithTenancy(TenancyConfig{Default: TenantQuota{MaxEntries: 2}}))
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		c.Get("missing" + strconv.Itoa(i))
+	}
+	require.Empty(t, c.TenantStats())
+	tc := c.(*cache)
+	require.Empty(t, tc.tenancy.tenants)
+
+	require.NoError(t, c.AddE(Key("a", "1"), 1))
+	require.NoError(t, c.AddE(Key("a", "2"), 2))
+	c.Get(Key("a", "1"))
+	require.NoError(t, c.AddE(Key("a", "3"), 3))
+	_, ok := c.Get(Key("a", "2"))
+	require.False(t, ok)
+	_, ok = c.Get(Key("a", "1"))
+	require.True(t, ok)
+
+	require.True(t, c.Remove(Key("a", "1")))
+	require.True(t, c.Remove(Key("a", "3")))
+	require.Empty(t, tc.tenancy.tenants)
+}
+
+func TestTenantGetBytes(t *testing.T) {
+	c, err := NewCache(100, WithTenancy(TenancyConfig{}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddE(Key("small", "1"), 1))
+	_, ok := c.GetBytes([]byte(Key("small", "1")))
+	require.True(t, ok)
+	_, ok = c.GetBytes([]byte(Key("small", "2")))
+	require.False(t, ok)
+
+	require.Equal(t, TenantStats{Tenant: "small", Entries: 1, Adds: 1, Hits: 1, Misses: 1}, c.TenantStats()[0])
+}
+
+func TestTenantNoisyNeighbour(t *testing.T) {
+	c, err := NewCache(10, WithTenancy(TenancyConfig{}))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		c.Add(Key("quiet", strconv.Itoa(i)), i)
+	}
+	for i := 0; i < 50; i++ {
+		c.Add(Key("noisy", strconv.Itoa(i)), i)
+	}
+
+	require.Equal(t, 10, c.Len())
+	for i := 0; i < 3; i++ {
+		_, ok := c.Get(Key("quiet", strconv.Itoa(i)))
+		require.True(t, ok)
+	}
+	require.Equal(t, 7, c.TenantStats()[0].Entries)
+}
+
+func TestAddForTenant(t *testing.T) {
+	c, err := NewCache(10, WithTenancy(TenancyConfig{Quotas: map[string]TenantQuota{"vip": {MaxEntries: 1}}}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddForTenant("vip", "first", 1))
+	require.NoError(t, c.AddForTenant("vip", "second", 2))
+	require.ErrorIs(t, c.AddForTenant("vip", "second", 2), ErrKeyExists)
+	require.Equal(t, 1, c.Len())
+	c.Get("second")
+
+	stats := c.TenantStats()
+	require.Len(t, stats, 1)
+	require.Equal(t, TenantStats{Tenant: "vip", Entries: 1, Adds: 2, Hits: 1, Evictions: 1}, stats[0])
+
+	clone := c.Clone(nil)
+	require.Equal(t, 1, clone.TenantStats()[0].Entries)
+
+	plain, err := NewCache(10)
+	require.NoError(t, err)
+	require.NoError(t, plain.AddForTenant("vip", "first", 1))
+	require.Nil(t, plain.TenantStats())
+}
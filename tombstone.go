@@ -0,0 +1,73 @@
+package golru
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTombstoned = errors.New("key was removed recently and can't be added during the grace period")
+
+// tombstones keeps the recently removed keys until their grace period ends. The grace period is the same for all the
+// keys, so the order of the removals is the order of the expiration
+type tombstones struct {
+	grace time.Duration
+	until map[string]time.Time
+	order []tombstone
+}
+
+// tombstone is the removal of the key waiting for its grace period to end
+type tombstone struct {
+	key   string
+	until time.Time
+}
+
+// WithTombstones keeps the tombstone of every key removed by Remove and its variants, Invalidate, the index, the tree
+// and Purge for the grace period, and the adds of the key return ErrTombstoned until it ends, so the late writer with
+// the stale data can't put back the value which was just invalidated. The loaded values of GetMulti aren't cached
+// then either, while the elements leaving the cache by capacity, ttl or Clear leave no tombstones
+func WithTombstones(grace time.Duration) CacheOption {
+	return func(cache *cache) {
+		if grace <= 0 {
+			cache.tombstones = nil
+			return
+		}
+		cache.tombstones = &tombstones{grace: grace, until: make(map[string]time.Time)}
+	}
+}
+
+// entomb leaves the tombstone of the removed key
+func (c *cache) entomb(key string) {
+	if c.tombstones == nil {
+		return
+	}
+
+	now := time.Now()
+	c.tombstones.prune(now)
+	until := now.Add(c.tombstones.grace)
+	c.tombstones.until[key] = until
+	c.tombstones.order = append(c.tombstones.order, tombstone{key: key, until: until})
+}
+
+// tombstoned reports whether the key was removed within the grace period
+func (c *cache) tombstoned(key string) bool {
+	if c.tombstones == nil {
+		return false
+	}
+
+	now := time.Now()
+	c.tombstones.prune(now)
+	_, ok := c.tombstones.until[key]
+
+	return ok
+}
+
+// prune drops the tombstones whose grace period has ended. The key removed again keeps only its latest tombstone
+func (t *tombstones) prune(now time.Time) {
+	for len(t.order) != 0 && !now.Before(t.order[0].until) {
+		if first := t.order[0]; t.until[first.key].Equal(first.until) {
+			delete(t.until, first.key)
+		}
+		t.order[0] = tombstone{}
+		t.order = t.order[1:]
+	}
+}
@@ -0,0 +1,54 @@
+package golru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTombstones(t *testing.T) {
+	c, err := NewCache(1, WithTombstones(30*time.Millisecond))
+	require.NoError(t, err)
+
+	c.Add("perm", 1)
+	require.True(t, c.Remove("perm"))
+	require.ErrorIs(t, c.AddE("perm", 2), ErrTombstoned)
+	require.False(t, c.Add("perm", 2))
+	_, loaded := c.GetOrAdd("perm", 2)
+	require.False(t, loaded)
+	require.Equal(t, 0, c.Len())
+
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"perm": 3}, nil
+	}
+	values, err := c.GetMulti(context.Background(), []string{"perm"}, load)
+	require.NoError(t, err)
+	require.Equal(t, 3, values["perm"])
+	require.Equal(t, 0, c.Len())
+
+	c.Add("evicted", 1)
+	c.Add("other", 2)
+	require.NoError(t, c.AddE("evicted", 3))
+
+	require.Equal(t, 0, c.Invalidate("external"))
+	require.ErrorIs(t, c.AddE("external", 1), ErrTombstoned)
+
+	time.Sleep(40 * time.Millisecond)
+	require.NoError(t, c.AddE("perm", 4))
+	require.Empty(t, c.(*cache).tombstones.until)
+}
+
+func TestTombstonesInvalidatedAgain(t *testing.T) {
+	c, err := NewCache(0, WithTombstones(30*time.Millisecond))
+	require.NoError(t, err)
+
+	c.Invalidate("key")
+	time.Sleep(20 * time.Millisecond)
+	c.Invalidate("key")
+	time.Sleep(15 * time.Millisecond)
+	require.ErrorIs(t, c.AddE("key", 1), ErrTombstoned)
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, c.AddE("key", 1))
+}
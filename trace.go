@@ -0,0 +1,264 @@
+package golru
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrTraceFormat = errors.New("trace record is malformed")
+
+// TraceFormat is the encoding of the recorded trace
+type TraceFormat int
+
+const (
+	// TraceBinary writes every record as the varint distance in nanoseconds from the previous one, the byte of the
+	// operation and the hit flag, and the key prefixed by its varint length. It is the most compact format
+	TraceBinary TraceFormat = iota
+	// TraceCSV writes every record as the line of the unix time in nanoseconds, the name of the operation, the key
+	// and 1 or 0 for the hit
+	TraceCSV
+)
+
+// TraceRecord is a single operation of the recorded trace. Hit has the same meaning as in AfterHook
+type TraceRecord struct {
+	Time time.Time
+	Op   Op
+	Key  string
+	Hit  bool
+}
+
+// TraceRecorder writes the operations of the cache to the writer for the offline analysis. The keys are sampled by
+// their hash, so either all the operations with the key are recorded or none of them, which keeps the sampled trace
+// usable for the simulation of a proportionally smaller cache
+type TraceRecorder struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	csv       *csv.Writer
+	format    TraceFormat
+	threshold uint64
+	last      int64
+	err       error
+}
+
+// NewTraceRecorder creates the recorder writing to w in the given format. Sample is the fraction of the keys
+// recorded, the values out of the range (0, 1) make it record all of them. The records are buffered, call Flush to
+// write them out
+func NewTraceRecorder(w io.Writer, format TraceFormat, sample float64) *TraceRecorder {
+	r := &TraceRecorder{w: bufio.NewWriter(w), format: format, threshold: math.MaxUint64}
+	if sample > 0 && sample < 1 {
+		r.threshold = uint64(sample * math.MaxUint64)
+	}
+	if format == TraceCSV {
+		r.csv = csv.NewWriter(r.w)
+	}
+
+	return r
+}
+
+// WithTraceRecorder makes the cache write every operation with a key to the recorder after it is performed
+func WithTraceRecorder(r *TraceRecorder) CacheOption {
+	return func(cache *cache) {
+		cache.tracer = r
+	}
+}
+
+// Record writes the record if its key is sampled. After the first error of the writer the records are skipped and
+// the error is returned by Flush
+func (r *TraceRecorder) Record(rec TraceRecord) {
+	if r.sampled(rec.Key) {
+		r.write(rec)
+	}
+}
+
+// write writes the record regardless of the sampling
+func (r *TraceRecorder) write(rec TraceRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	if r.format == TraceCSV {
+		hit := "0"
+		if rec.Hit {
+			hit = "1"
+		}
+		r.err = r.csv.Write([]string{strconv.FormatInt(rec.Time.UnixNano(), 10), rec.Op.String(), rec.Key, hit})
+		return
+	}
+
+	now := rec.Time.UnixNano()
+	delta := now - r.last
+	if delta < 0 {
+		delta = 0
+	}
+	r.last += delta
+
+	var buf [2*binary.MaxVarintLen64 + 1]byte
+	n := binary.PutUvarint(buf[:], uint64(delta))
+	flags := byte(rec.Op) << 1
+	if rec.Hit {
+		flags |= 1
+	}
+	buf[n] = flags
+	n++
+	n += binary.PutUvarint(buf[n:], uint64(len(rec.Key)))
+	if _, r.err = r.w.Write(buf[:n]); r.err == nil {
+		_, r.err = r.w.WriteString(rec.Key)
+	}
+}
+
+// Flush writes the buffered records to the writer and returns the first error of the recording
+func (r *TraceRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if r.csv != nil {
+		r.csv.Flush()
+		if r.err = r.csv.Error(); r.err != nil {
+			return r.err
+		}
+	}
+	r.err = r.w.Flush()
+
+	return r.err
+}
+
+// sampled reports whether the key falls into the sample
+func (r *TraceRecorder) sampled(key string) bool {
+	if r.threshold == math.MaxUint64 {
+		return true
+	}
+
+	return sampleHash(key) <= r.threshold
+}
+
+// sampleHash returns the hash of the key used for sampling. It doesn't depend on a random seed, so the same keys are
+// sampled in every process. FNV-1a is mixed by the finalizer of splitmix64, because its high bits are poorly
+// distributed for short keys
+func sampleHash(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
+
+// wrapTrace records the operations after they are performed. The operations with the context marked by ForceTrace
+// are recorded regardless of the sampling
+func (c *cache) wrapTrace(next ctxOpFunc) ctxOpFunc {
+	return func(ctx context.Context, op Op, key string, value interface{}) (interface{}, error) {
+		start := time.Now()
+		result, err := next(ctx, op, key, value)
+		if op != OpClear {
+			record := TraceRecord{Time: start, Op: op, Key: c.redact(key), Hit: err == nil}
+			if forced, _ := ctx.Value(forceTraceKey{}).(bool); forced {
+				c.tracer.write(record)
+			} else {
+				c.tracer.Record(record)
+			}
+		}
+
+		return result, err
+	}
+}
+
+// TraceReader reads the trace written by TraceRecorder in the same format
+type TraceReader struct {
+	r      *bufio.Reader
+	csv    *csv.Reader
+	format TraceFormat
+	last   int64
+}
+
+// NewTraceReader creates the reader of the trace in the given format
+func NewTraceReader(r io.Reader, format TraceFormat) *TraceReader {
+	tr := &TraceReader{r: bufio.NewReader(r), format: format}
+	if format == TraceCSV {
+		tr.csv = csv.NewReader(tr.r)
+		tr.csv.FieldsPerRecord = 4
+	}
+
+	return tr
+}
+
+// Next returns the next record of the trace, io.EOF at the end of it, or ErrTraceFormat if the record is malformed
+func (tr *TraceReader) Next() (TraceRecord, error) {
+	if tr.format == TraceCSV {
+		return tr.nextCSV()
+	}
+
+	delta, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return TraceRecord{}, err
+	}
+	flags, err := tr.r.ReadByte()
+	if err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	length, err := binary.ReadUvarint(tr.r)
+	if err != nil || length > math.MaxInt32 {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	key := make([]byte, length)
+	if _, err := io.ReadFull(tr.r, key); err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+
+	tr.last += int64(delta)
+
+	return TraceRecord{Time: time.Unix(0, tr.last), Op: Op(flags >> 1), Key: string(key), Hit: flags&1 != 0}, nil
+}
+
+// nextCSV returns the next record of the trace in CSV
+func (tr *TraceReader) nextCSV() (TraceRecord, error) {
+	fields, err := tr.csv.Read()
+	if err == io.EOF {
+		return TraceRecord{}, err
+	}
+	if err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+
+	nanos, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	op := parseOp(fields[1])
+	if op == 0 || (fields[3] != "0" && fields[3] != "1") {
+		return TraceRecord{}, ErrTraceFormat
+	}
+
+	return TraceRecord{Time: time.Unix(0, nanos), Op: op, Key: fields[2], Hit: fields[3] == "1"}, nil
+}
+
+// parseOp returns the operation by its name, zero if there is none
+func parseOp(name string) Op {
+	for op := OpAdd; op <= OpUpdate; op++ {
+		if op.String() == name {
+			return op
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,81 @@
+package golru
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	for _, format := range []TraceFormat{TraceBinary, TraceCSV} {
+		var buf bytes.Buffer
+		rec := NewTraceRecorder(&buf, format, 1)
+		c, err := NewCache(10, WithTraceRecorder(rec))
+		require.NoError(t, err)
+
+		c.Add("first", 1)
+		c.Get("first")
+		c.Get("missing")
+		c.Remove("first")
+		c.Clear()
+		require.NoError(t, rec.Flush())
+
+		r := NewTraceReader(&buf, format)
+		var records []TraceRecord
+		for {
+			record, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			records = append(records, record)
+		}
+
+		require.Len(t, records, 4)
+		expected := []struct {
+			op  Op
+			key string
+			hit bool
+		}{{OpAdd, "first", true}, {OpGet, "first", true}, {OpGet, "missing", false}, {OpRemove, "first", true}}
+		for i, e := range expected {
+			require.Equal(t, e.op, records[i].Op)
+			require.Equal(t, e.key, records[i].Key)
+			require.Equal(t, e.hit, records[i].Hit)
+			require.WithinDuration(t, time.Now(), records[i].Time, time.Second)
+		}
+		require.False(t, records[1].Time.Before(records[0].Time))
+	}
+}
+
+func TestTraceSampling(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewTraceRecorder(&buf, TraceCSV, 0.25)
+	for i := 0; i < 1000; i++ {
+		key := "test" + strconv.Itoa(i%100)
+		rec.Record(TraceRecord{Time: time.Now(), Op: OpGet, Key: key})
+	}
+	require.NoError(t, rec.Flush())
+
+	keys := make(map[string]int)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines {
+		keys[strings.Split(line, ",")[2]]++
+	}
+	require.InDelta(t, 25, len(keys), 15)
+	for _, n := range keys {
+		require.Equal(t, 10, n)
+	}
+}
+
+func TestTraceReaderMalformed(t *testing.T) {
+	_, err := NewTraceReader(strings.NewReader("1,fly,key,1\n"), TraceCSV).Next()
+	require.ErrorIs(t, err, ErrTraceFormat)
+
+	_, err = NewTraceReader(bytes.NewReader([]byte{1, 4, 10, 'a'}), TraceBinary).Next()
+	require.ErrorIs(t, err, ErrTraceFormat)
+}
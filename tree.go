@@ -0,0 +1,181 @@
+package golru
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// keyTree keeps the keys split by the separator as a trie, so the keys under a common path are found without
+// scanning the whole cache
+type keyTree struct {
+	separator string
+	root      *treeNode
+}
+
+// treeNode is a single segment of the path. Stored reports whether the key ending at the node is in the cache
+type treeNode struct {
+	children map[string]*treeNode
+	stored   bool
+}
+
+// WithKeyTree treats the keys as the paths with the segments divided by the separator, for example "a/b/c" with '/',
+// and keeps them in a trie, so InvalidateSubtree and ListChildren work with the part of the keys under the path
+// instead of all of them. The trie is updated whenever an element is added or leaves the cache
+func WithKeyTree(separator byte) CacheOption {
+	return func(cache *cache) {
+		cache.tree = &keyTree{separator: string(separator), root: &treeNode{}}
+	}
+}
+
+// InvalidateSubtree removes the element with the path and all the elements under it, like Remove does, except the
+// leased ones, so "a/b" removes "a/b" and "a/b/c", but not "a/bc". The empty path removes all the elements. Returns
+// the number of removed elements, zero without WithKeyTree
+func (c *cache) InvalidateSubtree(path string) int {
+	c.lock()
+	defer c.unlock()
+
+	if c.tree == nil {
+		return 0
+	}
+
+	var keys []string
+	c.tree.walk(c.tree.find(path), path, func(key string) {
+		keys = append(keys, key)
+	})
+
+	removed := 0
+	now := time.Now()
+	for _, key := range keys {
+		element, ok := c.items[key]
+		if !ok || c.pinned(element, now) {
+			continue
+		}
+		c.removeElement(element)
+		c.queueRemoval(element.key, element.value)
+		c.queueAudit(element.key)
+		c.entomb(element.key)
+		c.release(element)
+		removed++
+	}
+
+	return removed
+}
+
+// ListChildren returns the paths right under the path in the sorted order, both of the elements and of the segments
+// only leading to the deeper elements, so the tree can be browsed level by level. The empty path lists the first
+// segments. Returns nil without WithKeyTree
+func (c *cache) ListChildren(path string) []string {
+	c.lock()
+	defer c.unlock()
+
+	if c.tree == nil {
+		return nil
+	}
+
+	node := c.tree.find(path)
+	if node == nil {
+		return nil
+	}
+
+	children := make([]string, 0, len(node.children))
+	for name := range node.children {
+		children = append(children, c.tree.join(path, name))
+	}
+	sort.Strings(children)
+
+	return children
+}
+
+// plant puts the key of the element to the tree
+func (c *cache) plant(element *item) {
+	if c.tree == nil {
+		return
+	}
+
+	node := c.tree.root
+	for _, segment := range strings.Split(element.key, c.tree.separator) {
+		child, ok := node.children[segment]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*treeNode)
+			}
+			child = &treeNode{}
+			node.children[segment] = child
+		}
+		node = child
+	}
+	node.stored = true
+}
+
+// uproot deletes the key of the element from the tree together with the segments which lead to nothing else
+func (c *cache) uproot(element *item) {
+	if c.tree == nil {
+		return
+	}
+
+	segments := strings.Split(element.key, c.tree.separator)
+	path := make([]*treeNode, 0, len(segments)+1)
+	node := c.tree.root
+	path = append(path, node)
+	for _, segment := range segments {
+		if node = node.children[segment]; node == nil {
+			return
+		}
+		path = append(path, node)
+	}
+
+	node.stored = false
+	for i := len(segments) - 1; i >= 0; i-- {
+		if child := path[i+1]; child.stored || len(child.children) != 0 {
+			return
+		}
+		delete(path[i].children, segments[i])
+	}
+}
+
+// resetTree empties the tree after the cache is cleared
+func (c *cache) resetTree() {
+	if c.tree != nil {
+		c.tree.root = &treeNode{}
+	}
+}
+
+// find returns the node of the path, the root for the empty one, or nil if there is no such node
+func (t *keyTree) find(path string) *treeNode {
+	if path == "" {
+		return t.root
+	}
+
+	node := t.root
+	for _, segment := range strings.Split(path, t.separator) {
+		if node = node.children[segment]; node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// walk calls fn for the keys of the stored nodes under the node including itself
+func (t *keyTree) walk(node *treeNode, path string, fn func(key string)) {
+	if node == nil {
+		return
+	}
+
+	if node.stored && node != t.root {
+		fn(path)
+	}
+	for name, child := range node.children {
+		t.walk(child, t.join(path, name), fn)
+	}
+}
+
+// join returns the path of the child segment
+func (t *keyTree) join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + t.separator + name
+}
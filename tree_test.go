@@ -0,0 +1,69 @@
+package golru
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyTree(t *testing.T) {
+	c, err := NewCache(0, WithKeyTree('/'))
+	require.NoError(t, err)
+
+	for _, key := range []string{"a/b", "a/b/c", "a/b/d/e", "a/bc", "x"} {
+		c.Add(key, key)
+	}
+
+	require.Equal(t, []string{"a", "x"}, c.ListChildren(""))
+	require.Equal(t, []string{"a/b", "a/bc"}, c.ListChildren("a"))
+	require.Equal(t, []string{"a/b/c", "a/b/d"}, c.ListChildren("a/b"))
+	require.Empty(t, c.ListChildren("a/b/c"))
+	require.Nil(t, c.ListChildren("missing"))
+
+	_, err = c.AcquireLease("a/b/c", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2, c.InvalidateSubtree("a/b"))
+	keys := c.Keys()
+	sort.Strings(keys)
+	require.Equal(t, []string{"a/b/c", "a/bc", "x"}, keys)
+	require.Equal(t, []string{"a/b/c"}, c.ListChildren("a/b"))
+	require.Equal(t, 0, c.InvalidateSubtree("a/b/d"))
+
+	require.True(t, c.Remove("x"))
+	require.Equal(t, []string{"a"}, c.ListChildren(""))
+	c.Clear()
+	require.Empty(t, c.ListChildren(""))
+}
+
+func TestKeyTreeEviction(t *testing.T) {
+	c, err := NewCache(2, WithKeyTree(':'))
+	require.NoError(t, err)
+
+	c.Add(Key("user", "1", "profile"), 1)
+	c.Add(Key("user", "2", "profile"), 2)
+	c.Add(Key("order", "1"), 3)
+	require.Equal(t, []string{"user:2"}, c.ListChildren("user"))
+	require.Equal(t, 1, c.InvalidateSubtree("user"))
+	require.Equal(t, []string{"order"}, c.ListChildren(""))
+
+	plain, err := NewCache(0)
+	require.NoError(t, err)
+	plain.Add("a/b", 1)
+	require.Zero(t, plain.InvalidateSubtree("a"))
+	require.Nil(t, plain.ListChildren(""))
+}
+
+func BenchmarkInvalidateSubtree(b *testing.B) {
+	c, _ := NewCache(0, WithKeyTree('/'))
+	for i := 0; i < 100000; i++ {
+		c.Add("tenant/"+strconv.Itoa(i%100)+"/"+strconv.Itoa(i), i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.InvalidateSubtree("tenant/" + strconv.Itoa(i%100))
+	}
+}
@@ -0,0 +1,78 @@
+package golru
+
+import (
+	"context"
+	"errors"
+)
+
+const defaultTrimBatch = 64
+
+var (
+	ErrSoftCapacity   = errors.New("soft capacity should be less than capacity")
+	ErrNoSoftCapacity = errors.New("soft capacity is not set")
+)
+
+// WithSoftCapacity sets the soft limit of the number of entries. The capacity passed to NewCache becomes the hard
+// limit, which is still enforced by Add itself, while the elements above the soft limit are evicted in the background
+// by batches of the given size after Trim is started. Batch is 64 by default
+func WithSoftCapacity(soft uint32, batch int) CacheOption {
+	return func(cache *cache) {
+		if batch <= 0 {
+			batch = defaultTrimBatch
+		}
+		cache.softCapacity = soft
+		cache.trimBatch = batch
+		cache.trimSignal = make(chan struct{}, 1)
+	}
+}
+
+// Trim starts the background eviction of the last elements above the soft capacity. Returns error if soft capacity
+// isn't set. Every batch is evicted under a separate lock, so the other operations can go on between them. Trimming
+// stops when the context is done
+func (c *cache) Trim(ctx context.Context) error {
+	if c.softCapacity == 0 {
+		return ErrNoSoftCapacity
+	}
+
+	c.background(ctx, "trim", func(ctx context.Context) {
+		for {
+			select {
+			case <-c.trimSignal:
+				for c.trimBatchTail() {
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// trimBatchTail evicts a single batch of the elements above the soft capacity and reports whether there are more
+func (c *cache) trimBatchTail() bool {
+	c.lock()
+	defer c.unlock()
+
+	for i := 0; i < c.trimBatch && c.chain.Len() > int(c.softCapacity); i++ {
+		victim := c.tail()
+		if victim == nil {
+			return false
+		}
+		c.evict(victim, EvictedByCapacity)
+	}
+
+	return c.chain.Len() > int(c.softCapacity)
+}
+
+// signalTrim wakes the background trimming up without blocking if the soft capacity is exceeded
+func (c *cache) signalTrim() {
+	if c.softCapacity == 0 || c.chain.Len() <= int(c.softCapacity) {
+		return
+	}
+
+	select {
+	case c.trimSignal <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,55 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftCapacityInvalid(t *testing.T) {
+	c, err := NewCache(10, WithSoftCapacity(10, 0))
+	require.ErrorIs(t, err, ErrSoftCapacity)
+	require.Nil(t, c)
+
+	c, err = NewCache(10)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Trim(context.Background()), ErrNoSoftCapacity)
+}
+
+func TestSoftCapacityTrim(t *testing.T) {
+	c, err := NewCache(100, WithSoftCapacity(10, 3))
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	for i := 0; i < 50; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+	require.Equal(t, 50, c.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Trim(ctx))
+
+	c.Add("new", 42)
+	require.Eventually(t, func() bool {
+		tc.mu.Lock()
+		defer tc.mu.Unlock()
+		return tc.chain.Len() == 10
+	}, time.Second, 5*time.Millisecond)
+
+	_, ok := c.Get("new")
+	require.True(t, ok)
+}
+
+func TestSoftCapacityHardLimit(t *testing.T) {
+	c, err := NewCache(5, WithSoftCapacity(2, 1))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+	}
+	require.Equal(t, 5, c.Len())
+}
@@ -0,0 +1,117 @@
+package golru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultTuneStep  = 0.1
+	defaultTuneChurn = 0.05
+)
+
+var (
+	ErrTuneBounds   = errors.New("min capacity should be greater than 0 and not greater than max capacity")
+	ErrTuneInterval = errors.New("tuning interval should be greater than 0")
+)
+
+// TuneConfig describes the limits and the pace of the capacity auto-tuning. Min and Max bound the capacity, Interval
+// is the period of the adjustments. Step is the share of the current capacity added or removed at once, 0.1 by
+// default. Churn is the share of the newly added elements evicted before the end of the interval, above which the
+// cache grows, 0.05 by default. If TargetHitRatio is set, the cache doesn't grow while the hit ratio of the interval
+// reaches it
+type TuneConfig struct {
+	Min      uint32
+	Max      uint32
+	Interval time.Duration
+
+	Step           float64
+	Churn          float64
+	TargetHitRatio float64
+}
+
+// AutoTune starts the periodic adjustment of the capacity. The cache grows while the elements leave it too early
+// because of capacity, and shrinks to the number of elements plus a step while there are no evictions. Shrinking
+// never evicts elements by itself. Tuning stops when the context is done
+func (c *cache) AutoTune(ctx context.Context, cfg TuneConfig) error {
+	if cfg.Min == 0 || cfg.Min > cfg.Max {
+		return ErrTuneBounds
+	}
+	if cfg.Interval <= 0 {
+		return ErrTuneInterval
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = defaultTuneStep
+	}
+	if cfg.Churn <= 0 {
+		cfg.Churn = defaultTuneChurn
+	}
+
+	c.lock()
+	c.youngAge = cfg.Interval
+	switch {
+	case c.capacity == 0 || c.capacity > cfg.Max:
+		c.resize(cfg.Max)
+	case c.capacity < cfg.Min:
+		c.resize(cfg.Min)
+	}
+	prev, prevYoung := c.stats, c.youngEvictions
+	c.unlock()
+
+	ticker := time.NewTicker(cfg.Interval)
+	c.background(ctx, "tune", func(ctx context.Context) {
+		for {
+			select {
+			case <-ticker.C:
+				prev, prevYoung = c.tune(cfg, prev, prevYoung)
+			case <-ctx.Done():
+				ticker.Stop()
+				c.lock()
+				c.youngAge = 0
+				c.unlock()
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// tune makes a single adjustment of the capacity based on the counters changed since the previous one
+func (c *cache) tune(cfg TuneConfig, prev Stats, prevYoung uint64) (Stats, uint64) {
+	c.lock()
+	defer c.unlock()
+
+	interval := c.stats.Delta(prev)
+	adds, evictions := interval.Adds, interval.Evictions
+	young := since(c.youngEvictions, prevYoung)
+
+	step := uint32(float64(c.capacity) * cfg.Step)
+	if step == 0 {
+		step = 1
+	}
+
+	switch {
+	case adds != 0 && float64(young)/float64(adds) > cfg.Churn:
+		if cfg.TargetHitRatio != 0 && interval.HitRatio() >= cfg.TargetHitRatio {
+			break
+		}
+		newCap := c.capacity + step
+		if newCap > cfg.Max || newCap < c.capacity {
+			newCap = cfg.Max
+		}
+		c.log(slog.LevelDebug, "golru: capacity tuned", "from", c.capacity, "to", newCap)
+		c.resize(newCap)
+	case evictions == 0 && c.chain.Len()+int(step) < int(c.capacity):
+		newCap := c.capacity - step
+		if newCap < cfg.Min {
+			newCap = cfg.Min
+		}
+		c.log(slog.LevelDebug, "golru: capacity tuned", "from", c.capacity, "to", newCap)
+		c.resize(newCap)
+	}
+
+	return c.stats, c.youngEvictions
+}
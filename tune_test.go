@@ -0,0 +1,66 @@
+package golru
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoTuneInvalidConfig(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.ErrorIs(t, c.AutoTune(ctx, TuneConfig{Min: 0, Max: 10, Interval: time.Second}), ErrTuneBounds)
+	require.ErrorIs(t, c.AutoTune(ctx, TuneConfig{Min: 20, Max: 10, Interval: time.Second}), ErrTuneBounds)
+	require.ErrorIs(t, c.AutoTune(ctx, TuneConfig{Min: 1, Max: 10}), ErrTuneInterval)
+}
+
+func TestAutoTuneGrow(t *testing.T) {
+	c, err := NewCache(4)
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = c.AutoTune(ctx, TuneConfig{Min: 4, Max: 8, Interval: 20 * time.Millisecond, Step: 0.5})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		c.Add("test"+strconv.Itoa(i), i)
+		time.Sleep(time.Millisecond)
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	require.Equal(t, uint32(8), tc.capacity)
+}
+
+func TestAutoTuneShrink(t *testing.T) {
+	c, err := NewCache(20)
+	require.NoError(t, err)
+
+	tc := c.(*cache)
+	c.Add("test", 42)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = c.AutoTune(ctx, TuneConfig{Min: 5, Max: 20, Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	time.Sleep(300 * time.Millisecond)
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	require.Equal(t, uint32(5), tc.capacity)
+	require.Equal(t, 1, tc.chain.Len())
+}
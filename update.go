@@ -0,0 +1,107 @@
+package golru
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrValueType = errors.New("value has an unexpected type")
+
+// UpdateFunc returns the new value of the element from the current one. Exists is false if there is no such key, then
+// old is nil. The error cancels the update
+type UpdateFunc func(old interface{}, exists bool) (interface{}, error)
+
+// Update replaces the value of the key by the result of the function, adding the element if there is no such key.
+// The function is called under the cache lock, so the read-modify-write is atomic, and it must not use the methods
+// of the cache. Returns the new value, the error of the function or ErrLeased if the element is leased
+func (c *cache) Update(key string, fn UpdateFunc) (interface{}, error) {
+	return c.do(OpUpdate, key, fn)
+}
+
+// AppendToSlice appends the elements to the []interface{} value of the key, creating it if there is no such key, and
+// returns the new length. The slice is copied, so the readers holding the previous value don't see the change.
+// Returns ErrValueType if the value isn't a []interface{}
+func (c *cache) AppendToSlice(key string, elems ...interface{}) (int, error) {
+	value, err := c.Update(key, func(old interface{}, exists bool) (interface{}, error) {
+		if !exists {
+			return append([]interface{}(nil), elems...), nil
+		}
+
+		slice, ok := old.([]interface{})
+		if !ok {
+			return nil, ErrValueType
+		}
+
+		return append(slice[:len(slice):len(slice)], elems...), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(value.([]interface{})), nil
+}
+
+// Increment adds delta to the int64 value of the key, starting from zero if there is no such key, and returns the
+// new value. Returns ErrValueType if the value isn't an int64
+func (c *cache) Increment(key string, delta int64) (int64, error) {
+	value, err := c.Update(key, func(old interface{}, exists bool) (interface{}, error) {
+		if !exists {
+			return delta, nil
+		}
+
+		n, ok := old.(int64)
+		if !ok {
+			return nil, ErrValueType
+		}
+
+		return n + delta, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return value.(int64), nil
+}
+
+// update replaces the value of the element by the result of the function under the lock
+func (c *cache) update(key string, fn UpdateFunc) (interface{}, error) {
+	now := time.Now()
+	element, ok := c.validate(key)
+	if ok && c.expired(element, now) {
+		c.evict(element, EvictedByTTL)
+		ok = false
+	}
+
+	var old interface{}
+	if ok {
+		if c.pinned(element, now) {
+			return nil, ErrLeased
+		}
+
+		var err error
+		if old, err = c.decode(element.value); err != nil {
+			return nil, err
+		}
+	}
+
+	newValue, err := fn(old, ok)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := c.store(newValue)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		err = c.changeValue(key, stored)
+	} else {
+		err = c.add(key, stored)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.countCompressed(stored)
+
+	return newValue, nil
+}
@@ -0,0 +1,83 @@
+package golru
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	c, err := NewCache(2, WithCodec(GobCodec()))
+	require.NoError(t, err)
+
+	value, err := c.Update("test", func(old interface{}, exists bool) (interface{}, error) {
+		require.False(t, exists)
+		return "first", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "first", value)
+
+	value, err = c.Update("test", func(old interface{}, exists bool) (interface{}, error) {
+		require.True(t, exists)
+		return old.(string) + "+second", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "first+second", value)
+
+	failure := errors.New("failure")
+	_, err = c.Update("test", func(old interface{}, exists bool) (interface{}, error) {
+		return nil, failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	value, _ = c.Get("test")
+	require.Equal(t, "first+second", value)
+}
+
+func TestAppendToSlice(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	n, err := c.AppendToSlice("list", 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	before, _ := c.Get("list")
+	n, err = c.AppendToSlice("list", 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []interface{}{1, 2}, before)
+
+	value, _ := c.Get("list")
+	require.Equal(t, []interface{}{1, 2, 3}, value)
+
+	c.Add("other", "string")
+	_, err = c.AppendToSlice("other", 1)
+	require.ErrorIs(t, err, ErrValueType)
+}
+
+func TestIncrement(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Increment("counter", 2)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	n, err := c.Increment("counter", -1)
+	require.NoError(t, err)
+	require.Equal(t, int64(199), n)
+
+	c.Add("other", 1)
+	_, err = c.Increment("other", 1)
+	require.ErrorIs(t, err, ErrValueType)
+}
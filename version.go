@@ -0,0 +1,118 @@
+package golru
+
+import "errors"
+
+var ErrNotModified = errors.New("element has the known version")
+
+// AddVersion works like AddE and returns the version of the added element. The versions are given by the counter of
+// the cache growing with every added or changed value, so the later write always has the greater version
+func (c *cache) AddVersion(key string, value interface{}) (uint64, error) {
+	result, err := c.do(OpAdd, key, value)
+
+	return versionOf(result), err
+}
+
+// ChangeValueVersion works like ChangeValue and returns the new version of the element, or ErrKeyNotFound if there
+// is no such key and ErrLeased if the element is leased
+func (c *cache) ChangeValueVersion(key string, newValue interface{}) (uint64, error) {
+	result, err := c.do(OpChangeValue, key, newValue)
+
+	return versionOf(result), err
+}
+
+// GetAtLeast works like Get, but the element with the version less than the given one is a miss, so the writer
+// never reads back the value older than its own write. The stale element stays in the cache. Like GetEntry, it
+// doesn't go through the hooks and the middleware
+func (c *cache) GetAtLeast(key string, version uint64) (interface{}, bool) {
+	value, _, err := c.getVersion(key, version)
+
+	return value, err == nil
+}
+
+// GetIfChanged returns the value and the version of the element unless its version is the known one, then
+// ErrNotModified is returned without the value, so the caller can skip sending the payload it has already sent. The
+// unchanged element still counts as a hit. Returns ErrKeyNotFound or ErrExpired like GetE. Like GetEntry, it doesn't
+// go through the hooks and the middleware
+func (c *cache) GetIfChanged(key string, knownVersion uint64) (interface{}, uint64, error) {
+	key, err := c.prepare(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	c.lock()
+	element, ok := c.validate(key)
+	value, err := c.access(element, ok)
+	var version uint64
+	if err == nil {
+		version = element.version
+	}
+	c.unlock()
+
+	switch {
+	case err != nil:
+		return nil, 0, err
+	case version == knownVersion:
+		return nil, version, ErrNotModified
+	}
+
+	if c.transforms() {
+		if value, err = c.load(value); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return value, version, nil
+}
+
+// getVersion returns the value and the version of the element if its version is at least the given one
+func (c *cache) getVersion(key string, version uint64) (interface{}, uint64, error) {
+	key, err := c.prepare(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value, current, err := c.accessVersion(key, version)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if c.transforms() {
+		if value, err = c.load(value); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return value, current, nil
+}
+
+// accessVersion returns the stored value and the version of the element under the lock, the older element is a miss
+func (c *cache) accessVersion(key string, version uint64) (interface{}, uint64, error) {
+	c.lock()
+	defer c.unlock()
+
+	element, ok := c.validate(key)
+	if ok && element.version < version {
+		c.countAccess(false)
+		return nil, 0, ErrKeyNotFound
+	}
+
+	value, err := c.access(element, ok)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, element.version, nil
+}
+
+// stamp gives the next version to the added or changed element
+func (c *cache) stamp(element *item) {
+	c.version++
+	element.version = c.version
+}
+
+// versionOf returns the version from the result of Add or ChangeValue
+func versionOf(result interface{}) uint64 {
+	version, _ := result.(uint64)
+
+	return version
+}
@@ -0,0 +1,73 @@
+package golru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	c, err := NewCache(3)
+	require.NoError(t, err)
+
+	first, err := c.AddVersion("first", 1)
+	require.NoError(t, err)
+	second, err := c.AddVersion("second", 2)
+	require.NoError(t, err)
+	require.Greater(t, second, first)
+
+	_, err = c.AddVersion("first", 10)
+	require.ErrorIs(t, err, ErrKeyExists)
+
+	changed, err := c.ChangeValueVersion("first", 10)
+	require.NoError(t, err)
+	require.Greater(t, changed, second)
+	_, err = c.ChangeValueVersion("missing", 1)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	entry, _ := c.GetEntry("first")
+	require.Equal(t, changed, entry.Version)
+
+	value, ok := c.GetAtLeast("first", changed)
+	require.True(t, ok)
+	require.Equal(t, 10, value)
+	_, ok = c.GetAtLeast("second", changed)
+	require.False(t, ok)
+	_, ok = c.GetAtLeast("missing", 0)
+	require.False(t, ok)
+	require.Equal(t, uint64(2), c.Stats().Misses)
+
+	clone := c.Clone(nil)
+	entry, _ = clone.GetEntry("first")
+	require.Equal(t, changed, entry.Version)
+	next, _ := clone.AddVersion("third", 3)
+	require.Greater(t, next, changed)
+}
+
+func TestGetIfChanged(t *testing.T) {
+	c, err := NewCache(2, WithCodec(GobCodec()))
+	require.NoError(t, err)
+
+	_, _, err = c.GetIfChanged("missing", 0)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	version, err := c.AddVersion("test", "payload")
+	require.NoError(t, err)
+
+	value, current, err := c.GetIfChanged("test", 0)
+	require.NoError(t, err)
+	require.Equal(t, "payload", value)
+	require.Equal(t, version, current)
+
+	value, current, err = c.GetIfChanged("test", version)
+	require.ErrorIs(t, err, ErrNotModified)
+	require.Nil(t, value)
+	require.Equal(t, version, current)
+
+	c.ChangeValue("test", "changed")
+	value, current, err = c.GetIfChanged("test", version)
+	require.NoError(t, err)
+	require.Equal(t, "changed", value)
+	require.Greater(t, current, version)
+	require.Equal(t, uint64(3), c.Stats().Hits)
+}
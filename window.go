@@ -0,0 +1,85 @@
+package golru
+
+import "time"
+
+// hitWindow counts hits and misses in a ring of time buckets, so the hit ratio can be calculated over the last
+// minutes instead of the whole lifetime of the cache
+type hitWindow struct {
+	width   time.Duration
+	buckets []hitBucket
+}
+
+// hitBucket keeps the counters of a single time slot. Epoch is the number of the slot since the Unix epoch, it
+// tells whether the bucket belongs to the current turn of the ring
+type hitBucket struct {
+	epoch  int64
+	hits   uint64
+	misses uint64
+}
+
+// WithHitWindow enables tracking of the hit ratio over the sliding window of the given number of buckets of the given
+// width. For example, 90 buckets of 10 seconds allow you to get the hit ratio of the last 1, 5 or 15 minutes by
+// WindowHitRatio. The window is disabled if either argument is zero
+func WithHitWindow(width time.Duration, buckets int) CacheOption {
+	return func(cache *cache) {
+		if width <= 0 || buckets <= 0 {
+			cache.window = nil
+			return
+		}
+		cache.window = &hitWindow{width: width, buckets: make([]hitBucket, buckets)}
+	}
+}
+
+// WindowHitRatio returns the share of successful Get calls over the last period d, rounded up to whole buckets and
+// limited by the size of the window. Returns zero if the window isn't enabled or there were no calls
+func (c *cache) WindowHitRatio(d time.Duration) float64 {
+	c.lock()
+	defer c.unlock()
+
+	if c.window == nil {
+		return 0
+	}
+
+	return c.window.ratio(time.Now(), d)
+}
+
+// record counts a hit or a miss in the bucket of the moment now
+func (w *hitWindow) record(now time.Time, hit bool) {
+	epoch := now.UnixNano() / int64(w.width)
+	bucket := &w.buckets[epoch%int64(len(w.buckets))]
+	if bucket.epoch != epoch {
+		*bucket = hitBucket{epoch: epoch}
+	}
+
+	if hit {
+		bucket.hits++
+	} else {
+		bucket.misses++
+	}
+}
+
+// ratio returns the hit ratio of the buckets covering the period d before the moment now
+func (w *hitWindow) ratio(now time.Time, d time.Duration) float64 {
+	hits, misses := w.count(now, d)
+
+	return Stats{Hits: hits, Misses: misses}.HitRatio()
+}
+
+// count returns the number of hits and misses in the buckets covering the period d before the moment now
+func (w *hitWindow) count(now time.Time, d time.Duration) (hits, misses uint64) {
+	last := now.UnixNano() / int64(w.width)
+	count := int64((d + w.width - 1) / w.width)
+	if count > int64(len(w.buckets)) {
+		count = int64(len(w.buckets))
+	}
+
+	for epoch := last - count + 1; epoch <= last; epoch++ {
+		bucket := w.buckets[epoch%int64(len(w.buckets))]
+		if bucket.epoch == epoch {
+			hits += bucket.hits
+			misses += bucket.misses
+		}
+	}
+
+	return hits, misses
+}
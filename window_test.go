@@ -0,0 +1,44 @@
+package golru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowHitRatio(t *testing.T) {
+	c, err := NewCache(2, WithHitWindow(50*time.Millisecond, 4))
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("missing")
+	c.Get("missing")
+	c.Get("missing")
+	require.Equal(t, 0.0, c.WindowHitRatio(time.Second))
+
+	time.Sleep(60 * time.Millisecond)
+	c.Get("test")
+	require.Equal(t, 1.0, c.WindowHitRatio(time.Millisecond))
+	require.Equal(t, 0.25, c.WindowHitRatio(time.Second))
+
+	time.Sleep(250 * time.Millisecond)
+	require.Equal(t, 0.0, c.WindowHitRatio(time.Second))
+	require.Equal(t, 0.25, c.Stats().HitRatio())
+}
+
+func TestWindowDisabled(t *testing.T) {
+	c, err := NewCache(2)
+	require.NoError(t, err)
+
+	c.Add("test", 42)
+	c.Get("test")
+	require.Equal(t, 0.0, c.WindowHitRatio(time.Minute))
+
+	s, err := NewShardedCache(2, 10, WithHitWindow(time.Second, 60))
+	require.NoError(t, err)
+	s.Add("test", 42)
+	s.Get("test")
+	s.Get("missing")
+	require.Equal(t, 0.5, s.WindowHitRatio(time.Minute))
+}